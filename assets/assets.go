@@ -0,0 +1,9 @@
+// Package assets holds the files embedded in the tomatick binary
+package assets
+
+import _ "embed"
+
+// SoftBeep is the default sound at the end of timers
+//
+//go:embed softbeep.mp3
+var SoftBeep []byte
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+	"github.com/1x-eng/tomatick/pkg/context"
+	"github.com/1x-eng/tomatick/pkg/llm"
+	"github.com/1x-eng/tomatick/pkg/ui"
+	"github.com/1x-eng/tomatick/pkg/webhook"
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+)
+
+func newContextCmd(cfg *config.Config) *cobra.Command {
+	contextCmd := &cobra.Command{
+		Use:   "context",
+		Short: "Manage saved session contexts",
+	}
+
+	contextCmd.AddCommand(&cobra.Command{
+		Use:   "from-git [path]",
+		Short: "Create a context from the local state of a git repository",
+		Long: "Reads the current branch, recent commits, uncommitted changes and TODO/FIXME " +
+			"comments in changed files, then offers refinement and saving like any other context. " +
+			"Only the local repository is read.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) == 1 {
+				path = args[0]
+			}
+
+			llmClient := llm.NewPerplexityAI(cfg)
+			dispatcher := webhook.NewSessionDispatcher(cfg)
+			defer dispatcher.Shutdown(10 * time.Second)
+
+			manager := context.NewContextManager(cfg.ContextDir, aurora.NewAurora(true), ui.NewTheme(), llmClient, dispatcher, cfg.UseEditor)
+			if _, err := manager.ImportFromGit(path); err != nil {
+				return fmt.Errorf("failed to import context: %w", err)
+			}
+			return nil
+		},
+	})
+
+	return contextCmd
+}
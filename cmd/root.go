@@ -10,7 +10,7 @@ import (
 )
 
 func NewRootCmd(cfg *config.Config) *cobra.Command {
-	return &cobra.Command{
+	rootCmd := &cobra.Command{
 		Use:   "tomatick",
 		Short: "A CLI Pomodoro timer with mem.ai integration",
 		Run: func(cmd *cobra.Command, args []string) {
@@ -18,6 +18,12 @@ func NewRootCmd(cfg *config.Config) *cobra.Command {
 			pomo.StartCycle()
 		},
 	}
+
+	rootCmd.AddCommand(newContextCmd(cfg))
+	rootCmd.AddCommand(newWebhookCmd(cfg))
+	rootCmd.AddCommand(newStatsCmd(cfg))
+
+	return rootCmd
 }
 
 func Execute(cfg *config.Config) {
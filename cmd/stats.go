@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/1x-eng/tomatick/config"
+	"github.com/1x-eng/tomatick/pkg/history"
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+)
+
+// barWidth is the width of a bar for a rating of 5
+const barWidth = 10
+
+func newStatsCmd(cfg *config.Config) *cobra.Command {
+	var since, moment string
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Chart your check-ins over the time of day",
+		Long: "Averages the energy, focus and mood you rated at the start and end of cycles " +
+			"by the hour of day they were made in, to show when you do your best work.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, err := parseReplayTime(since)
+			if err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+			if moment != "" && moment != history.CheckInStart && moment != history.CheckInEnd {
+				return fmt.Errorf("invalid --moment %q: must be start or end", moment)
+			}
+
+			store := history.NewStore(filepath.Join(cfg.ContextDir, "history"))
+			checkIns, err := store.CheckIns()
+			if err != nil {
+				return fmt.Errorf("failed to read check-ins: %w", err)
+			}
+
+			var selected []history.CheckIn
+			for _, c := range checkIns {
+				if c.Time.Before(from) || (moment != "" && c.Moment != moment) {
+					continue
+				}
+				selected = append(selected, c)
+			}
+
+			au := aurora.NewAurora(true)
+			if len(selected) == 0 {
+				fmt.Println(au.Yellow("No check-ins recorded in this period."))
+				return nil
+			}
+
+			fmt.Println(au.Bold(fmt.Sprintf("Check-ins by time of day (%d in total)", len(selected))))
+			fmt.Printf("%-6s %-14s %-14s %-14s %s\n", "", "energy", "focus", "mood", "check-ins")
+			for _, avg := range history.ByHour(selected) {
+				fmt.Printf("%02d:00  %s %s %s %d\n", avg.Hour,
+					au.Green(ratingBar(avg.Energy)), au.Cyan(ratingBar(avg.Focus)), au.Magenta(ratingBar(avg.Mood)), avg.Count)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "30d", "only chart check-ins at or after this time (duration, RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().StringVar(&moment, "moment", "", "only chart check-ins made at the start or end of cycles")
+
+	return cmd
+}
+
+// ratingBar draws an average rating from 1 to 5 as a bar followed by its value
+func ratingBar(rating float64) string {
+	filled := int(rating/5*barWidth + 0.5)
+	return fmt.Sprintf("%s%s %.1f", strings.Repeat("█", filled), strings.Repeat("░", barWidth-filled), rating)
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+	"github.com/1x-eng/tomatick/pkg/webhook"
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+)
+
+func newWebhookCmd(cfg *config.Config) *cobra.Command {
+	webhookCmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Inspect, test and replay webhook deliveries",
+	}
+
+	webhookCmd.AddCommand(newWebhookReplayCmd(cfg), newWebhookTestCmd(cfg), newWebhookLogCmd(cfg))
+	webhookCmd.AddCommand(&cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the standard webhook payload",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			os.Stdout.Write(webhook.Schema())
+		},
+	})
+
+	return webhookCmd
+}
+
+func newWebhookReplayCmd(cfg *config.Config) *cobra.Command {
+	var events []string
+	var since, until, target string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Resend deliveries from the dead-letter queue",
+		Long: "Resends webhook deliveries that exhausted their retries. Each delivery is attempted once; " +
+			"delivered entries are removed from the queue and failed ones are kept for the next replay. " +
+			"--since and --until accept RFC3339 times, YYYY-MM-DD dates or durations such as 24h or 7d (relative to now).",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter := webhook.DeadLetterFilter{Target: target}
+			for _, event := range events {
+				filter.Events = append(filter.Events, webhook.EventType(event))
+			}
+
+			var err error
+			if filter.Since, err = parseReplayTime(since); err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+			if filter.Until, err = parseReplayTime(until); err != nil {
+				return fmt.Errorf("invalid --until: %w", err)
+			}
+
+			dispatcher := webhook.NewHTTPDispatcher(cfg.Webhooks, filepath.Join(cfg.ContextDir, "logs"), cfg.WebhookLog)
+			defer dispatcher.Close()
+
+			if dryRun {
+				letters, err := dispatcher.DeadLetters().List()
+				if err != nil {
+					return err
+				}
+				matched := 0
+				for _, letter := range letters {
+					if !filter.Matches(letter) {
+						continue
+					}
+					matched++
+					fmt.Printf("%s  %-18s %s  (%d attempts, %s)\n",
+						letter.EventTime.Local().Format("2006-01-02 15:04:05"), letter.Event, letter.Target, letter.Attempts, describeFailure(letter))
+				}
+				fmt.Printf("%d of %d dead letters match\n", matched, len(letters))
+				return nil
+			}
+
+			result, err := dispatcher.Replay(filter)
+			if err != nil {
+				return fmt.Errorf("failed to replay dead letters: %w", err)
+			}
+			fmt.Printf("Delivered %d, failed %d, %d left in %s\n",
+				result.Delivered, result.Failed, result.Remaining, dispatcher.DeadLetters().Path())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&events, "event", nil, "only replay these event types (repeatable)")
+	cmd.Flags().StringVar(&since, "since", "", "only replay events at or after this time")
+	cmd.Flags().StringVar(&until, "until", "", "only replay events at or before this time")
+	cmd.Flags().StringVar(&target, "target", "", "only replay deliveries to this URL")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "list matching dead letters without sending them")
+
+	return cmd
+}
+
+func newWebhookTestCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "test <url>",
+		Short: "Send a sample work_start event to a URL",
+		Long: "Sends a sample event synchronously and prints the response status. " +
+			"If the URL is a configured webhook, its template, headers and secret are used.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dispatcher := webhook.NewHTTPDispatcher(cfg.Webhooks, filepath.Join(cfg.ContextDir, "logs"), cfg.WebhookLog)
+			defer dispatcher.Close()
+
+			code, duration, err := dispatcher.Test(args[0])
+			if err != nil {
+				return fmt.Errorf("test delivery failed: %w", err)
+			}
+			fmt.Printf("%s responded %d in %s\n", args[0], code, duration.Round(time.Millisecond))
+			if code < 200 || code >= 300 {
+				return fmt.Errorf("endpoint returned non-2xx status %d", code)
+			}
+			return nil
+		},
+	}
+}
+
+func newWebhookLogCmd(cfg *config.Config) *cobra.Command {
+	var since, target string
+	var limit int
+	var failuresOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show recent webhook deliveries grouped by target",
+		Long: "Reads the webhook delivery log, including rotated files, and shows per-target " +
+			"attempt counts, latency and the most recent deliveries and failures.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, err := parseReplayTime(since)
+			if err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+
+			entries, err := webhook.ReadLog(filepath.Join(cfg.ContextDir, "logs"), from)
+			if err != nil {
+				return fmt.Errorf("failed to read webhook log: %w", err)
+			}
+
+			au := aurora.NewAurora(true)
+			summaries := webhook.SummarizeLog(entries)
+			shown := 0
+			for _, s := range summaries {
+				if target != "" && !strings.EqualFold(s.Target, target) {
+					continue
+				}
+				shown++
+				printTargetSummary(au, s, limit, failuresOnly)
+			}
+
+			if shown == 0 {
+				fmt.Println(au.Yellow("No webhook deliveries logged in this period."))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "24h", "show deliveries at or after this time (duration, RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().StringVar(&target, "target", "", "only show this URL")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "recent entries to show per target")
+	cmd.Flags().BoolVar(&failuresOnly, "failures", false, "only list failed attempts")
+
+	return cmd
+}
+
+func printTargetSummary(au aurora.Aurora, s webhook.TargetSummary, limit int, failuresOnly bool) {
+	fmt.Println(au.Bold(au.BrightBlue(s.Target)))
+	fmt.Printf("  %d attempts · %d delivered · %d retried · %d failed · latency p50 %s / p95 %s\n",
+		s.Attempts,
+		au.Green(s.Delivered),
+		au.Yellow(s.Retried),
+		au.Red(s.Failed),
+		s.P50.Round(time.Millisecond),
+		s.P95.Round(time.Millisecond))
+
+	var rows []webhook.LogEntry
+	for i := len(s.Entries) - 1; i >= 0 && len(rows) < limit; i-- {
+		entry := s.Entries[i]
+		if failuresOnly && entry.Delivered() {
+			continue
+		}
+		rows = append(rows, entry)
+	}
+
+	for _, entry := range rows {
+		status := au.Green(entry.Status)
+		if !entry.Delivered() {
+			status = au.Red(entry.Status)
+			if entry.Status == "Retrying" {
+				status = au.Yellow(entry.Status)
+			}
+		}
+
+		code := "   "
+		if entry.Code != 0 {
+			code = fmt.Sprintf("%d", entry.Code)
+		}
+
+		line := fmt.Sprintf("  %s  %-18s #%d  %-14s %s  %8s",
+			entry.Time().Local().Format("2006-01-02 15:04:05"),
+			entry.Event,
+			entry.Attempt,
+			status,
+			code,
+			entry.Latency().Round(time.Millisecond))
+		if entry.Error != "" {
+			line += "  " + au.Red(entry.Error).String()
+		}
+		fmt.Println(line)
+	}
+	fmt.Println()
+}
+
+func parseReplayTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && strings.HasSuffix(value, "d") {
+		return time.Now().AddDate(0, 0, -days), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a duration (24h, 7d), RFC3339 time or YYYY-MM-DD date", value)
+}
+
+func describeFailure(letter webhook.DeadLetter) string {
+	if letter.LastError != "" {
+		return letter.LastError
+	}
+	return fmt.Sprintf("status %d", letter.LastCode)
+}
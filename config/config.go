@@ -13,12 +13,23 @@ type Config struct {
 	ShortBreakDuration      time.Duration
 	LongBreakDuration       time.Duration
 	CyclesBeforeLongBreak   int
+	AdaptiveBreaks          bool // lengthen breaks or bring the long break forward on signs of fatigue
+	CheckIns                bool // ask for energy, focus and mood ratings at the start and end of each cycle
 	MEMAIAPIToken           string
 	ContextDir              string
 	PerplexityAPIToken      string
 	UserName                string
-	WorkApps                []string
-	Webhooks                []string
+	Monitor                 Monitor
+	FocusTracking           bool // also sample activity while working
+	Webhooks                []Webhook
+	WebhookLog              LogRotation
+	MQTT                    MQTT
+	UseEditor               bool
+	Notifier                string // auto, dbus, osc9, osc777, bell or none
+	Sound                   Sound
+	Routines                Routines
+	ControlAPIAddr          string // loopback host:port or unix:/path; empty disables the control API
+	ControlAPIToken         string
 	Features                Features
 }
 
@@ -51,6 +62,16 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("invalid CYCLES_BEFORE_LONGBREAK: %w", err)
 	}
 
+	adaptiveBreaks, err := parseBoolEnv("ADAPTIVE_BREAKS", true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ADAPTIVE_BREAKS: %w", err)
+	}
+
+	checkIns, err := parseBoolEnv("CHECK_INS", true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CHECK_INS: %w", err)
+	}
+
 	contextDir := getEnvVar("TOMATICK_CONTEXT_DIR")
 	if contextDir == "" {
 		contextDir = getDefaultContextDir()
@@ -60,15 +81,61 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to create context directory: %w", err)
 	}
 
-	// Get work apps from environment
-	workApps := getWorkApps()
+	// Get the monitor thresholds and work, break and distraction apps
+	monitor, err := getMonitor()
+	if err != nil {
+		return nil, err
+	}
+
+	focusTracking, err := parseBoolEnv("FOCUS_TRACKING", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FOCUS_TRACKING: %w", err)
+	}
+
+	// Get webhooks from the webhooks file and environment
+	webhooks, err := getWebhooks(contextDir)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookLog, err := getWebhookLogRotation()
+	if err != nil {
+		return nil, err
+	}
 
-	// Get webhooks from environment
-	webhooks := getWebhooks()
+	mqtt, err := getMQTT()
+	if err != nil {
+		return nil, err
+	}
+
+	useEditor, err := parseBoolEnv("USE_EDITOR", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid USE_EDITOR: %w", err)
+	}
+
+	notifier := strings.ToLower(getEnvVar("NOTIFIER"))
+	switch notifier {
+	case "":
+		notifier = "auto"
+	case "auto", "dbus", "osc9", "osc777", "bell", "none":
+	default:
+		return nil, fmt.Errorf("invalid NOTIFIER %q: must be auto, dbus, osc9, osc777, bell or none", notifier)
+	}
+
+	sound, err := getSound()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the break routines from the routines file and environment
+	routines, err := getRoutines(contextDir)
+	if err != nil {
+		return nil, err
+	}
 
 	// Determine available features based on OS
 	features := Features{
-		BreakMonitoring: runtime.GOOS == "darwin", // Only enable on macOS
+		BreakMonitoring: runtime.GOOS == "darwin" || runtime.GOOS == "linux",
 	}
 
 	return &Config{
@@ -76,62 +143,43 @@ func LoadConfig() (*Config, error) {
 		ShortBreakDuration:      shortBreak,
 		LongBreakDuration:       longBreak,
 		CyclesBeforeLongBreak:   cycles,
+		AdaptiveBreaks:          adaptiveBreaks,
+		CheckIns:                checkIns,
 		MEMAIAPIToken:           getEnvVar("MEM_AI_API_TOKEN"),
 		ContextDir:              contextDir,
 		PerplexityAPIToken:      getEnvVar("PERPLEXITY_API_TOKEN"),
 		UserName:                getEnvVar("USER_NAME"),
-		WorkApps:                workApps,
+		Monitor:                 monitor,
+		FocusTracking:           focusTracking,
 		Webhooks:                webhooks,
+		WebhookLog:              webhookLog,
+		MQTT:                    mqtt,
+		UseEditor:               useEditor,
+		Notifier:                notifier,
+		Sound:                   sound,
+		Routines:                routines,
+		ControlAPIAddr:          getEnvVar("CONTROL_API_ADDR"),
+		ControlAPIToken:         getEnvVar("CONTROL_API_TOKEN"),
 		Features:                features,
 	}, nil
 }
 
-// getWorkApps gets the list of work apps from environment variable
-func getWorkApps() []string {
-	defaultApps := []string{
-		"Code",          // VS Code
-		"Cursor",        // Cursor Editor
-		"iTerm2",        // Terminal
-		"Insomnia",      // API Testing
-		"pgAdmin 4",     // PostgreSQL Admin
-		"pgAdmin",       // PostgreSQL Admin
-		"Chrome",        // Web Browser
-		"Google Chrome", // Web Browser
-		"Terminal",      // Built-in Terminal
-	}
-
-	workAppsEnv := getEnvVar("WORK_APPS")
-	if workAppsEnv == "" {
-		return defaultApps
-	}
-
-	// Split by comma and trim spaces
-	customApps := strings.Split(workAppsEnv, ",")
-	for i, app := range customApps {
-		customApps[i] = strings.TrimSpace(app)
-	}
-
-	return customApps
-}
-
-// getWebhooks gets the list of webhook URLs from environment variable
-func getWebhooks() []string {
-	webhooksEnv := getEnvVar("WEBHOOK_URLS")
-	if webhooksEnv == "" {
+// splitCommaList splits a comma-separated value, trimming spaces.
+// Empty entries are dropped unless keepEmpty is set.
+func splitCommaList(value string, keepEmpty bool) []string {
+	if value == "" {
 		return []string{}
 	}
 
-	// Split by comma and trim spaces
-	urls := strings.Split(webhooksEnv, ",")
-	var validUrls []string
-	for _, url := range urls {
-		trimmed := strings.TrimSpace(url)
-		if trimmed != "" {
-			validUrls = append(validUrls, trimmed)
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		trimmed := strings.TrimSpace(item)
+		if trimmed != "" || keepEmpty {
+			items = append(items, trimmed)
 		}
 	}
 
-	return validUrls
+	return items
 }
 
 func parseDurationEnv(key, defaultValue string) (time.Duration, error) {
@@ -150,6 +198,14 @@ func parseIntEnv(key string, defaultValue int) (int, error) {
 	return strconv.Atoi(value)
 }
 
+func parseBoolEnv(key string, defaultValue bool) (bool, error) {
+	value := getEnvVar(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.ParseBool(value)
+}
+
 // GetMemAIToken returns the Mem AI API token
 func (c *Config) GetMemAIToken() string {
 	return c.MEMAIAPIToken
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultIdleThreshold is the idle time after which we consider the user idle
+	// during a break. 10 seconds avoids false positives from casual mouse movement.
+	defaultIdleThreshold = 10 * time.Second
+
+	// defaultActivityThreshold is how long continuous activity needs to be present to count as a violation
+	defaultActivityThreshold = 30 * time.Second
+
+	// defaultAppNameCacheTTL limits how often the foreground app is looked up
+	defaultAppNameCacheTTL = 500 * time.Millisecond
+
+	// defaultNotifyInterval is the minimum time between break notifications
+	defaultNotifyInterval = 60 * time.Second
+
+	// defaultFocusIdleThreshold is the idle time after which focus time counts
+	// as time away. It is longer than the break threshold, since reading or
+	// thinking without touching the keyboard is still work.
+	defaultFocusIdleThreshold = time.Minute
+)
+
+// Monitor configures break monitoring and focus tracking
+type Monitor struct {
+	IdleThreshold      time.Duration // no input for this long during a break counts as idle
+	ActivityThreshold  time.Duration // continuous activity for this long during a break is a violation
+	AppNameCacheTTL    time.Duration
+	NotifyInterval     time.Duration // minimum time between break notifications and violation reports
+	FocusIdleThreshold time.Duration // no input for this long while working counts as time away
+	WorkApps           []AppRule
+	BreakApps          []AppRule // activity in these apps is fine during breaks
+	DistractionApps    []AppRule // empty counts every identified non-work app as a distraction
+}
+
+// DefaultMonitor returns the monitor settings used when nothing is configured
+func DefaultMonitor() Monitor {
+	return Monitor{
+		IdleThreshold:      defaultIdleThreshold,
+		ActivityThreshold:  defaultActivityThreshold,
+		AppNameCacheTTL:    defaultAppNameCacheTTL,
+		NotifyInterval:     defaultNotifyInterval,
+		FocusIdleThreshold: defaultFocusIdleThreshold,
+	}
+}
+
+// AppRule matches the focused app by its name, or by its window title for
+// rules written as title:<pattern>. A pattern is a regular expression when
+// written as /regexp/, a glob when it contains *, ? or [, and otherwise an
+// exact name. All matching ignores case.
+type AppRule struct {
+	Pattern string // as configured
+	Title   bool   // match the window title instead of the app name
+
+	exact string
+	re    *regexp.Regexp // a regexp rule, or a glob translated into one
+}
+
+// ParseAppRule parses a work, break or distraction app rule
+func ParseAppRule(rule string) (AppRule, error) {
+	r := AppRule{Pattern: rule}
+
+	pattern := rule
+	if rest, ok := strings.CutPrefix(pattern, "title:"); ok {
+		r.Title = true
+		pattern = strings.TrimSpace(rest)
+	}
+	if pattern == "" {
+		return AppRule{}, fmt.Errorf("empty app rule %q", rule)
+	}
+
+	switch {
+	case len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/"):
+		re, err := regexp.Compile("(?i)" + pattern[1:len(pattern)-1])
+		if err != nil {
+			return AppRule{}, fmt.Errorf("invalid regular expression in app rule %q: %w", rule, err)
+		}
+		r.re = re
+	case strings.ContainsAny(pattern, "*?["):
+		re, err := globRegexp(pattern)
+		if err != nil {
+			return AppRule{}, fmt.Errorf("invalid glob in app rule %q: %w", rule, err)
+		}
+		r.re = re
+	default:
+		r.exact = pattern
+	}
+
+	return r, nil
+}
+
+// ParseAppRules parses a list of app rules
+func ParseAppRules(rules []string) ([]AppRule, error) {
+	parsed := make([]AppRule, 0, len(rules))
+	for _, rule := range rules {
+		r, err := ParseAppRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, r)
+	}
+	return parsed, nil
+}
+
+// Match reports whether the rule matches the focused app or its window title
+func (r AppRule) Match(app, title string) bool {
+	value := app
+	if r.Title {
+		value = title
+	}
+	if value == "" {
+		return false
+	}
+
+	switch {
+	case r.re != nil:
+		return r.re.MatchString(value)
+	default:
+		return strings.EqualFold(r.exact, value)
+	}
+}
+
+// globRegexp translates a glob into a case-insensitive regexp matching the
+// whole value. Unlike path.Match, * and ? also match /, which window titles
+// are full of.
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("(?i)^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		case '\\':
+			if i+1 == len(glob) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			i++
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in %q", glob)
+			}
+			class := glob[i+1 : i+1+end]
+			if rest, ok := strings.CutPrefix(class, "!"); ok {
+				class = "^" + rest
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+func (r AppRule) String() string {
+	return r.Pattern
+}
+
+// getMonitor reads the monitor thresholds and app rules from the environment
+func getMonitor() (Monitor, error) {
+	m := DefaultMonitor()
+
+	thresholds := []struct {
+		key   string
+		value *time.Duration
+	}{
+		{"MONITOR_IDLE_THRESHOLD", &m.IdleThreshold},
+		{"MONITOR_ACTIVITY_THRESHOLD", &m.ActivityThreshold},
+		{"MONITOR_APP_CACHE_TTL", &m.AppNameCacheTTL},
+		{"MONITOR_NOTIFY_INTERVAL", &m.NotifyInterval},
+		{"FOCUS_IDLE_THRESHOLD", &m.FocusIdleThreshold},
+	}
+	for _, t := range thresholds {
+		d, err := parseDurationEnv(t.key, t.value.String())
+		if err != nil || d < 0 {
+			return Monitor{}, fmt.Errorf("invalid %s: must be a duration like 30s", t.key)
+		}
+		*t.value = d
+	}
+
+	if m.IdleThreshold <= 0 || m.ActivityThreshold <= 0 || m.FocusIdleThreshold <= 0 {
+		return Monitor{}, fmt.Errorf("invalid monitor thresholds: MONITOR_IDLE_THRESHOLD, MONITOR_ACTIVITY_THRESHOLD and FOCUS_IDLE_THRESHOLD must be positive")
+	}
+
+	rules := []struct {
+		key      string
+		value    *[]AppRule
+		defaults []string
+	}{
+		{"WORK_APPS", &m.WorkApps, defaultWorkApps},
+		{"BREAK_APPS", &m.BreakApps, defaultBreakApps},
+		{"DISTRACTION_APPS", &m.DistractionApps, nil},
+	}
+	for _, r := range rules {
+		patterns := splitCommaList(getEnvVar(r.key), false)
+		if len(patterns) == 0 {
+			patterns = r.defaults
+		}
+		parsed, err := ParseAppRules(patterns)
+		if err != nil {
+			return Monitor{}, fmt.Errorf("invalid %s: %w", r.key, err)
+		}
+		*r.value = parsed
+	}
+
+	return m, nil
+}
+
+// defaultWorkApps are the apps that count as work unless WORK_APPS is set
+var defaultWorkApps = []string{
+	"Code",          // VS Code
+	"Cursor",        // Cursor Editor
+	"iTerm2",        // Terminal
+	"Insomnia",      // API Testing
+	"pgAdmin 4",     // PostgreSQL Admin
+	"pgAdmin",       // PostgreSQL Admin
+	"Chrome",        // Web Browser
+	"Google Chrome", // Web Browser
+	"Terminal",      // Built-in Terminal
+}
+
+// defaultBreakApps are the break-friendly apps unless BREAK_APPS is set
+var defaultBreakApps = []string{
+	"Spotify",   // Music
+	"Music",     // Apple Music
+	"Podcasts",  // Apple Podcasts
+	"Calm",      // Meditation
+	"Headspace", // Meditation
+}
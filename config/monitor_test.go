@@ -0,0 +1,48 @@
+package config
+
+import "testing"
+
+func TestAppRuleMatch(t *testing.T) {
+	tests := []struct {
+		rule  string
+		app   string
+		title string
+		want  bool
+	}{
+		{rule: "Code", app: "Code", want: true},
+		{rule: "Code", app: "code", want: true},
+		{rule: "Code", app: "Xcode", want: false},
+		{rule: "Code", app: "Code Helper", want: false},
+		{rule: "*terminal*", app: "gnome-terminal-server", want: true},
+		{rule: "Google-chrome?", app: "google-chrome", want: false},
+		{rule: "[Ff]irefox", app: "firefox", want: true},
+		{rule: "/^pgadmin( 4)?$/", app: "pgAdmin 4", want: true},
+		{rule: "/^pgadmin( 4)?$/", app: "pgAdmin 3", want: false},
+		{rule: "title:*- Jira", app: "firefox", title: "PROJ-1 Login - Jira", want: true},
+		{rule: "title:*- Jira", app: "Jira", want: false},
+		{rule: "title:*github.com*", app: "firefox", title: "Pull requests · github.com/1x-eng/tomatick - Firefox", want: true},
+		{rule: "title:*/src/*", app: "Code", title: "main.go - ~/src/tomatick - Visual Studio Code", want: true},
+		{rule: "title:*/src/*", app: "Code", title: "main.go - ~/docs - Visual Studio Code", want: false},
+		{rule: "title:[!x]*.go*", app: "Code", title: "main.go - Code", want: true},
+		{rule: "title:/youtube/", app: "firefox", title: "Lo-fi beats - YouTube", want: true},
+		{rule: "title:Inbox", app: "Mail", title: "inbox", want: true},
+	}
+
+	for _, tt := range tests {
+		rule, err := ParseAppRule(tt.rule)
+		if err != nil {
+			t.Fatalf("ParseAppRule(%q): %v", tt.rule, err)
+		}
+		if got := rule.Match(tt.app, tt.title); got != tt.want {
+			t.Errorf("%q matching app %q, title %q = %v, want %v", tt.rule, tt.app, tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestParseAppRuleErrors(t *testing.T) {
+	for _, rule := range []string{"", "title:", "/(unclosed/", "[abc", "title:*\\"} {
+		if _, err := ParseAppRule(rule); err == nil {
+			t.Errorf("ParseAppRule(%q) succeeded, want an error", rule)
+		}
+	}
+}
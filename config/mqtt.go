@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	defaultMQTTTopicPrefix = "tomatick"
+	defaultMQTTKeepAlive   = 60 * time.Second
+)
+
+// MQTT is an MQTT broker that receives session events next to the webhooks
+type MQTT struct {
+	Broker           string // tcp://host:1883 or ssl://host:8883; empty disables MQTT
+	ClientID         string // defaults to tomatick-<session id>
+	Username         string
+	Password         string
+	TopicPrefix      string   // events go to <prefix>/events/<type>, retained state to <prefix>/state
+	Events           []string // event types to publish; empty means all. The state topics are always kept current
+	QoS              byte     // 0 or 1
+	IncludeSensitive bool     // publish user content (tasks, AI transcripts, contexts)
+	KeepAlive        time.Duration
+	Timeout          time.Duration // connect and acknowledgement timeout
+	MaxRetries       int
+	RetryBaseDelay   time.Duration // doubled after every failed attempt
+}
+
+// getMQTT reads the MQTT sink from the environment
+func getMQTT() (MQTT, error) {
+	broker := getEnvVar("MQTT_BROKER")
+	if broker == "" {
+		return MQTT{}, nil
+	}
+
+	u, err := url.Parse(broker)
+	if err != nil || u.Host == "" {
+		return MQTT{}, fmt.Errorf("invalid MQTT_BROKER %q: expected a URL like tcp://localhost:1883", broker)
+	}
+	switch u.Scheme {
+	case "tcp", "mqtt", "ssl", "tls", "mqtts":
+	default:
+		return MQTT{}, fmt.Errorf("invalid MQTT_BROKER %q: unsupported scheme %q", broker, u.Scheme)
+	}
+
+	qos, err := parseIntEnv("MQTT_QOS", 1)
+	if err != nil || qos < 0 || qos > 1 {
+		return MQTT{}, fmt.Errorf("invalid MQTT_QOS: must be 0 or 1")
+	}
+
+	includeSensitive, err := parseBoolEnv("MQTT_INCLUDE_SENSITIVE", false)
+	if err != nil {
+		return MQTT{}, fmt.Errorf("invalid MQTT_INCLUDE_SENSITIVE: %w", err)
+	}
+
+	keepAlive, err := parseDurationEnv("MQTT_KEEPALIVE", defaultMQTTKeepAlive.String())
+	if err != nil || keepAlive < time.Second || keepAlive > 18*time.Hour {
+		return MQTT{}, fmt.Errorf("invalid MQTT_KEEPALIVE: must be between 1s and 18h")
+	}
+
+	// MQTT 3.1.1 only allows a password together with a user name
+	username, password := getEnvVar("MQTT_USERNAME"), getEnvVar("MQTT_PASSWORD")
+	if password != "" && username == "" {
+		return MQTT{}, fmt.Errorf("invalid MQTT_PASSWORD: MQTT_USERNAME must be set too")
+	}
+
+	prefix := getEnvVar("MQTT_TOPIC_PREFIX")
+	if prefix == "" {
+		prefix = defaultMQTTTopicPrefix
+	}
+
+	return MQTT{
+		Broker:           broker,
+		ClientID:         getEnvVar("MQTT_CLIENT_ID"),
+		Username:         username,
+		Password:         password,
+		TopicPrefix:      prefix,
+		Events:           splitCommaList(getEnvVar("MQTT_EVENTS"), false),
+		QoS:              byte(qos),
+		IncludeSensitive: includeSensitive,
+		KeepAlive:        keepAlive,
+		Timeout:          defaultWebhookTimeout,
+		MaxRetries:       defaultWebhookMaxRetries,
+		RetryBaseDelay:   defaultWebhookBaseDelay,
+	}, nil
+}
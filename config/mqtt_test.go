@@ -0,0 +1,44 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetMQTT(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "disabled", env: map[string]string{}},
+		{name: "anonymous", env: map[string]string{"MQTT_BROKER": "tcp://localhost:1883"}},
+		{name: "user and password", env: map[string]string{"MQTT_BROKER": "tcp://localhost:1883", "MQTT_USERNAME": "tomatick", "MQTT_PASSWORD": "secret"}},
+		{name: "user without password", env: map[string]string{"MQTT_BROKER": "tcp://localhost:1883", "MQTT_USERNAME": "tomatick"}},
+		{name: "password without user", env: map[string]string{"MQTT_BROKER": "tcp://localhost:1883", "MQTT_PASSWORD": "secret"}, wantErr: "MQTT_USERNAME"},
+		{name: "unsupported scheme", env: map[string]string{"MQTT_BROKER": "http://localhost:1883"}, wantErr: "unsupported scheme"},
+		{name: "QoS 2", env: map[string]string{"MQTT_BROKER": "tcp://localhost:1883", "MQTT_QOS": "2"}, wantErr: "MQTT_QOS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"MQTT_BROKER", "MQTT_USERNAME", "MQTT_PASSWORD", "MQTT_QOS"} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			mqtt, err := getMQTT()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("getMQTT error = %v, want one mentioning %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mqtt.Username != tt.env["MQTT_USERNAME"] || mqtt.Password != tt.env["MQTT_PASSWORD"] {
+				t.Errorf("credentials = %q/%q", mqtt.Username, mqtt.Password)
+			}
+		})
+	}
+}
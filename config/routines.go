@@ -0,0 +1,123 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Routine is a guided break activity made of timed steps
+type Routine struct {
+	Name  string // identifies the routine in BREAK_ROUTINES and the break history
+	Title string
+	Break string // short, long or any
+	Steps []RoutineStep
+}
+
+// RoutineStep is one instruction of a routine, shown for Duration
+type RoutineStep struct {
+	Instruction string
+	Duration    time.Duration
+}
+
+// Routines selects the break routines to rotate through
+type Routines struct {
+	Enabled []string  // routine names; empty enables every routine
+	Custom  []Routine // routines from the routines file, which replace built-ins of the same name
+}
+
+// routineFileEntry is the on-disk shape of a routine in the routines file
+type routineFileEntry struct {
+	Name  string `json:"name"`
+	Title string `json:"title"`
+	Break string `json:"break"`
+	Steps []struct {
+		Instruction string `json:"instruction"`
+		Duration    string `json:"duration"`
+	} `json:"steps"`
+}
+
+// getRoutines loads the routines file (ROUTINES_FILE, defaulting to
+// routines.json in the context directory) and the enabled routines from
+// BREAK_ROUTINES
+func getRoutines(contextDir string) (Routines, error) {
+	path := getEnvVar("ROUTINES_FILE")
+	explicit := path != ""
+	if !explicit {
+		path = filepath.Join(contextDir, "routines.json")
+	}
+
+	custom, err := loadRoutinesFile(path)
+	if err != nil && (explicit || !os.IsNotExist(err)) {
+		return Routines{}, fmt.Errorf("invalid routines file %s: %w", path, err)
+	}
+
+	return Routines{
+		Enabled: splitCommaList(strings.ToLower(getEnvVar("BREAK_ROUTINES")), false),
+		Custom:  custom,
+	}, nil
+}
+
+func loadRoutinesFile(path string) ([]Routine, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []routineFileEntry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, err
+	}
+
+	routines := make([]Routine, 0, len(entries))
+	for i, entry := range entries {
+		routine, err := entry.toRoutine()
+		if err != nil {
+			return nil, fmt.Errorf("routine #%d: %w", i+1, err)
+		}
+		routines = append(routines, routine)
+	}
+
+	return routines, nil
+}
+
+func (e routineFileEntry) toRoutine() (Routine, error) {
+	if e.Name == "" {
+		return Routine{}, fmt.Errorf("name is required")
+	}
+	if len(e.Steps) == 0 {
+		return Routine{}, fmt.Errorf("%s: at least one step is required", e.Name)
+	}
+
+	routine := Routine{
+		Name:  strings.ToLower(e.Name),
+		Title: e.Title,
+		Break: strings.ToLower(e.Break),
+	}
+	if routine.Title == "" {
+		routine.Title = e.Name
+	}
+	switch routine.Break {
+	case "":
+		routine.Break = "any"
+	case "short", "long", "any":
+	default:
+		return Routine{}, fmt.Errorf("%s: invalid break %q: must be short, long or any", e.Name, e.Break)
+	}
+
+	for j, step := range e.Steps {
+		if step.Instruction == "" {
+			return Routine{}, fmt.Errorf("%s: step #%d has no instruction", e.Name, j+1)
+		}
+		duration, err := time.ParseDuration(step.Duration)
+		if err != nil || duration <= 0 {
+			return Routine{}, fmt.Errorf("%s: step #%d has an invalid duration %q", e.Name, j+1, step.Duration)
+		}
+		routine.Steps = append(routine.Steps, RoutineStep{Instruction: step.Instruction, Duration: duration})
+	}
+
+	return routine, nil
+}
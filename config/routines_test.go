@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadRoutinesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routines.json")
+	content := `[
+		{"name": "Pushups", "title": "Push-ups", "break": "long", "steps": [
+			{"instruction": "Ten push-ups", "duration": "45s"},
+			{"instruction": "Rest", "duration": "1m"}
+		]},
+		{"name": "water", "steps": [{"instruction": "Drink a glass of water", "duration": "30s"}]}
+	]`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	routines, err := loadRoutinesFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routines) != 2 {
+		t.Fatalf("loaded %d routines, want 2", len(routines))
+	}
+
+	pushups := routines[0]
+	if pushups.Name != "pushups" || pushups.Title != "Push-ups" || pushups.Break != "long" {
+		t.Errorf("pushups = %+v", pushups)
+	}
+	if len(pushups.Steps) != 2 || pushups.Steps[0].Duration != 45*time.Second || pushups.Steps[1].Instruction != "Rest" {
+		t.Errorf("pushups steps = %+v", pushups.Steps)
+	}
+
+	water := routines[1]
+	if water.Title != "water" || water.Break != "any" {
+		t.Errorf("water = %+v, want its name as title for any break", water)
+	}
+}
+
+func TestLoadRoutinesFileErrors(t *testing.T) {
+	for _, content := range []string{
+		`[{"steps": [{"instruction": "x", "duration": "1m"}]}]`,
+		`[{"name": "empty"}]`,
+		`[{"name": "bad", "break": "lunch", "steps": [{"instruction": "x", "duration": "1m"}]}]`,
+		`[{"name": "bad", "steps": [{"duration": "1m"}]}]`,
+		`[{"name": "bad", "steps": [{"instruction": "x", "duration": "soon"}]}]`,
+		`[{"name": "bad", "steps": [{"instruction": "x", "duration": "-1m"}]}]`,
+	} {
+		path := filepath.Join(t.TempDir(), "routines.json")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadRoutinesFile(path); err == nil {
+			t.Errorf("loaded %s", content)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sound configures the sounds played at the end of timers and on break violations
+type Sound struct {
+	Sink           string // auto, pulse, alsa, afplay, powershell or none
+	Volume         int    // 0-100
+	WorkEnd        string // built-in sound name, path to an MP3 or WAV file, or none
+	BreakEnd       string
+	BreakViolation string
+}
+
+// getSound reads the sound settings from the environment
+func getSound() (Sound, error) {
+	sink := strings.ToLower(getEnvVar("SOUND_SINK"))
+	switch sink {
+	case "":
+		sink = "auto"
+	case "auto", "pulse", "alsa", "afplay", "powershell", "none":
+	default:
+		return Sound{}, fmt.Errorf("invalid SOUND_SINK %q: must be auto, pulse, alsa, afplay, powershell or none", sink)
+	}
+
+	volume, err := parseIntEnv("SOUND_VOLUME", 100)
+	if err != nil || volume < 0 || volume > 100 {
+		return Sound{}, fmt.Errorf("invalid SOUND_VOLUME: must be between 0 and 100")
+	}
+
+	return Sound{
+		Sink:           sink,
+		Volume:         volume,
+		WorkEnd:        soundEnv("SOUND_WORK_END", "softbeep"),
+		BreakEnd:       soundEnv("SOUND_BREAK_END", "softbeep"),
+		BreakViolation: soundEnv("SOUND_BREAK_VIOLATION", "none"),
+	}, nil
+}
+
+// soundEnv reads a sound setting, expanding a leading ~ in file paths
+func soundEnv(key, defaultValue string) string {
+	value := getEnvVar(key)
+	if value == "" {
+		return defaultValue
+	}
+	if rest, ok := strings.CutPrefix(value, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return value
+}
@@ -48,6 +48,191 @@ var requiredEnvVars = []EnvVar{
 		Description: "Number of cycles before a long break",
 		Required:    false, // We have a default value
 	},
+	{
+		Name:        "WEBHOOKS_FILE",
+		Description: "JSON file with per-endpoint webhook definitions (defaults to webhooks.json in the context directory)",
+		Required:    false,
+	},
+	{
+		Name:        "WEBHOOK_URLS",
+		Description: "Comma-separated webhook URLs that receive session events",
+		Required:    false,
+	},
+	{
+		Name:        "WEBHOOK_SECRETS",
+		Description: "Comma-separated HMAC secrets, matched positionally to WEBHOOK_URLS",
+		Required:    false,
+	},
+	{
+		Name:        "WEBHOOK_LOG_MAX_SIZE_MB",
+		Description: "Rotate the webhook delivery log once it reaches this size in megabytes (0 disables)",
+		Required:    false, // Defaults to 5
+	},
+	{
+		Name:        "WEBHOOK_LOG_MAX_AGE",
+		Description: "Rotate the webhook delivery log once its oldest entry is this old (e.g., 168h, 0 disables)",
+		Required:    false, // Defaults to 168h
+	},
+	{
+		Name:        "WEBHOOK_LOG_MAX_BACKUPS",
+		Description: "Number of rotated webhook logs to keep (0 keeps all)",
+		Required:    false, // Defaults to 5
+	},
+	{
+		Name:        "WORK_APPS",
+		Description: "Comma-separated work app rules: exact names, globs, /regexps/ or title:<pattern>",
+		Required:    false, // Defaults to common editors, terminals and browsers
+	},
+	{
+		Name:        "BREAK_APPS",
+		Description: "Comma-separated rules for break-friendly apps whose use isn't a break violation",
+		Required:    false, // Defaults to music, podcast and meditation apps
+	},
+	{
+		Name:        "MONITOR_IDLE_THRESHOLD",
+		Description: "Idle time after which the user counts as resting during a break (e.g., 10s)",
+		Required:    false, // Defaults to 10s
+	},
+	{
+		Name:        "MONITOR_ACTIVITY_THRESHOLD",
+		Description: "Continuous activity during a break that counts as a violation (e.g., 30s)",
+		Required:    false, // Defaults to 30s
+	},
+	{
+		Name:        "MONITOR_APP_CACHE_TTL",
+		Description: "How long the focused app is cached between lookups (e.g., 500ms)",
+		Required:    false, // Defaults to 500ms
+	},
+	{
+		Name:        "MONITOR_NOTIFY_INTERVAL",
+		Description: "Minimum time between break violation notifications (e.g., 60s)",
+		Required:    false, // Defaults to 60s
+	},
+	{
+		Name:        "FOCUS_TRACKING",
+		Description: "Track time spent idle or in non-work apps during focus sessions (true/false)",
+		Required:    false, // Defaults to false
+	},
+	{
+		Name:        "DISTRACTION_APPS",
+		Description: "Comma-separated rules for apps counted as distractions during focus sessions",
+		Required:    false, // Defaults to every app not in WORK_APPS
+	},
+	{
+		Name:        "FOCUS_IDLE_THRESHOLD",
+		Description: "Idle time after which focus time counts as time away (e.g., 1m)",
+		Required:    false, // Defaults to 1m
+	},
+	{
+		Name:        "MQTT_BROKER",
+		Description: "Also publish session events to this MQTT broker (e.g., tcp://localhost:1883 or ssl://broker:8883)",
+		Required:    false, // Disabled when empty
+	},
+	{
+		Name:        "MQTT_CLIENT_ID",
+		Description: "MQTT client ID",
+		Required:    false, // Defaults to tomatick-<session id>
+	},
+	{
+		Name:        "MQTT_USERNAME",
+		Description: "MQTT username",
+		Required:    false,
+	},
+	{
+		Name:        "MQTT_PASSWORD",
+		Description: "MQTT password; requires MQTT_USERNAME",
+		Required:    false,
+	},
+	{
+		Name:        "MQTT_TOPIC_PREFIX",
+		Description: "Prefix of the MQTT event and state topics",
+		Required:    false, // Defaults to tomatick
+	},
+	{
+		Name:        "MQTT_EVENTS",
+		Description: "Comma-separated event types to publish to MQTT",
+		Required:    false, // Defaults to all events
+	},
+	{
+		Name:        "MQTT_QOS",
+		Description: "MQTT quality of service, 0 or 1",
+		Required:    false, // Defaults to 1
+	},
+	{
+		Name:        "MQTT_INCLUDE_SENSITIVE",
+		Description: "Publish task names, contexts and AI transcripts to MQTT (true/false)",
+		Required:    false, // Defaults to false
+	},
+	{
+		Name:        "MQTT_KEEPALIVE",
+		Description: "MQTT keep-alive interval (e.g., 60s)",
+		Required:    false, // Defaults to 60s
+	},
+	{
+		Name:        "CONTROL_API_ADDR",
+		Description: "Serve the local control API on this address (e.g., 127.0.0.1:7766 or unix:/tmp/tomatick.sock)",
+		Required:    false, // Disabled when empty
+	},
+	{
+		Name:        "CONTROL_API_TOKEN",
+		Description: "Bearer token required by the control API",
+		Required:    false,
+	},
+	{
+		Name:        "NOTIFIER",
+		Description: "How to notify when timers end and breaks are skipped: auto, dbus, osc9, osc777, bell or none",
+		Required:    false, // Defaults to auto
+	},
+	{
+		Name:        "SOUND_SINK",
+		Description: "Where sounds are played: auto, pulse, alsa, afplay, powershell or none",
+		Required:    false, // Defaults to auto
+	},
+	{
+		Name:        "SOUND_VOLUME",
+		Description: "Volume of the sounds, from 0 to 100",
+		Required:    false, // Defaults to 100
+	},
+	{
+		Name:        "SOUND_WORK_END",
+		Description: "Sound at the end of a focus session: softbeep, chime, an MP3 or WAV file, or none",
+		Required:    false, // Defaults to softbeep
+	},
+	{
+		Name:        "SOUND_BREAK_END",
+		Description: "Sound at the end of a break: softbeep, chime, an MP3 or WAV file, or none",
+		Required:    false, // Defaults to softbeep
+	},
+	{
+		Name:        "SOUND_BREAK_VIOLATION",
+		Description: "Sound when a break reminder is shown: softbeep, chime, an MP3 or WAV file, or none",
+		Required:    false, // Defaults to none
+	},
+	{
+		Name:        "ADAPTIVE_BREAKS",
+		Description: "Lengthen breaks or bring the long break forward on signs of fatigue (true/false)",
+		Required:    false, // Defaults to true
+	},
+	{
+		Name:        "CHECK_INS",
+		Description: "Ask for 1-5 energy, focus and mood ratings at the start and end of each cycle (true/false)",
+		Required:    false, // Defaults to true
+	},
+	{
+		Name:        "ROUTINES_FILE",
+		Description: "JSON file with custom break routines",
+		Required:    false, // Defaults to routines.json in the context directory
+	},
+	{
+		Name:        "BREAK_ROUTINES",
+		Description: "Comma-separated break routines to rotate through, e.g. eyes,stretch,breathing",
+		Required:    false, // Defaults to every routine
+	},
+	{
+		Name:        "USE_EDITOR",
+		Description: "Write contexts and reflections in $EDITOR instead of line by line (true/false)",
+		Required:    false, // Defaults to false
+	},
 }
 
 func validateEnvVars() error {
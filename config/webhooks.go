@@ -0,0 +1,211 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	defaultWebhookTimeout    = 10 * time.Second
+	defaultWebhookMaxRetries = 3
+	defaultWebhookBaseDelay  = 1 * time.Second
+)
+
+// Webhook is a single webhook endpoint
+type Webhook struct {
+	URL              string
+	Events           []string          // event types to deliver; empty means all
+	Headers          map[string]string // extra request headers
+	Secret           string            // signs payloads with HMAC-SHA256 when set
+	Timeout          time.Duration
+	MaxRetries       int
+	RetryBaseDelay   time.Duration // doubled after every failed attempt
+	IncludeSensitive bool          // deliver user content (AI transcripts, contexts, reflections)
+	Template         string        // preset name (json, slack, discord, ntfy) or Go text/template; empty means json
+	ContentType      string        // overrides the preset's content type
+}
+
+// LogRotation controls when the webhook delivery log is rotated
+type LogRotation struct {
+	MaxSize    int64         // bytes; 0 disables size-based rotation
+	MaxAge     time.Duration // age of the oldest entry; 0 disables age-based rotation
+	MaxBackups int           // rotated files to keep; 0 keeps all
+}
+
+// getWebhookLogRotation reads the rotation policy, defaulting to 5MB, 7 days and 5 backups
+func getWebhookLogRotation() (LogRotation, error) {
+	maxSizeMB, err := parseIntEnv("WEBHOOK_LOG_MAX_SIZE_MB", 5)
+	if err != nil || maxSizeMB < 0 {
+		return LogRotation{}, fmt.Errorf("invalid WEBHOOK_LOG_MAX_SIZE_MB: must be a non-negative number of megabytes")
+	}
+
+	maxAge, err := parseDurationEnv("WEBHOOK_LOG_MAX_AGE", "168h")
+	if err != nil {
+		return LogRotation{}, fmt.Errorf("invalid WEBHOOK_LOG_MAX_AGE: %w", err)
+	}
+
+	maxBackups, err := parseIntEnv("WEBHOOK_LOG_MAX_BACKUPS", 5)
+	if err != nil || maxBackups < 0 {
+		return LogRotation{}, fmt.Errorf("invalid WEBHOOK_LOG_MAX_BACKUPS: must be a non-negative number")
+	}
+
+	return LogRotation{
+		MaxSize:    int64(maxSizeMB) << 20,
+		MaxAge:     maxAge,
+		MaxBackups: maxBackups,
+	}, nil
+}
+
+// webhookFileEntry is the on-disk shape of a webhook in the webhooks file
+type webhookFileEntry struct {
+	URL       string            `json:"url"`
+	Events    []string          `json:"events"`
+	Headers   map[string]string `json:"headers"`
+	Secret    string            `json:"secret"`
+	SecretEnv string            `json:"secret_env"`
+	Timeout   string            `json:"timeout"`
+	Retry     struct {
+		MaxRetries *int   `json:"max_retries"`
+		BaseDelay  string `json:"base_delay"`
+	} `json:"retry"`
+	IncludeSensitive bool   `json:"include_sensitive"`
+	Template         string `json:"template"`
+	TemplateFile     string `json:"template_file"`
+	ContentType      string `json:"content_type"`
+}
+
+// getWebhooks loads the webhooks file (WEBHOOKS_FILE, defaulting to
+// webhooks.json in the context directory) and appends the endpoints from
+// WEBHOOK_URLS. WEBHOOK_SECRETS is matched positionally to WEBHOOK_URLS;
+// leave a slot empty for endpoints that should not be signed.
+func getWebhooks(contextDir string) ([]Webhook, error) {
+	path := getEnvVar("WEBHOOKS_FILE")
+	explicit := path != ""
+	if !explicit {
+		path = filepath.Join(contextDir, "webhooks.json")
+	}
+
+	webhooks, err := loadWebhooksFile(path)
+	if err != nil && (explicit || !os.IsNotExist(err)) {
+		return nil, fmt.Errorf("invalid webhooks file %s: %w", path, err)
+	}
+
+	urls := splitCommaList(getEnvVar("WEBHOOK_URLS"), false)
+	secrets := splitCommaList(getEnvVar("WEBHOOK_SECRETS"), true)
+
+	if len(secrets) > len(urls) {
+		return nil, fmt.Errorf("invalid WEBHOOK_SECRETS: %d secrets for %d webhook URLs", len(secrets), len(urls))
+	}
+
+	for i, url := range urls {
+		// Endpoints from WEBHOOK_URLS keep their original behaviour: every event, full payloads
+		webhook := Webhook{
+			URL:              url,
+			Timeout:          defaultWebhookTimeout,
+			MaxRetries:       defaultWebhookMaxRetries,
+			RetryBaseDelay:   defaultWebhookBaseDelay,
+			IncludeSensitive: true,
+		}
+		if i < len(secrets) {
+			webhook.Secret = secrets[i]
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
+}
+
+func loadWebhooksFile(path string) ([]Webhook, error) {
+	baseDir := filepath.Dir(path)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []webhookFileEntry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]Webhook, 0, len(entries))
+	for i, entry := range entries {
+		webhook, err := entry.toWebhook(baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("webhook #%d: %w", i+1, err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
+}
+
+// toWebhook validates the entry; relative template files resolve against baseDir
+func (e webhookFileEntry) toWebhook(baseDir string) (Webhook, error) {
+	if e.URL == "" {
+		return Webhook{}, fmt.Errorf("url is required")
+	}
+
+	webhook := Webhook{
+		URL:              e.URL,
+		Events:           e.Events,
+		Headers:          e.Headers,
+		Secret:           e.Secret,
+		Timeout:          defaultWebhookTimeout,
+		MaxRetries:       defaultWebhookMaxRetries,
+		RetryBaseDelay:   defaultWebhookBaseDelay,
+		IncludeSensitive: e.IncludeSensitive,
+		Template:         e.Template,
+		ContentType:      e.ContentType,
+	}
+
+	if e.TemplateFile != "" {
+		if e.Template != "" {
+			return Webhook{}, fmt.Errorf("template and template_file are mutually exclusive")
+		}
+		path := e.TemplateFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return Webhook{}, fmt.Errorf("failed to read template_file: %w", err)
+		}
+		webhook.Template = string(content)
+	}
+
+	if e.SecretEnv != "" {
+		webhook.Secret = getEnvVar(e.SecretEnv)
+		if webhook.Secret == "" {
+			return Webhook{}, fmt.Errorf("secret_env %s is not set", e.SecretEnv)
+		}
+	}
+
+	if e.Timeout != "" {
+		timeout, err := time.ParseDuration(e.Timeout)
+		if err != nil || timeout <= 0 {
+			return Webhook{}, fmt.Errorf("invalid timeout %q", e.Timeout)
+		}
+		webhook.Timeout = timeout
+	}
+
+	if e.Retry.MaxRetries != nil {
+		if *e.Retry.MaxRetries < 0 {
+			return Webhook{}, fmt.Errorf("invalid retry.max_retries %d", *e.Retry.MaxRetries)
+		}
+		webhook.MaxRetries = *e.Retry.MaxRetries
+	}
+
+	if e.Retry.BaseDelay != "" {
+		delay, err := time.ParseDuration(e.Retry.BaseDelay)
+		if err != nil || delay < 0 {
+			return Webhook{}, fmt.Errorf("invalid retry.base_delay %q", e.Retry.BaseDelay)
+		}
+		webhook.RetryBaseDelay = delay
+	}
+
+	return webhook, nil
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetWebhooks(t *testing.T) {
+	defaults := func(url string) Webhook {
+		return Webhook{URL: url, Timeout: defaultWebhookTimeout, MaxRetries: defaultWebhookMaxRetries, RetryBaseDelay: defaultWebhookBaseDelay}
+	}
+	fromEnv := func(url, secret string) Webhook {
+		w := defaults(url)
+		w.Secret = secret
+		w.IncludeSensitive = true
+		return w
+	}
+	withTemplate := func(w Webhook, template string) Webhook {
+		w.Template = template
+		return w
+	}
+
+	tests := []struct {
+		name      string
+		file      string // contents of webhooks.json in the context directory; empty means none
+		otherFile string // contents of the file WEBHOOKS_FILE points to; empty leaves it unset
+		env       map[string]string
+		want      []Webhook
+		wantErr   string
+	}{
+		{name: "nothing configured", want: nil},
+		{name: "environment only",
+			env:  map[string]string{"WEBHOOK_URLS": "https://a.example/hook, https://b.example/hook"},
+			want: []Webhook{fromEnv("https://a.example/hook", ""), fromEnv("https://b.example/hook", "")}},
+		{name: "secrets matched by position",
+			env:  map[string]string{"WEBHOOK_URLS": "https://a.example/hook,https://b.example/hook", "WEBHOOK_SECRETS": ",whsec_b"},
+			want: []Webhook{fromEnv("https://a.example/hook", ""), fromEnv("https://b.example/hook", "whsec_b")}},
+		{name: "fewer secrets than URLs",
+			env:  map[string]string{"WEBHOOK_URLS": "https://a.example/hook,https://b.example/hook", "WEBHOOK_SECRETS": "whsec_a"},
+			want: []Webhook{fromEnv("https://a.example/hook", "whsec_a"), fromEnv("https://b.example/hook", "")}},
+		{name: "more secrets than URLs",
+			env:     map[string]string{"WEBHOOK_URLS": "https://a.example/hook", "WEBHOOK_SECRETS": "whsec_a,whsec_b"},
+			wantErr: "2 secrets for 1 webhook URLs"},
+		{name: "file and environment merged",
+			file: `[{"url": "https://file.example/hook", "template": "slack"}]`,
+			env:  map[string]string{"WEBHOOK_URLS": "https://env.example/hook"},
+			want: []Webhook{withTemplate(defaults("https://file.example/hook"), "slack"), fromEnv("https://env.example/hook", "")}},
+		{name: "WEBHOOKS_FILE replaces the default file",
+			file:      `[{"url": "https://default.example/hook"}]`,
+			otherFile: `[{"url": "https://other.example/hook"}]`,
+			want:      []Webhook{defaults("https://other.example/hook")}},
+		{name: "missing WEBHOOKS_FILE",
+			env:     map[string]string{"WEBHOOKS_FILE": "/nonexistent/webhooks.json"},
+			wantErr: "/nonexistent/webhooks.json"},
+		{name: "malformed file", file: `{"url": "https://a.example/hook"}`, wantErr: "invalid webhooks file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range []string{"WEBHOOKS_FILE", "WEBHOOK_URLS", "WEBHOOK_SECRETS"} {
+				t.Setenv(name, tt.env[name])
+			}
+			if tt.file != "" {
+				writeFile(t, filepath.Join(dir, "webhooks.json"), tt.file)
+			}
+			if tt.otherFile != "" {
+				path := filepath.Join(t.TempDir(), "hooks.json")
+				writeFile(t, path, tt.otherFile)
+				t.Setenv("WEBHOOKS_FILE", path)
+			}
+
+			got, err := getWebhooks(dir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("getWebhooks error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("getWebhooks = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhooksFileEntry(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "ntfy.tmpl"), "{{.Type}}")
+	t.Setenv("TOMATICK_TEST_SECRET", "whsec_env")
+
+	tests := []struct {
+		name    string
+		entry   string
+		check   func(t *testing.T, w Webhook)
+		wantErr string
+	}{
+		{name: "event filter and headers",
+			entry: `{"url": "https://a.example/hook", "events": ["work_start", "break_start"],
+				"headers": {"X-Api-Key": "abc", "Authorization": "Bearer t"}}`,
+			check: func(t *testing.T, w Webhook) {
+				if !reflect.DeepEqual(w.Events, []string{"work_start", "break_start"}) {
+					t.Errorf("Events = %v", w.Events)
+				}
+				if !reflect.DeepEqual(w.Headers, map[string]string{"X-Api-Key": "abc", "Authorization": "Bearer t"}) {
+					t.Errorf("Headers = %v", w.Headers)
+				}
+			}},
+		// The dispatcher warns about unknown events; the config keeps them as written
+		{name: "unknown event names kept",
+			entry: `{"url": "https://a.example/hook", "events": ["work_start", "lunch_time"]}`,
+			check: func(t *testing.T, w Webhook) {
+				if !reflect.DeepEqual(w.Events, []string{"work_start", "lunch_time"}) {
+					t.Errorf("Events = %v", w.Events)
+				}
+			}},
+		{name: "no event filter", entry: `{"url": "https://a.example/hook"}`,
+			check: func(t *testing.T, w Webhook) {
+				if len(w.Events) != 0 || w.IncludeSensitive {
+					t.Errorf("Events = %v, IncludeSensitive = %v; want all events without sensitive content", w.Events, w.IncludeSensitive)
+				}
+			}},
+		{name: "timeout and retry",
+			entry: `{"url": "https://a.example/hook", "timeout": "3s", "retry": {"max_retries": 0, "base_delay": "250ms"}}`,
+			check: func(t *testing.T, w Webhook) {
+				if w.Timeout != 3*time.Second || w.MaxRetries != 0 || w.RetryBaseDelay != 250*time.Millisecond {
+					t.Errorf("timeout %v, retries %d, delay %v", w.Timeout, w.MaxRetries, w.RetryBaseDelay)
+				}
+			}},
+		{name: "secret from environment", entry: `{"url": "https://a.example/hook", "secret_env": "TOMATICK_TEST_SECRET"}`,
+			check: func(t *testing.T, w Webhook) {
+				if w.Secret != "whsec_env" {
+					t.Errorf("Secret = %q", w.Secret)
+				}
+			}},
+		{name: "relative template file", entry: `{"url": "https://a.example/hook", "template_file": "ntfy.tmpl"}`,
+			check: func(t *testing.T, w Webhook) {
+				if w.Template != "{{.Type}}" {
+					t.Errorf("Template = %q", w.Template)
+				}
+			}},
+		{name: "missing url", entry: `{"events": ["work_start"]}`, wantErr: "url is required"},
+		{name: "unset secret_env", entry: `{"url": "https://a.example/hook", "secret_env": "TOMATICK_TEST_UNSET"}`, wantErr: "TOMATICK_TEST_UNSET"},
+		{name: "invalid timeout", entry: `{"url": "https://a.example/hook", "timeout": "soon"}`, wantErr: "invalid timeout"},
+		{name: "negative retries", entry: `{"url": "https://a.example/hook", "retry": {"max_retries": -1}}`, wantErr: "max_retries"},
+		{name: "template and template file",
+			entry: `{"url": "https://a.example/hook", "template": "json", "template_file": "ntfy.tmpl"}`, wantErr: "mutually exclusive"},
+		{name: "missing template file", entry: `{"url": "https://a.example/hook", "template_file": "gone.tmpl"}`, wantErr: "template_file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "webhooks.json")
+			writeFile(t, path, "["+tt.entry+"]")
+
+			webhooks, err := loadWebhooksFile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadWebhooksFile error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(webhooks) != 1 {
+				t.Fatalf("webhooks = %+v, want one", webhooks)
+			}
+			tt.check(t, webhooks[0])
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,47 @@
+// Package api serves a small local HTTP API for controlling a running session,
+// for tools such as Stream Deck buttons, editor plugins and shell prompts.
+package api
+
+import (
+	"errors"
+	"time"
+)
+
+// Phases reported in State
+const (
+	PhaseIdle       = "idle"
+	PhasePlanning   = "planning"
+	PhaseWork       = "work"
+	PhaseReflection = "reflection"
+	PhaseShortBreak = "short_break"
+	PhaseLongBreak  = "long_break"
+)
+
+// ErrConflict is returned by a Controller when an action does not apply to
+// the current phase, e.g. skipping a break during work
+var ErrConflict = errors.New("action not available in the current phase")
+
+// State is a snapshot of the running session
+type State struct {
+	SessionID        string    `json:"session_id"`
+	Phase            string    `json:"phase"`
+	Cycle            int       `json:"cycle"`
+	CyclesCompleted  int       `json:"cycles_completed"`
+	Paused           bool      `json:"paused"`
+	TimerRunning     bool      `json:"timer_running"`
+	DurationSeconds  int       `json:"duration_seconds,omitempty"`
+	ElapsedSeconds   int       `json:"elapsed_seconds,omitempty"`
+	RemainingSeconds int       `json:"remaining_seconds,omitempty"`
+	Tasks            []string  `json:"tasks"`
+	SessionStarted   time.Time `json:"session_started"`
+}
+
+// Controller is the running session, as seen by the API
+type Controller interface {
+	State() State
+	Pause() error
+	Resume() error
+	SkipBreak() error
+	AddTask(task string) error
+	EndSession() error
+}
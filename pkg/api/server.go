@@ -0,0 +1,240 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const unixPrefix = "unix:"
+
+// Server exposes a Controller over HTTP on a loopback address or Unix socket
+type Server struct {
+	controller Controller
+	token      string
+	listener   net.Listener
+	httpServer *http.Server
+	socketPath string
+	hosts      map[string]bool // Host headers accepted on a TCP listener
+}
+
+// NewServer listens on addr, either host:port on a loopback interface or
+// unix:/path/to.sock. When token is set, requests must send it as a bearer token.
+func NewServer(addr, token string, controller Controller) (*Server, error) {
+	s := &Server{controller: controller, token: token}
+
+	var err error
+	if strings.HasPrefix(addr, unixPrefix) {
+		s.socketPath = strings.TrimPrefix(addr, unixPrefix)
+		// A socket left behind by a crashed run would block the listener
+		if info, statErr := os.Stat(s.socketPath); statErr == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(s.socketPath)
+		}
+		s.listener, err = net.Listen("unix", s.socketPath)
+		if err == nil {
+			err = os.Chmod(s.socketPath, 0600)
+		}
+	} else {
+		if err = checkLoopback(addr); err != nil {
+			return nil, err
+		}
+		s.listener, err = net.Listen("tcp", addr)
+	}
+	if err != nil {
+		if s.listener != nil {
+			s.listener.Close()
+		}
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	if s.socketPath == "" {
+		s.hosts = loopbackHosts(s.listener.Addr())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/state", s.handle(http.MethodGet, s.getState))
+	mux.HandleFunc("/v1/tasks", s.handleTasks)
+	mux.HandleFunc("/v1/timer/pause", s.handle(http.MethodPost, s.action(controller.Pause)))
+	mux.HandleFunc("/v1/timer/resume", s.handle(http.MethodPost, s.action(controller.Resume)))
+	mux.HandleFunc("/v1/break/skip", s.handle(http.MethodPost, s.action(controller.SkipBreak)))
+	mux.HandleFunc("/v1/session/end", s.handle(http.MethodPost, s.action(controller.EndSession)))
+
+	s.httpServer = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s, nil
+}
+
+// checkLoopback refuses to expose the API beyond this machine
+func checkLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid control API address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("control API address %q must be on localhost, 127.0.0.1 or ::1", addr)
+}
+
+// loopbackHosts are the Host headers a local client sends to addr. Any
+// other name is refused, as it is how a DNS rebinding page reaches the API.
+func loopbackHosts(addr net.Addr) map[string]bool {
+	_, port, _ := net.SplitHostPort(addr.String())
+	hosts := make(map[string]bool)
+	for _, host := range []string{"localhost", "127.0.0.1", "::1"} {
+		hosts[net.JoinHostPort(host, port)] = true
+	}
+	return hosts
+}
+
+// Addr returns the address the server listens on
+func (s *Server) Addr() string {
+	if s.socketPath != "" {
+		return unixPrefix + s.socketPath
+	}
+	return s.listener.Addr().String()
+}
+
+// Serve handles requests until Close is called
+func (s *Server) Serve() error {
+	err := s.httpServer.Serve(s.listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Close stops the server, giving in-flight requests a moment to finish
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err := s.httpServer.Shutdown(ctx)
+	if s.socketPath != "" {
+		os.Remove(s.socketPath)
+	}
+	return err
+}
+
+// handle wraps a handler with method, token and browser checks
+func (s *Server) handle(method string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("use %s", method))
+			return
+		}
+		if status, err := s.authorize(r); err != nil {
+			writeError(w, status, err)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// authorize checks the bearer token and rejects browser requests. Web pages
+// can reach localhost, so any request carrying an Origin header is refused and
+// POST bodies must be JSON, which browsers cannot send cross-origin without a
+// preflight this server never answers. A page whose name was rebound to
+// 127.0.0.1 sends same-origin requests without an Origin header, but with
+// its own name as the Host, so TCP requests must name a loopback host.
+func (s *Server) authorize(r *http.Request) (int, error) {
+	if s.hosts != nil && !s.hosts[strings.ToLower(r.Host)] {
+		return http.StatusForbidden, fmt.Errorf("host %q is not allowed", r.Host)
+	}
+	if r.Header.Get("Origin") != "" {
+		return http.StatusForbidden, errors.New("browser requests are not allowed")
+	}
+	if r.Method == http.MethodPost && r.ContentLength != 0 {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType != "application/json" {
+			return http.StatusUnsupportedMediaType, errors.New("request body must be application/json")
+		}
+	}
+	if s.token == "" {
+		return 0, nil
+	}
+	got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+		return http.StatusUnauthorized, errors.New("missing or invalid bearer token")
+	}
+	return 0, nil
+}
+
+func (s *Server) getState(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.controller.State())
+}
+
+func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.handle(http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, map[string][]string{"tasks": s.controller.State().Tasks})
+		})(w, r)
+	default:
+		s.handle(http.MethodPost, s.addTask)(w, r)
+	}
+}
+
+func (s *Server) addTask(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Task string `json:"task"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid JSON body: %w", err))
+		return
+	}
+	body.Task = strings.TrimSpace(body.Task)
+	if body.Task == "" {
+		writeError(w, http.StatusBadRequest, errors.New("task cannot be empty"))
+		return
+	}
+
+	if err := s.controller.AddTask(body.Task); err != nil {
+		writeActionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.controller.State())
+}
+
+// action runs a Controller action and responds with the resulting state
+func (s *Server) action(fn func() error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := fn(); err != nil {
+			writeActionError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, s.controller.State())
+	}
+}
+
+func writeActionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrConflict) {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testToken = "s3cret"
+
+// fakeController records the actions it receives
+type fakeController struct {
+	mu     sync.Mutex
+	state  State
+	paused int
+}
+
+func (c *fakeController) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+func (c *fakeController) Pause() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state.Phase != PhaseWork {
+		return ErrConflict
+	}
+	c.paused++
+	c.state.Paused = true
+	return nil
+}
+
+func (c *fakeController) Resume() error     { return nil }
+func (c *fakeController) SkipBreak() error  { return ErrConflict }
+func (c *fakeController) EndSession() error { return nil }
+
+func (c *fakeController) AddTask(task string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.state.Tasks = append(c.state.Tasks, task)
+	return nil
+}
+
+// newTestServer returns a server with a token on a free loopback port
+func newTestServer(t *testing.T) (*Server, *fakeController) {
+	t.Helper()
+	controller := &fakeController{state: State{Phase: PhaseWork, Tasks: []string{}}}
+	s, err := NewServer("127.0.0.1:0", testToken, controller)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s, controller
+}
+
+// do sends a request straight to the server's handler
+func do(s *Server, method, path, contentType, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Host = s.listener.Addr().String()
+	if body == "" {
+		req.ContentLength = 0
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for key, values := range header {
+		if key == "Host" {
+			req.Host = values[0]
+			continue
+		}
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	s.httpServer.Handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestTokenRequired(t *testing.T) {
+	s, controller := newTestServer(t)
+
+	for name, auth := range map[string]string{
+		"missing token": "",
+		"wrong token":   "Bearer nope",
+		"no scheme":     "nope",
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := do(s, http.MethodPost, "/v1/timer/pause", "", "", http.Header{"Authorization": {auth}})
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+	if controller.paused != 0 {
+		t.Error("paused without a valid token")
+	}
+}
+
+func TestBrowserRequestsRejected(t *testing.T) {
+	s, controller := newTestServer(t)
+	_, port, _ := net.SplitHostPort(s.Addr())
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		header http.Header
+	}{
+		{name: "cross-origin POST", method: http.MethodPost, path: "/v1/tasks", body: `{"task":"exfiltrate"}`,
+			header: http.Header{"Origin": {"https://evil.example"}}},
+		{name: "GET with Origin", method: http.MethodGet, path: "/v1/state", header: http.Header{"Origin": {"null"}}},
+		{name: "DNS rebinding read", method: http.MethodGet, path: "/v1/state", header: http.Header{"Host": {"evil.example:" + port}}},
+		{name: "DNS rebinding POST", method: http.MethodPost, path: "/v1/tasks", body: `{"task":"exfiltrate"}`,
+			header: http.Header{"Host": {"evil.example:" + port}}},
+		{name: "loopback on another port", method: http.MethodGet, path: "/v1/state", header: http.Header{"Host": {"localhost:1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contentType := ""
+			if tt.body != "" {
+				contentType = "application/json"
+			}
+			if rec := do(s, tt.method, tt.path, contentType, tt.body, tt.header); rec.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+			}
+		})
+	}
+	if tasks := controller.State().Tasks; len(tasks) != 0 {
+		t.Errorf("tasks = %v, want none", tasks)
+	}
+
+	for _, host := range []string{"localhost:" + port, "127.0.0.1:" + port, "[::1]:" + port} {
+		if rec := do(s, http.MethodGet, "/v1/state", "", "", http.Header{"Host": {host}}); rec.Code != http.StatusOK {
+			t.Errorf("Host %s: status = %d, want %d", host, rec.Code, http.StatusOK)
+		}
+	}
+}
+
+func TestNonJSONBodyRejected(t *testing.T) {
+	s, controller := newTestServer(t)
+
+	// The content types a cross-origin form or fetch can send without a preflight
+	for _, contentType := range []string{"text/plain", "application/x-www-form-urlencoded", "multipart/form-data", ""} {
+		rec := do(s, http.MethodPost, "/v1/tasks", contentType, `{"task":"sneaky"}`, nil)
+		if rec.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("Content-Type %q: status = %d, want %d", contentType, rec.Code, http.StatusUnsupportedMediaType)
+		}
+	}
+	if tasks := controller.State().Tasks; len(tasks) != 0 {
+		t.Errorf("tasks = %v, want none", tasks)
+	}
+}
+
+func TestAddTask(t *testing.T) {
+	s, controller := newTestServer(t)
+
+	rec := do(s, http.MethodPost, "/v1/tasks", "application/json; charset=utf-8", `{"task":"  Review PR #42 "}`, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body)
+	}
+	var state State
+	if err := json.NewDecoder(rec.Body).Decode(&state); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Tasks) != 1 || state.Tasks[0] != "Review PR #42" {
+		t.Errorf("tasks = %v, want the trimmed task", state.Tasks)
+	}
+
+	if rec := do(s, http.MethodPost, "/v1/tasks", "application/json", `{"task":" "}`, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("empty task: status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if tasks := controller.State().Tasks; len(tasks) != 1 {
+		t.Errorf("tasks = %v, want one", tasks)
+	}
+}
+
+func TestPause(t *testing.T) {
+	s, controller := newTestServer(t)
+
+	rec := do(s, http.MethodPost, "/v1/timer/pause", "", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	var state State
+	if err := json.NewDecoder(rec.Body).Decode(&state); err != nil {
+		t.Fatal(err)
+	}
+	if !state.Paused || controller.paused != 1 {
+		t.Errorf("state = %+v after %d pauses, want paused once", state, controller.paused)
+	}
+
+	if rec := do(s, http.MethodGet, "/v1/timer/pause", "", "", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if rec := do(s, http.MethodPost, "/v1/break/skip", "", "", nil); rec.Code != http.StatusConflict {
+		t.Errorf("skipping a break during work: status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
+
+func TestListenAddress(t *testing.T) {
+	for _, addr := range []string{"0.0.0.0:0", "192.168.1.10:7777", ":7777", "example.com:80"} {
+		if s, err := NewServer(addr, "", &fakeController{}); err == nil {
+			s.Close()
+			t.Errorf("NewServer(%q) listens beyond this machine", addr)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "tomatick.sock")
+	s, err := NewServer(unixPrefix+path, "", &fakeController{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("socket mode = %v, want 0600", info.Mode().Perm())
+	}
+	if s.Addr() != unixPrefix+path {
+		t.Errorf("Addr = %q", s.Addr())
+	}
+	// Browsers can't reach a Unix socket, so its clients may send any Host
+	if rec := do(s, http.MethodGet, "/v1/state", "", "", http.Header{"Host": {"tomatick"}}); rec.Code != http.StatusOK {
+		t.Errorf("Unix socket request: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,80 @@
+// Package audio plays tomatick's sounds in-process: sounds are embedded in
+// the binary or read from files, decoded to PCM and written to an audio sink.
+package audio
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+// playTimeout is how much longer than the sound a sink may take to play it
+const playTimeout = 5 * time.Second
+
+// Event is a moment that can have a sound
+type Event string
+
+const (
+	WorkEnd        Event = "work_end"
+	BreakEnd       Event = "break_end"
+	BreakViolation Event = "break_violation"
+)
+
+// Player plays the sound of each event on a sink
+type Player struct {
+	sink   Sink
+	sounds map[Event]*PCM // events without a sound are missing
+
+	mu sync.Mutex // plays one sound at a time
+}
+
+// NewPlayer loads the configured sounds at the configured volume. A sound
+// that can't be loaded is replaced by the soft beep, with a warning.
+func NewPlayer(cfg config.Sound, sink Sink) *Player {
+	p := &Player{sink: sink, sounds: make(map[Event]*PCM)}
+
+	loaded := make(map[string]*PCM)
+	for event, name := range map[Event]string{
+		WorkEnd:        cfg.WorkEnd,
+		BreakEnd:       cfg.BreakEnd,
+		BreakViolation: cfg.BreakViolation,
+	} {
+		pcm, ok := loaded[name]
+		if !ok {
+			var err error
+			pcm, err = Load(name)
+			if err != nil {
+				fmt.Printf("Warning: Using the default %s sound: %v\n", event, err)
+				pcm, _ = Load(SoftBeep)
+			}
+			if pcm != nil {
+				pcm = pcm.Scaled(cfg.Volume)
+			}
+			loaded[name] = pcm
+		}
+		if pcm != nil {
+			p.sounds[event] = pcm
+		}
+	}
+
+	return p
+}
+
+// Play plays the sound of the event, if it has one, and returns when it
+// has finished
+func (p *Player) Play(event Event) error {
+	pcm, ok := p.sounds[event]
+	if !ok {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), pcm.Duration()+playTimeout)
+	defer cancel()
+	return p.sink.Play(ctx, pcm)
+}
@@ -0,0 +1,142 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+func TestEmbeddedSoftBeepDecodes(t *testing.T) {
+	pcm, err := Load(SoftBeep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pcm.SampleRate == 0 || pcm.Channels != 2 {
+		t.Fatalf("format = %d Hz, %d channels", pcm.SampleRate, pcm.Channels)
+	}
+	if d := pcm.Duration(); d < 100*time.Millisecond || d > 10*time.Second {
+		t.Errorf("duration = %v, want a short beep", d)
+	}
+}
+
+func TestWAVRoundTrip(t *testing.T) {
+	original := &PCM{SampleRate: 22050, Channels: 1, Samples: []int16{0, 1000, -1000, 32767, -32768}}
+
+	decoded, err := DecodeWAV(bytes.NewReader(original.WAV()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.SampleRate != original.SampleRate || decoded.Channels != original.Channels {
+		t.Fatalf("format = %d Hz, %d channels, want 22050 Hz mono", decoded.SampleRate, decoded.Channels)
+	}
+	if len(decoded.Samples) != len(original.Samples) {
+		t.Fatalf("samples = %v, want %v", decoded.Samples, original.Samples)
+	}
+	for i := range original.Samples {
+		if decoded.Samples[i] != original.Samples[i] {
+			t.Fatalf("samples = %v, want %v", decoded.Samples, original.Samples)
+		}
+	}
+}
+
+func TestDecodeWAVRejectsOtherFiles(t *testing.T) {
+	if _, err := DecodeWAV(bytes.NewReader([]byte("ID3 not a wav file"))); err == nil {
+		t.Error("decoded an MP3 header as WAV")
+	}
+}
+
+func TestScaled(t *testing.T) {
+	pcm := &PCM{SampleRate: 8000, Channels: 1, Samples: []int16{1000, -32768, 32767}}
+
+	half := pcm.Scaled(50)
+	if half.Samples[0] != 500 || half.Samples[1] != -16384 || half.Samples[2] != 16383 {
+		t.Errorf("50%% = %v", half.Samples)
+	}
+	if pcm.Samples[0] != 1000 {
+		t.Error("scaling changed the original")
+	}
+	for _, s := range pcm.Scaled(0).Samples {
+		if s != 0 {
+			t.Fatalf("0%% is not silent: %v", pcm.Scaled(0).Samples)
+		}
+	}
+}
+
+func TestPlayerPlaysPerEventSounds(t *testing.T) {
+	sink := &Silent{}
+	player := NewPlayer(config.Sound{
+		Volume:         100,
+		WorkEnd:        SoftBeep,
+		BreakEnd:       Chime,
+		BreakViolation: None,
+	}, sink)
+
+	for _, event := range []Event{WorkEnd, BreakEnd, BreakViolation} {
+		if err := player.Play(event); err != nil {
+			t.Fatalf("Play(%s): %v", event, err)
+		}
+	}
+
+	played := sink.Played()
+	if len(played) != 2 {
+		t.Fatalf("played %d sounds, want the work and break end sounds only", len(played))
+	}
+	beep, _ := Load(SoftBeep)
+	if len(played[0].Samples) != len(beep.Samples) {
+		t.Error("work end didn't play the soft beep")
+	}
+	if len(played[1].Samples) != len(chime().Samples) {
+		t.Error("break end didn't play the chime")
+	}
+}
+
+func TestPlayerFallsBackToSoftBeep(t *testing.T) {
+	sink := &Silent{}
+	player := NewPlayer(config.Sound{Volume: 40, WorkEnd: filepath.Join(t.TempDir(), "missing.mp3")}, sink)
+
+	if err := player.Play(WorkEnd); err != nil {
+		t.Fatal(err)
+	}
+	beep, _ := Load(SoftBeep)
+	played := sink.Played()
+	if len(played) != 1 || len(played[0].Samples) != len(beep.Samples) {
+		t.Fatalf("played %d sounds, want the soft beep", len(played))
+	}
+	// At 40% volume
+	quiet := beep.Scaled(40)
+	for i := range quiet.Samples {
+		if played[0].Samples[i] != quiet.Samples[i] {
+			t.Fatalf("sample %d = %d, want %d", i, played[0].Samples[i], quiet.Samples[i])
+		}
+	}
+}
+
+func TestStreamSinkWritesRawPCM(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no shell")
+	}
+
+	out := filepath.Join(t.TempDir(), "out.raw")
+	sink := &streamSink{command: "sh", args: func(*PCM) []string {
+		return []string{"-c", "cat > " + out}
+	}}
+
+	pcm := &PCM{SampleRate: 8000, Channels: 1, Samples: []int16{1, -2, 300}}
+	if err := sink.Play(context.Background(), pcm); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{1, 0, 0xfe, 0xff, 0x2c, 0x01}; !bytes.Equal(got, want) {
+		t.Errorf("raw PCM = %x, want %x", got, want)
+	}
+}
@@ -0,0 +1,234 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/1x-eng/tomatick/assets"
+	"github.com/hajimehoshi/go-mp3"
+)
+
+// Built-in sounds
+const (
+	SoftBeep = "softbeep"
+	Chime    = "chime"
+	None     = "none"
+)
+
+// PCM is decoded audio: interleaved signed 16-bit samples
+type PCM struct {
+	SampleRate int
+	Channels   int
+	Samples    []int16
+}
+
+// Duration is how long the audio plays
+func (p *PCM) Duration() time.Duration {
+	if p.SampleRate == 0 || p.Channels == 0 {
+		return 0
+	}
+	frames := len(p.Samples) / p.Channels
+	return time.Duration(frames) * time.Second / time.Duration(p.SampleRate)
+}
+
+// Bytes returns the samples as raw little-endian PCM
+func (p *PCM) Bytes() []byte {
+	out := make([]byte, 2*len(p.Samples))
+	for i, sample := range p.Samples {
+		binary.LittleEndian.PutUint16(out[2*i:], uint16(sample))
+	}
+	return out
+}
+
+// WAV encodes the audio as a WAV file
+func (p *PCM) WAV() []byte {
+	data := p.Bytes()
+
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(36+len(data)))
+	b.WriteString("WAVEfmt ")
+	binary.Write(&b, binary.LittleEndian, struct {
+		Size          uint32
+		Format        uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+	}{16, 1, uint16(p.Channels), uint32(p.SampleRate), uint32(p.SampleRate * p.Channels * 2), uint16(p.Channels * 2), 16})
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(len(data)))
+	b.Write(data)
+	return b.Bytes()
+}
+
+// Scaled returns a copy at volume percent of the original loudness
+func (p *PCM) Scaled(volume int) *PCM {
+	scaled := &PCM{SampleRate: p.SampleRate, Channels: p.Channels, Samples: make([]int16, len(p.Samples))}
+	for i, sample := range p.Samples {
+		scaled.Samples[i] = int16(int(sample) * volume / 100)
+	}
+	return scaled
+}
+
+// Load returns a built-in sound or decodes an MP3 or WAV file. None
+// returns nil.
+func Load(name string) (*PCM, error) {
+	switch name {
+	case None, "":
+		return nil, nil
+	case SoftBeep:
+		return DecodeMP3(bytes.NewReader(assets.SoftBeep))
+	case Chime:
+		return chime(), nil
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".mp3":
+		return DecodeMP3(f)
+	case ".wav":
+		return DecodeWAV(f)
+	default:
+		return nil, fmt.Errorf("%s: only MP3 and WAV files are supported", name)
+	}
+}
+
+// DecodeMP3 decodes an MP3 stream
+func DecodeMP3(r io.Reader) (*PCM, error) {
+	d, err := mp3.NewDecoder(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode MP3: %w", err)
+	}
+	data, err := io.ReadAll(d)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode MP3: %w", err)
+	}
+
+	// go-mp3 always decodes to 16-bit stereo
+	return &PCM{SampleRate: d.SampleRate(), Channels: 2, Samples: samples16(data)}, nil
+}
+
+// DecodeWAV decodes an uncompressed 8 or 16-bit WAV stream
+func DecodeWAV(r io.Reader) (*PCM, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil || string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, errors.New("not a WAV file")
+	}
+
+	var pcm *PCM
+	var bits int
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, errors.New("WAV file has no data")
+		}
+		id, size := string(chunk[0:4]), int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		body := io.LimitReader(r, size+size%2) // chunks are padded to an even size
+
+		switch id {
+		case "fmt ":
+			var format struct {
+				Format        uint16
+				Channels      uint16
+				SampleRate    uint32
+				ByteRate      uint32
+				BlockAlign    uint16
+				BitsPerSample uint16
+			}
+			if err := binary.Read(body, binary.LittleEndian, &format); err != nil {
+				return nil, fmt.Errorf("invalid WAV format: %w", err)
+			}
+			// 0xFFFE is WAVE_FORMAT_EXTENSIBLE, used by some encoders for plain PCM
+			if format.Format != 1 && format.Format != 0xFFFE {
+				return nil, errors.New("only uncompressed PCM WAV files are supported")
+			}
+			if format.BitsPerSample != 8 && format.BitsPerSample != 16 {
+				return nil, fmt.Errorf("unsupported WAV sample size of %d bits", format.BitsPerSample)
+			}
+			if format.Channels == 0 || format.SampleRate == 0 {
+				return nil, errors.New("invalid WAV format")
+			}
+			pcm = &PCM{SampleRate: int(format.SampleRate), Channels: int(format.Channels)}
+			bits = int(format.BitsPerSample)
+		case "data":
+			if pcm == nil {
+				return nil, errors.New("WAV data before its format")
+			}
+			data, err := io.ReadAll(io.LimitReader(r, size))
+			if err != nil {
+				return nil, err
+			}
+			if bits == 8 {
+				// 8-bit WAV samples are unsigned
+				pcm.Samples = make([]int16, len(data))
+				for i, b := range data {
+					pcm.Samples[i] = int16(int(b)-128) << 8
+				}
+			} else {
+				pcm.Samples = samples16(data)
+			}
+			return pcm, nil
+		}
+
+		if _, err := io.Copy(io.Discard, body); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// samples16 reads little-endian 16-bit samples
+func samples16(data []byte) []int16 {
+	samples := make([]int16, len(data)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(data[2*i:]))
+	}
+	return samples
+}
+
+// chime synthesizes a two-note chime, E6 then C6, each fading out
+func chime() *PCM {
+	const (
+		rate     = 44100
+		duration = 1200 * time.Millisecond
+	)
+	notes := []struct {
+		freq  float64
+		start float64 // seconds
+	}{
+		{1318.51, 0},
+		{1046.50, 0.25},
+	}
+
+	frames := int(duration.Seconds() * rate)
+	pcm := &PCM{SampleRate: rate, Channels: 2, Samples: make([]int16, 2*frames)}
+	for i := 0; i < frames; i++ {
+		t := float64(i) / rate
+		var v float64
+		for _, note := range notes {
+			if t < note.start {
+				continue
+			}
+			dt := t - note.start
+			v += 0.3 * math.Exp(-4*dt) * math.Sin(2*math.Pi*note.freq*dt)
+		}
+		sample := int16(v * math.MaxInt16)
+		pcm.Samples[2*i] = sample
+		pcm.Samples[2*i+1] = sample
+	}
+	return pcm
+}
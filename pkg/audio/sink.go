@@ -0,0 +1,162 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Sink plays PCM audio
+type Sink interface {
+	Play(ctx context.Context, pcm *PCM) error
+}
+
+// NewSink returns the sink of the given kind: pulse, alsa, afplay,
+// powershell or none. Auto picks the one for this system.
+func NewSink(kind string) (Sink, error) {
+	if kind == "auto" {
+		kind = detectSink()
+		if kind == "" {
+			return nil, fmt.Errorf("no audio player found: install pacat (pulseaudio-utils) or aplay (alsa-utils)")
+		}
+	}
+
+	var sink Sink
+	switch kind {
+	case "none":
+		return &Silent{}, nil
+	case "pulse":
+		sink = &streamSink{command: "pacat", args: func(pcm *PCM) []string {
+			return []string{"--playback", "--raw", "--format=s16le",
+				"--rate=" + strconv.Itoa(pcm.SampleRate), "--channels=" + strconv.Itoa(pcm.Channels),
+				"--client-name=tomatick"}
+		}}
+	case "alsa":
+		sink = &streamSink{command: "aplay", args: func(pcm *PCM) []string {
+			return []string{"-q", "-t", "raw", "-f", "S16_LE",
+				"-r", strconv.Itoa(pcm.SampleRate), "-c", strconv.Itoa(pcm.Channels), "-"}
+		}}
+	case "afplay":
+		sink = &fileSink{command: "afplay", args: func(path string) []string {
+			return []string{path}
+		}}
+	case "powershell":
+		sink = &fileSink{command: "powershell", args: func(path string) []string {
+			quoted := "'" + strings.ReplaceAll(path, "'", "''") + "'"
+			return []string{"-NoProfile", "-Command", "(New-Object Media.SoundPlayer " + quoted + ").PlaySync();"}
+		}}
+	default:
+		return nil, fmt.Errorf("unknown audio sink %q", kind)
+	}
+
+	if err := lookPath(sink); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// detectSink returns the sink kind for this system, or "" without one
+func detectSink() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "afplay"
+	case "windows":
+		return "powershell"
+	}
+
+	// PipeWire serves the PulseAudio socket too
+	if _, err := exec.LookPath("pacat"); err == nil {
+		socket := filepath.Join(os.Getenv("XDG_RUNTIME_DIR"), "pulse", "native")
+		if _, err := os.Stat(socket); err == nil || os.Getenv("PULSE_SERVER") != "" {
+			return "pulse"
+		}
+	}
+	if _, err := exec.LookPath("aplay"); err == nil {
+		return "alsa"
+	}
+	return ""
+}
+
+func lookPath(sink Sink) error {
+	var command string
+	switch s := sink.(type) {
+	case *streamSink:
+		command = s.command
+	case *fileSink:
+		command = s.command
+	}
+	if _, err := exec.LookPath(command); err != nil {
+		return fmt.Errorf("audio player %s not found: %w", command, err)
+	}
+	return nil
+}
+
+// streamSink pipes raw PCM into a player command, like pacat or aplay
+type streamSink struct {
+	command string
+	args    func(pcm *PCM) []string
+}
+
+func (s *streamSink) Play(ctx context.Context, pcm *PCM) error {
+	cmd := exec.CommandContext(ctx, s.command, s.args(pcm)...)
+	cmd.Stdin = bytes.NewReader(pcm.Bytes())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", s.command, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// fileSink writes a WAV file for players that can't read from stdin,
+// like afplay
+type fileSink struct {
+	command string
+	args    func(path string) []string
+}
+
+func (s *fileSink) Play(ctx context.Context, pcm *PCM) error {
+	f, err := os.CreateTemp("", "tomatick-*.wav")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(pcm.WAV())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if out, err := exec.CommandContext(ctx, s.command, s.args(f.Name())...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", s.command, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Silent discards the audio; tests use it to see what would have played
+type Silent struct {
+	mu     sync.Mutex
+	played []*PCM
+}
+
+func (s *Silent) Play(ctx context.Context, pcm *PCM) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.played = append(s.played, pcm)
+	return nil
+}
+
+// Played returns the audio played so far
+func (s *Silent) Played() []*PCM {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*PCM(nil), s.played...)
+}
@@ -0,0 +1,457 @@
+package context
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	frontMatterDelimiter = "---"
+	clockLayout          = "15:04"
+	dateLayout           = "2006-01-02"
+)
+
+// timelinePattern matches concrete time ranges such as "14:00 - 14:40" in a context body
+var timelinePattern = regexp.MustCompile(`\b([01]?\d|2[0-3]):[0-5]\d\s*(-|–|to)\s*([01]?\d|2[0-3]):[0-5]\d\b`)
+
+// Context is a structured session context. It is stored on disk as markdown
+// with a small front matter block; legacy .txt contexts only carry a Body.
+type Context struct {
+	Project     string
+	Goals       []string
+	Schedule    []ScheduleBlock
+	Constraints []string
+	Expires     time.Time
+	Tags        []string
+	Created     time.Time
+	Refined     time.Time
+	Body        string
+}
+
+// ScheduleBlock is a time-of-day slot from the context's schedule
+type ScheduleBlock struct {
+	Start    time.Duration // offset from midnight
+	End      time.Duration // offset from midnight
+	Activity string
+	Location string
+}
+
+// Section is a level-two markdown heading and the text beneath it
+type Section struct {
+	Title   string
+	Content string
+}
+
+// Parse reads a context document. Content without front matter is treated as
+// a legacy plain-text context and kept verbatim in Body.
+func Parse(content string) (*Context, error) {
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, frontMatterDelimiter+"\n") {
+		return &Context{Body: strings.TrimSpace(content)}, nil
+	}
+
+	rest := strings.TrimPrefix(normalized, frontMatterDelimiter+"\n")
+	block, body, found := cutFrontMatter(rest)
+	if !found {
+		return nil, fmt.Errorf("unterminated front matter")
+	}
+
+	ctx := &Context{}
+	if err := ctx.parseFrontMatter(block); err != nil {
+		return nil, err
+	}
+
+	ctx.Body = strings.TrimSpace(body)
+	return ctx, nil
+}
+
+// cutFrontMatter splits rest at the first line that is exactly the closing
+// delimiter, which may be the very first line of empty front matter
+func cutFrontMatter(rest string) (block, body string, found bool) {
+	for offset := 0; offset <= len(rest); {
+		line, next := rest[offset:], len(rest)
+		if i := strings.IndexByte(line, '\n'); i >= 0 {
+			line, next = line[:i], offset+i+1
+		}
+		if strings.TrimRight(line, " \t") == frontMatterDelimiter {
+			return strings.TrimSuffix(rest[:offset], "\n"), rest[next:], true
+		}
+		if next == len(rest) {
+			break
+		}
+		offset = next
+	}
+	return "", "", false
+}
+
+func (c *Context) parseFrontMatter(block string) error {
+	var listKey string
+
+	for i, raw := range strings.Split(block, "\n") {
+		line := strings.TrimRight(raw, " \t")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "- ") {
+			if listKey == "" {
+				return fmt.Errorf("front matter line %d: list item without a key", i+1)
+			}
+			if err := c.setValue(listKey, strings.TrimSpace(strings.TrimPrefix(trimmed, "- "))); err != nil {
+				return fmt.Errorf("front matter line %d: %w", i+1, err)
+			}
+			continue
+		}
+
+		key, value, found := strings.Cut(trimmed, ":")
+		if !found {
+			return fmt.Errorf("front matter line %d: expected 'key: value'", i+1)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if value == "" {
+			listKey = key
+			continue
+		}
+		listKey = ""
+
+		values := []string{value}
+		if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+			values = splitList(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"))
+		}
+		for _, v := range values {
+			if err := c.setValue(key, v); err != nil {
+				return fmt.Errorf("front matter line %d: %w", i+1, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Context) setValue(key, value string) error {
+	value = strings.Trim(value, `"'`)
+
+	switch key {
+	case "project":
+		c.Project = value
+	case "goals", "goal":
+		c.Goals = append(c.Goals, value)
+	case "constraints", "constraint":
+		c.Constraints = append(c.Constraints, value)
+	case "tags", "tag":
+		c.Tags = append(c.Tags, splitList(value)...)
+	case "schedule":
+		block, err := ParseScheduleBlock(value)
+		if err != nil {
+			return err
+		}
+		c.Schedule = append(c.Schedule, block)
+	case "created", "refined":
+		stamp, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("invalid %s timestamp %q (expected RFC 3339)", key, value)
+		}
+		if key == "created" {
+			c.Created = stamp
+		} else {
+			c.Refined = stamp
+		}
+	case "expires":
+		expires, err := time.ParseInLocation(dateLayout, value, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid expires date %q (expected YYYY-MM-DD)", value)
+		}
+		c.Expires = expires
+	default:
+		return fmt.Errorf("unknown key %q", key)
+	}
+
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if trimmed := strings.Trim(strings.TrimSpace(item), `"'`); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+	return items
+}
+
+// ParseScheduleBlock parses "HH:MM-HH:MM Activity @ Location", the location being optional
+func ParseScheduleBlock(value string) (ScheduleBlock, error) {
+	span, activity, _ := strings.Cut(strings.TrimSpace(value), " ")
+	from, to, found := strings.Cut(span, "-")
+	if !found {
+		return ScheduleBlock{}, fmt.Errorf("invalid schedule block %q (expected 'HH:MM-HH:MM activity')", value)
+	}
+
+	start, err := parseClock(from)
+	if err != nil {
+		return ScheduleBlock{}, fmt.Errorf("invalid schedule block %q: %w", value, err)
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return ScheduleBlock{}, fmt.Errorf("invalid schedule block %q: %w", value, err)
+	}
+	if end <= start {
+		return ScheduleBlock{}, fmt.Errorf("invalid schedule block %q: end must be after start", value)
+	}
+
+	block := ScheduleBlock{Start: start, End: end, Activity: strings.TrimSpace(activity)}
+	if act, loc, found := strings.Cut(block.Activity, "@"); found {
+		block.Activity = strings.TrimSpace(act)
+		block.Location = strings.TrimSpace(loc)
+	}
+	if block.Activity == "" {
+		return ScheduleBlock{}, fmt.Errorf("invalid schedule block %q: missing activity", value)
+	}
+
+	return block, nil
+}
+
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse(clockLayout, strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (expected HH:MM)", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func formatClock(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60)
+}
+
+// String renders the block the same way it is written in front matter
+func (b ScheduleBlock) String() string {
+	s := fmt.Sprintf("%s-%s %s", formatClock(b.Start), formatClock(b.End), b.Activity)
+	if b.Location != "" {
+		s += " @ " + b.Location
+	}
+	return s
+}
+
+// Contains reports whether the time of day of t falls within the block
+func (b ScheduleBlock) Contains(t time.Time) bool {
+	offset := sinceMidnight(t)
+	return offset >= b.Start && offset < b.End
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+// IsStructured reports whether the context carries any front matter metadata
+func (c *Context) IsStructured() bool {
+	return c.Project != "" || len(c.Goals) > 0 || len(c.Schedule) > 0 ||
+		len(c.Constraints) > 0 || !c.Expires.IsZero() || len(c.Tags) > 0
+}
+
+// hasFrontMatter reports whether the context needs a front matter block on disk
+func (c *Context) hasFrontMatter() bool {
+	return c.IsStructured() || !c.Created.IsZero() || !c.Refined.IsZero()
+}
+
+// LastUpdated returns when the context was last written or refined
+func (c *Context) LastUpdated() time.Time {
+	if c.Refined.After(c.Created) {
+		return c.Refined
+	}
+	return c.Created
+}
+
+// HasTimeline reports whether the body plans work against concrete clock
+// times, such as the execution timeline produced by refinement. Schedule
+// blocks are not counted; they describe the user's usual day.
+func (c *Context) HasTimeline() bool {
+	return strings.Contains(strings.ToUpper(c.Body), "EXECUTION TIMELINE") ||
+		timelinePattern.MatchString(c.Body)
+}
+
+// IsStale reports whether a time-bound context was planned on an earlier day
+// or longer than maxAge ago, so its timeline no longer matches the clock
+func (c *Context) IsStale(now time.Time, maxAge time.Duration) bool {
+	updated := c.LastUpdated()
+	if updated.IsZero() || !c.HasTimeline() {
+		return false
+	}
+
+	y1, m1, d1 := updated.In(now.Location()).Date()
+	y2, m2, d2 := now.Date()
+	differentDay := y1 != y2 || m1 != m2 || d1 != d2
+
+	return differentDay || now.Sub(updated) > maxAge
+}
+
+// Expired reports whether the context's expiry date has passed
+func (c *Context) Expired(now time.Time) bool {
+	if c.Expires.IsZero() {
+		return false
+	}
+	return !now.Before(c.Expires.AddDate(0, 0, 1))
+}
+
+// ActiveBlock returns the schedule block covering now, if any
+func (c *Context) ActiveBlock(now time.Time) *ScheduleBlock {
+	for i := range c.Schedule {
+		if c.Schedule[i].Contains(now) {
+			return &c.Schedule[i]
+		}
+	}
+	return nil
+}
+
+// Sections splits the body into its level-two markdown sections. Text before
+// the first heading is returned as an untitled section.
+func (c *Context) Sections() []Section {
+	var sections []Section
+	current := Section{}
+	var lines []string
+
+	flush := func() {
+		current.Content = strings.TrimSpace(strings.Join(lines, "\n"))
+		if current.Title != "" || current.Content != "" {
+			sections = append(sections, current)
+		}
+		lines = nil
+	}
+
+	for _, line := range strings.Split(c.Body, "\n") {
+		if strings.HasPrefix(line, "## ") {
+			flush()
+			current = Section{Title: strings.TrimSpace(strings.TrimPrefix(line, "## "))}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	return sections
+}
+
+// AppendSection adds a titled section to the end of the body
+func (c *Context) AppendSection(title, content string) {
+	section := fmt.Sprintf("## %s\n%s", title, strings.TrimSpace(content))
+	if strings.TrimSpace(c.Body) == "" {
+		c.Body = section
+		return
+	}
+	c.Body = strings.TrimSpace(c.Body) + "\n\n" + section
+}
+
+// Markdown serialises the context back into its on-disk format
+func (c *Context) Markdown() string {
+	if !c.hasFrontMatter() {
+		return c.Body + "\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(frontMatterDelimiter + "\n")
+	if !c.Created.IsZero() {
+		sb.WriteString(fmt.Sprintf("created: %s\n", c.Created.Format(time.RFC3339)))
+	}
+	if !c.Refined.IsZero() {
+		sb.WriteString(fmt.Sprintf("refined: %s\n", c.Refined.Format(time.RFC3339)))
+	}
+	if c.Project != "" {
+		sb.WriteString(fmt.Sprintf("project: %s\n", c.Project))
+	}
+	writeList(&sb, "goals", c.Goals)
+	if len(c.Schedule) > 0 {
+		sb.WriteString("schedule:\n")
+		for _, block := range c.Schedule {
+			sb.WriteString(fmt.Sprintf("  - %s\n", block))
+		}
+	}
+	writeList(&sb, "constraints", c.Constraints)
+	if !c.Expires.IsZero() {
+		sb.WriteString(fmt.Sprintf("expires: %s\n", c.Expires.Format(dateLayout)))
+	}
+	if len(c.Tags) > 0 {
+		sb.WriteString(fmt.Sprintf("tags: [%s]\n", strings.Join(c.Tags, ", ")))
+	}
+	sb.WriteString(frontMatterDelimiter + "\n\n")
+	sb.WriteString(c.Body)
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+func writeList(sb *strings.Builder, key string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	sb.WriteString(key + ":\n")
+	for _, item := range items {
+		sb.WriteString(fmt.Sprintf("  - %s\n", item))
+	}
+}
+
+// String renders the context as prompt text for the copilot
+func (c *Context) String() string {
+	if !c.IsStructured() {
+		return c.Body
+	}
+
+	var sb strings.Builder
+	if c.Project != "" {
+		sb.WriteString(fmt.Sprintf("PROJECT: %s\n\n", c.Project))
+	}
+	writePromptList(&sb, "GOALS", c.Goals)
+	if len(c.Schedule) > 0 {
+		sb.WriteString("SCHEDULE:\n")
+		for _, block := range c.Schedule {
+			sb.WriteString(fmt.Sprintf("- %s\n", block))
+		}
+		sb.WriteString("\n")
+	}
+	writePromptList(&sb, "CONSTRAINTS", c.Constraints)
+	if !c.Expires.IsZero() {
+		sb.WriteString(fmt.Sprintf("VALID UNTIL: %s\n\n", c.Expires.Format(dateLayout)))
+	}
+	if len(c.Tags) > 0 {
+		sb.WriteString(fmt.Sprintf("TAGS: %s\n\n", strings.Join(c.Tags, ", ")))
+	}
+	sb.WriteString(c.Body)
+
+	return strings.TrimSpace(sb.String())
+}
+
+func writePromptList(sb *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	sb.WriteString(title + ":\n")
+	for _, item := range items {
+		sb.WriteString(fmt.Sprintf("- %s\n", item))
+	}
+	sb.WriteString("\n")
+}
+
+// ScheduleSummary describes the schedule relative to now, for the suggestion
+// prompt's schedule enforcement step. It is empty when there is no schedule.
+func (c *Context) ScheduleSummary(now time.Time) string {
+	if len(c.Schedule) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, block := range c.Schedule {
+		sb.WriteString(fmt.Sprintf("- %s\n", block))
+	}
+
+	if active := c.ActiveBlock(now); active != nil {
+		remaining := active.End - sinceMidnight(now)
+		sb.WriteString(fmt.Sprintf("\nACTIVE BLOCK: %s (%d minutes remaining)", active, int(remaining.Minutes())))
+	} else {
+		sb.WriteString("\nACTIVE BLOCK: none - current time falls outside every scheduled block")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,173 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseFrontMatterDelimiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		project string
+		body    string
+	}{
+		{name: "front matter and body", content: "---\nproject: Tomatick\n---\nShip the release", project: "Tomatick", body: "Ship the release"},
+		{name: "empty front matter", content: "---\n---\nJust a body", body: "Just a body"},
+		{name: "empty front matter at end of file", content: "---\n---", body: ""},
+		{name: "closing delimiter at end of file", content: "---\nproject: Tomatick\n---", project: "Tomatick"},
+		{name: "CRLF line endings", content: "---\r\nproject: Tomatick\r\n---\r\nBody", project: "Tomatick", body: "Body"},
+		{name: "horizontal rule in body", content: "---\nproject: Tomatick\n---\nAbove\n\n----\n\nBelow", project: "Tomatick", body: "Above\n\n----\n\nBelow"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := Parse(tt.content)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if ctx.Project != tt.project || ctx.Body != tt.body {
+				t.Errorf("Parse = project %q, body %q; want %q, %q", ctx.Project, ctx.Body, tt.project, tt.body)
+			}
+		})
+	}
+}
+
+func TestParseRejectsLookalikeDelimiters(t *testing.T) {
+	for _, content := range []string{
+		"---\nproject: Tomatick\n----\nBody",
+		"---\nproject: Tomatick\n---foo\nBody",
+		"---\nproject: Tomatick\n",
+		"---\n",
+	} {
+		if ctx, err := Parse(content); err == nil {
+			t.Errorf("Parse(%q) = %+v, want unterminated front matter", content, ctx)
+		}
+	}
+}
+
+func TestLastUpdated(t *testing.T) {
+	created := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	refined := created.Add(2 * time.Hour)
+
+	tests := []struct {
+		name string
+		ctx  Context
+		want time.Time
+	}{
+		{name: "no timestamps", ctx: Context{}, want: time.Time{}},
+		{name: "created only", ctx: Context{Created: created}, want: created},
+		{name: "refined after created", ctx: Context{Created: created, Refined: refined}, want: refined},
+		{name: "refined before created", ctx: Context{Created: refined, Refined: created}, want: refined},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ctx.LastUpdated(); !got.Equal(tt.want) {
+				t.Errorf("LastUpdated = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasTimeline(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{name: "execution timeline heading", body: "## Execution Timeline\n- Draft the proposal", want: true},
+		{name: "heading in capitals", body: "EXECUTION TIMELINE:\nDraft first", want: true},
+		{name: "hyphenated range", body: "- 09:00-10:30 Draft the proposal", want: true},
+		{name: "en dash range", body: "- 9:00 – 10:30 Draft the proposal", want: true},
+		{name: "range with to", body: "Deep work 14:00 to 16:00", want: true},
+		{name: "single clock time", body: "Standup at 09:30", want: false},
+		{name: "no times", body: "Ship the release and write the changelog", want: false},
+		{name: "invalid hour", body: "Build 24:00-25:00", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := Context{Body: tt.body}
+			if got := ctx.HasTimeline(); got != tt.want {
+				t.Errorf("HasTimeline(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsStale(t *testing.T) {
+	const maxAge = 6 * time.Hour
+	afternoon := time.Date(2026, 3, 2, 17, 0, 0, 0, time.Local)
+	pastMidnight := time.Date(2026, 3, 2, 0, 30, 0, 0, time.Local)
+	timeline := "## Execution Timeline\n- 09:00-10:30 Draft the proposal"
+
+	tests := []struct {
+		name    string
+		body    string
+		updated time.Time
+		now     time.Time
+		want    bool
+	}{
+		{name: "just planned", body: timeline, updated: afternoon.Add(-time.Minute), now: afternoon, want: false},
+		{name: "exactly maxAge old", body: timeline, updated: afternoon.Add(-maxAge), now: afternoon, want: false},
+		{name: "just over maxAge", body: timeline, updated: afternoon.Add(-maxAge - time.Second), now: afternoon, want: true},
+		{name: "an hour ago, before midnight", body: timeline, updated: pastMidnight.Add(-time.Hour), now: pastMidnight, want: true},
+		{name: "same day stored in UTC", body: timeline, updated: afternoon.Add(-time.Hour).UTC(), now: afternoon, want: false},
+		{name: "old without a timeline", body: "Ship the release", updated: afternoon.AddDate(0, 0, -3), now: afternoon, want: false},
+		{name: "undated", body: timeline, now: afternoon, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := Context{Body: tt.body, Created: tt.updated}
+			if got := ctx.IsStale(tt.now, maxAge); got != tt.want {
+				t.Errorf("IsStale = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadContextFileDating(t *testing.T) {
+	created := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	modified := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    time.Time
+	}{
+		{name: "front matter timestamp", file: "plan.md",
+			content: "---\nproject: Tomatick\ncreated: 2026-03-02T09:00:00Z\n---\nShip it", want: created},
+		{name: "markdown without timestamps", file: "plan.md", content: "Ship it", want: modified},
+		{name: "legacy text", file: "plan.txt", content: "Ship it\n09:00-10:00 Release", want: modified},
+		// Legacy files are kept verbatim, so front matter in them isn't read
+		{name: "legacy text with front matter", file: "plan.txt",
+			content: "---\ncreated: 2026-03-02T09:00:00Z\n---\nShip it", want: modified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(path, modified, modified); err != nil {
+				t.Fatal(err)
+			}
+
+			cm := &ContextManager{contextDir: dir}
+			ctx, err := cm.loadContextFile(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ctx.LastUpdated(); !got.Equal(tt.want) {
+				t.Errorf("LastUpdated = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	gitRecentCommits = 10
+	gitMaxTodoHits   = 20
+	gitMaxScanBytes  = 1 << 20 // skip files larger than 1MB when scanning for TODOs
+)
+
+var todoPattern = regexp.MustCompile(`\b(TODO|FIXME)\b`)
+
+// GitSnapshot is the local state of a git repository, read without touching any remote
+type GitSnapshot struct {
+	Root          string
+	Branch        string
+	RecentCommits []string
+	DiffStat      string
+	ChangedFiles  []string
+	TodoHits      []TodoHit
+}
+
+// TodoHit is a TODO or FIXME comment found in a changed file
+type TodoHit struct {
+	File string
+	Line int
+	Text string
+}
+
+// ReadGitSnapshot collects the branch, recent commits, uncommitted changes and
+// TODO/FIXME comments in changed files of the repository containing path
+func ReadGitSnapshot(path string) (*GitSnapshot, error) {
+	root, err := runGit(path, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not inside a git repository: %w", path, err)
+	}
+
+	snapshot := &GitSnapshot{Root: root}
+
+	if snapshot.Branch, err = runGit(root, "rev-parse", "--abbrev-ref", "HEAD"); err != nil {
+		// A fresh repository has no HEAD yet
+		snapshot.Branch, _ = runGit(root, "symbolic-ref", "--short", "HEAD")
+	}
+
+	if log, err := runGit(root, "log", fmt.Sprintf("-n%d", gitRecentCommits), "--pretty=format:%h %s (%cr)"); err == nil && log != "" {
+		snapshot.RecentCommits = strings.Split(log, "\n")
+	}
+
+	snapshot.DiffStat, _ = runGit(root, "diff", "HEAD", "--stat")
+
+	status, err := runGit(root, "status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git status: %w", err)
+	}
+	snapshot.ChangedFiles = parseStatusZ(status)
+
+	snapshot.TodoHits = scanTodos(root, snapshot.ChangedFiles)
+
+	return snapshot, nil
+}
+
+// parseStatusZ returns the changed files from git status --porcelain -z.
+// Paths are NUL-terminated and never quoted; a rename or copy entry is
+// followed by the original path, which is skipped.
+func parseStatusZ(status string) []string {
+	var files []string
+	entries := strings.Split(status, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		files = append(files, entry[3:])
+		if entry[0] == 'R' || entry[0] == 'C' || entry[1] == 'R' || entry[1] == 'C' {
+			i++
+		}
+	}
+	return files
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", err
+	}
+
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func scanTodos(root string, files []string) []TodoHit {
+	var hits []TodoHit
+
+	for _, file := range files {
+		path := filepath.Join(root, file)
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || info.Size() > gitMaxScanBytes {
+			continue
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(f)
+		lineNo := 0
+		for scanner.Scan() {
+			lineNo++
+			line := scanner.Text()
+			if strings.ContainsRune(line, 0) {
+				// Binary file
+				break
+			}
+			if todoPattern.MatchString(line) {
+				hits = append(hits, TodoHit{File: file, Line: lineNo, Text: strings.TrimSpace(line)})
+				if len(hits) >= gitMaxTodoHits {
+					f.Close()
+					return hits
+				}
+			}
+		}
+		f.Close()
+	}
+
+	return hits
+}
+
+// Section renders the snapshot as a markdown context section body
+func (s *GitSnapshot) Section() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Repository: %s\n", s.Root))
+	sb.WriteString(fmt.Sprintf("Current branch: %s\n", s.Branch))
+
+	if len(s.RecentCommits) > 0 {
+		sb.WriteString("\nRecent commits:\n")
+		for _, commit := range s.RecentCommits {
+			sb.WriteString(fmt.Sprintf("- %s\n", commit))
+		}
+	}
+
+	if len(s.ChangedFiles) == 0 {
+		sb.WriteString("\nWorking tree is clean.\n")
+	} else {
+		sb.WriteString("\nUncommitted changes:\n")
+		if s.DiffStat != "" {
+			sb.WriteString(s.DiffStat + "\n")
+		}
+		for _, file := range s.ChangedFiles {
+			sb.WriteString(fmt.Sprintf("- %s\n", file))
+		}
+	}
+
+	if len(s.TodoHits) > 0 {
+		sb.WriteString("\nOpen TODO/FIXME in changed files:\n")
+		for _, hit := range s.TodoHits {
+			sb.WriteString(fmt.Sprintf("- %s:%d %s\n", hit.File, hit.Line, hit.Text))
+		}
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// Context turns the snapshot into a session context
+func (s *GitSnapshot) Context() *Context {
+	ctx := &Context{
+		Project: filepath.Base(s.Root),
+		Tags:    []string{"git"},
+	}
+	ctx.AppendSection("Repository State", s.Section())
+	return ctx
+}
@@ -0,0 +1,51 @@
+package context
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseStatusZ(t *testing.T) {
+	status := " M main.go\x00R  new name.go\x00old name.go\x00?? café/\"quoted\".go\x00A  docs/a -> b.md\x00"
+	want := []string{"main.go", "new name.go", "café/\"quoted\".go", "docs/a -> b.md"}
+	if got := parseStatusZ(status); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseStatusZ = %q, want %q", got, want)
+	}
+	if got := parseStatusZ(""); len(got) != 0 {
+		t.Errorf("parseStatusZ of a clean tree = %q", got)
+	}
+}
+
+func TestReadGitSnapshotUnusualPaths(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	name := "café \"notes\".go"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("package x\n// TODO: accents\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := ReadGitSnapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.ChangedFiles) != 1 || snapshot.ChangedFiles[0] != name {
+		t.Fatalf("changed files = %q, want %q", snapshot.ChangedFiles, name)
+	}
+	if len(snapshot.TodoHits) != 1 || snapshot.TodoHits[0].Line != 2 {
+		t.Errorf("TODO hits = %+v, want the TODO on line 2", snapshot.TodoHits)
+	}
+}
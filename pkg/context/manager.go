@@ -12,11 +12,47 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/logrusorgru/aurora"
 
+	"github.com/1x-eng/tomatick/pkg/editor"
 	"github.com/1x-eng/tomatick/pkg/llm"
 	"github.com/1x-eng/tomatick/pkg/ui"
 	"github.com/1x-eng/tomatick/pkg/webhook"
 )
 
+// contextStaleAfter is how old a time-bound context can get, on the same day,
+// before its timeline is considered out of date
+const contextStaleAfter = 6 * time.Hour
+
+// previousSessionFile holds the context the most recent session ran with.
+// It is hidden so it doesn't show up among the saved contexts.
+const previousSessionFile = ".previous_session.md"
+
+const (
+	optionLoadContext     = "Load an existing context"
+	optionTemplateContext = "Build a context from a template"
+	optionPreviousContext = "Start from the previous session's context"
+	optionGitContext      = "Import from a git repository"
+	optionNewContext      = "Write a new context"
+)
+
+const contextEditorTemplate = `<!--
+Describe this session's context, then save and close the editor.
+
+- What are you working on?
+- What are your main objectives?
+- Any specific challenges to address?
+- Expected outcomes or deliverables?
+
+Optionally start with front matter (project, goals, schedule, constraints,
+expires, tags) between two '---' lines. This comment is removed.
+-->
+`
+
+const deltaEditorTemplate = `<!--
+Add context for this session only, then save and close the editor.
+Leave it empty to continue with the loaded context. This comment is removed.
+-->
+`
+
 type ContextManager struct {
 	contextDir         string
 	au                 aurora.Aurora
@@ -24,56 +60,98 @@ type ContextManager struct {
 	currentContextFile string
 	llmClient          *llm.PerplexityAI
 	dispatcher         webhook.Dispatcher
+	useEditor          bool
 }
 
-func NewContextManager(contextDir string, au aurora.Aurora, theme *ui.Theme, llmClient *llm.PerplexityAI, dispatcher webhook.Dispatcher) *ContextManager {
+func NewContextManager(contextDir string, au aurora.Aurora, theme *ui.Theme, llmClient *llm.PerplexityAI, dispatcher webhook.Dispatcher, useEditor bool) *ContextManager {
 	return &ContextManager{
 		contextDir: contextDir,
 		au:         au,
 		presenter:  ui.NewContextPresenter(theme),
 		llmClient:  llmClient,
 		dispatcher: dispatcher,
+		useEditor:  useEditor,
 	}
 }
 
-func (cm *ContextManager) GetSessionContext(llmClient *llm.PerplexityAI) (string, error) {
+func (cm *ContextManager) GetSessionContext(llmClient *llm.PerplexityAI) (*Context, error) {
 	// Display the context menu
 	fmt.Print(cm.presenter.PresentContextMenu())
 
 	// Add a separator
 	fmt.Println()
 
-	var useContextFile bool
-	prompt := &survey.Confirm{
-		Message: cm.au.BrightBlue("Would you like to load an existing context?").String(),
+	options := []string{optionLoadContext, optionTemplateContext}
+	if cm.hasPreviousSession() {
+		options = append(options, optionPreviousContext)
+	}
+	options = append(options, optionGitContext, optionNewContext)
+
+	var choice string
+	prompt := &survey.Select{
+		Message: cm.au.BrightBlue("How would you like to set up this session's context?").String(),
+		Options: options,
 	}
-	survey.AskOne(prompt, &useContextFile)
+	survey.AskOne(prompt, &choice)
 
-	var context string
+	var context *Context
 	var err error
+	var source string
 
-	if useContextFile {
+	switch choice {
+	case optionLoadContext:
+		source = webhook.ContextFromFile
 		context, err = cm.getContextFromFile()
-	} else {
+	case optionTemplateContext:
+		source = webhook.ContextFromTemplate
+		context, err = cm.getContextFromTemplate()
+	case optionPreviousContext:
+		source = webhook.ContextFromPrevious
+		context, err = cm.getContextFromPreviousSession()
+	case optionGitContext:
+		source = webhook.ContextFromGit
+		var repoPath string
+		survey.AskOne(&survey.Input{Message: "Path to the repository:", Default: "."}, &repoPath)
+		context, err = cm.ImportFromGit(repoPath)
+	default:
+		source = webhook.ContextFromInput
 		context, err = cm.getContextFromInput()
 	}
 
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	cm.dispatchLoaded(source, context)
+
+	if err := cm.rememberSession(context); err != nil {
+		fmt.Println(cm.au.Yellow("Could not remember this context for next session:"), err)
 	}
 
 	return context, nil
 }
 
-func (cm *ContextManager) getContextFromFile() (string, error) {
+// dispatchLoaded announces the context the session runs with
+func (cm *ContextManager) dispatchLoaded(source string, context *Context) {
+	cm.dispatcher.Dispatch(webhook.ContextLoaded{
+		Source:      source,
+		Structured:  context.IsStructured(),
+		GoalsCount:  len(context.Goals),
+		Tags:        context.Tags,
+		HasSchedule: len(context.Schedule) > 0,
+		Project:     context.Project,
+	})
+}
+
+func (cm *ContextManager) getContextFromFile() (*Context, error) {
 	files, err := os.ReadDir(cm.contextDir)
 	if err != nil {
-		return "", fmt.Errorf("failed to read context directory: %w", err)
+		return nil, fmt.Errorf("failed to read context directory: %w", err)
 	}
 
 	var options []string
 	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".txt") {
+		if !file.IsDir() && isContextFile(file.Name()) && !strings.HasPrefix(file.Name(), ".") {
 			options = append(options, file.Name())
 		}
 	}
@@ -93,9 +171,107 @@ func (cm *ContextManager) getContextFromFile() (string, error) {
 	survey.AskOne(prompt, &selected)
 	cm.currentContextFile = selected
 
-	content, err := os.ReadFile(filepath.Join(cm.contextDir, selected))
+	context, err := cm.loadContextFile(selected)
+	if err != nil {
+		return nil, err
+	}
+
+	return cm.extendContext(context, selected)
+}
+
+// offerReanchor warns that a context's timeline was planned for an earlier
+// time and offers to have the copilot shift it to start from now
+func (cm *ContextManager) offerReanchor(context *Context, filename string) *Context {
+	planned := context.LastUpdated()
+	now := time.Now()
+
+	fmt.Println(cm.au.BrightYellow(fmt.Sprintf("\n⚠ This context's timeline was planned on %s (%s ago); its times no longer match the clock.",
+		planned.Format("Mon 02 Jan 15:04"), now.Sub(planned).Round(time.Minute))))
+
+	reanchor := true
+	prompt := &survey.Confirm{
+		Message: cm.au.BrightBlue(fmt.Sprintf("Re-anchor the timeline to start from now (%s)?", now.Format("15:04"))).String(),
+		Default: true,
+	}
+	survey.AskOne(prompt, &reanchor)
+
+	if !reanchor {
+		return context
+	}
+
+	var spinner = ui.NewSpinner(cm.presenter.GetTheme().Styles.Spinner.
+		Foreground(lipgloss.Color("#818CF8")).
+		Bold(true))
+	done := make(chan bool)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				fmt.Printf("\r%s Re-anchoring your timeline...", spinner.Next())
+				time.Sleep(100 * time.Millisecond)
+			}
+		}
+	}()
+
+	body, err := llm.ReanchorTimeline(cm.llmClient, context.Body, planned, now)
+	close(done)
+	fmt.Println() // Clear spinner line
+
+	if err != nil {
+		fmt.Printf("\n%s Error re-anchoring timeline: %v\n", cm.au.Red("✗"), err)
+		fmt.Println(cm.au.Yellow("Proceeding with the original timeline."))
+		return context
+	}
+
+	reanchored := *context
+	reanchored.Body = body
+	reanchored.Refined = now
+
+	fmt.Println("\n" + cm.au.BrightCyan("Re-anchored Context:").Bold().String())
+	fmt.Println(reanchored.String() + "\n")
+
+	if filename != "" {
+		var save bool
+		savePrompt := &survey.Confirm{
+			Message: fmt.Sprintf("Save the re-anchored timeline to %s?", filename),
+		}
+		survey.AskOne(savePrompt, &save)
+
+		if save {
+			if err := cm.writeContextFile(filename, &reanchored); err != nil {
+				fmt.Println(cm.au.Red("Failed to save context:"), err)
+			}
+		}
+	}
+
+	return &reanchored
+}
+
+// getContextFromPreviousSession starts from the context the last session ran with
+func (cm *ContextManager) getContextFromPreviousSession() (*Context, error) {
+	context, err := cm.loadContextFile(previousSessionFile)
 	if err != nil {
-		return "", fmt.Errorf("failed to read context file: %w", err)
+		return nil, err
+	}
+
+	fmt.Println("\n" + cm.au.BrightCyan("Previous Session Context:").Bold().String())
+	fmt.Println(context.String() + "\n")
+
+	return cm.extendContext(context, "")
+}
+
+// extendContext offers to add session-specific context on top of a loaded one.
+// filename is the saved file the context came from, or empty if there is none.
+func (cm *ContextManager) extendContext(context *Context, filename string) (*Context, error) {
+	if context.IsStale(time.Now(), contextStaleAfter) {
+		context = cm.offerReanchor(context, filename)
+	}
+
+	if context.Expired(time.Now()) {
+		fmt.Println(cm.au.BrightYellow(fmt.Sprintf("Heads up: this context expired on %s. Consider refreshing it.",
+			context.Expires.Format(dateLayout))))
 	}
 
 	// Ask if user wants to add additional context
@@ -106,54 +282,55 @@ func (cm *ContextManager) getContextFromFile() (string, error) {
 	survey.AskOne(deltaPrompt, &addDelta)
 
 	if !addDelta {
-		return string(content), nil
+		return context, nil
 	}
 
 	// Get delta context
-	fmt.Print(cm.presenter.PresentDeltaContextInput())
+	fmt.Print(cm.presenter.PresentDeltaContextInput(cm.useEditor))
 
-	var lines []string
-	scanner := bufio.NewScanner(os.Stdin)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "done" {
-			break
-		}
-		lines = append(lines, line)
-	}
-
-	deltaContext := strings.Join(lines, "\n")
+	deltaContext := cm.readText(deltaEditorTemplate)
 
 	if deltaContext == "" {
 		fmt.Println(cm.au.BrightYellow("No additional context provided. Proceeding with original context."))
-		return string(content), nil
+		return context, nil
 	}
 
 	// Ask if delta should be appended to saved context
 	var saveDelta bool
-	savePrompt := &survey.Confirm{
-		Message: "Would you like to append this additional context to the saved context file? Otherwise, the delta you've provided will be ephemeral and only available for this session.",
+	if filename != "" {
+		savePrompt := &survey.Confirm{
+			Message: "Would you like to append this additional context to the saved context file? Otherwise, the delta you've provided will be ephemeral and only available for this session.",
+		}
+		survey.AskOne(savePrompt, &saveDelta)
 	}
-	survey.AskOne(savePrompt, &saveDelta)
 
 	if saveDelta {
 		// Append to existing file
-		updatedContent := string(content) + "\n\n=== Additional Context ===\n" + deltaContext
-		if err := os.WriteFile(filepath.Join(cm.contextDir, selected), []byte(updatedContent), 0644); err != nil {
+		updated := *context
+		if isLegacyContextFile(filename) {
+			updated.Body = context.Body + "\n\n=== Additional Context ===\n" + deltaContext
+		} else {
+			updated.AppendSection("Additional Context", deltaContext)
+		}
+		if err := cm.writeContextFile(filename, &updated); err != nil {
 			fmt.Println(cm.au.Red("Failed to update context file:"), err)
 			// Continue with session even if save fails
 		}
-		return updatedContent, nil
+		return &updated, nil
 	}
 
-	enrichedContext := string(content) + "\n\n=== Session Context ===\n" + deltaContext
+	enrichedContext := *context
+	if isLegacyContextFile(filename) {
+		enrichedContext.Body = context.Body + "\n\n=== Session Context ===\n" + deltaContext
+	} else {
+		enrichedContext.AppendSection("Session Context", deltaContext)
+	}
 
 	// Refine enriched context with copilot - if user wants to
-	refinedContext, err := cm.RefineContext(enrichedContext, cm.llmClient)
+	refinedContext, err := cm.RefineContext(&enrichedContext, cm.llmClient)
 	if err != nil {
 		fmt.Println(cm.au.Red("\nError during context refinement. Proceeding with original context."))
-		refinedContext = enrichedContext
+		refinedContext = &enrichedContext
 	}
 
 	var saveRefinedContext bool
@@ -171,21 +348,106 @@ func (cm *ContextManager) getContextFromFile() (string, error) {
 	return refinedContext, nil
 }
 
-func (cm *ContextManager) getContextFromInput() (string, error) {
-	fmt.Print(cm.presenter.PresentContextInput())
+func (cm *ContextManager) getContextFromInput() (*Context, error) {
+	fmt.Print(cm.presenter.PresentContextInput(cm.useEditor))
 
-	var lines []string
-	scanner := bufio.NewScanner(os.Stdin)
+	text := cm.readText(contextEditorTemplate)
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "done" {
-			break
+	context, err := Parse(text)
+	if err != nil {
+		fmt.Println(cm.au.Yellow(fmt.Sprintf("Could not parse context metadata (%v). Using it as plain text.", err)))
+		context = &Context{Body: text}
+	}
+
+	return cm.refineAndOfferSave(context)
+}
+
+// ImportFromGit builds a context from the local state of the git repository
+// at path, then offers refinement and saving like a hand-written context
+func (cm *ContextManager) ImportFromGit(path string) (*Context, error) {
+	snapshot, err := ReadGitSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+
+	context := snapshot.Context()
+
+	fmt.Println("\n" + cm.au.BrightCyan("Imported Repository Context:").Bold().String())
+	fmt.Println(context.String() + "\n")
+
+	var notes string
+	survey.AskOne(&survey.Multiline{
+		Message: "Anything to add about what you're doing in this repository? (optional)",
+	}, &notes)
+	if strings.TrimSpace(notes) != "" {
+		context.AppendSection("Notes", notes)
+	}
+
+	return cm.refineAndOfferSave(context)
+}
+
+// getContextFromTemplate walks the user through one of the context templates
+func (cm *ContextManager) getContextFromTemplate() (*Context, error) {
+	templates, err := LoadTemplates(cm.contextDir)
+	if err != nil {
+		fmt.Println(cm.au.Yellow("Some user templates could not be loaded:"), err)
+	}
+
+	options := make([]string, len(templates))
+	for i, tmpl := range templates {
+		options[i] = fmt.Sprintf("%s - %s", tmpl.Name, tmpl.Description)
+	}
+
+	var selected int
+	prompt := &survey.Select{
+		Message: "Choose a template:",
+		Options: options,
+	}
+	if err := survey.AskOne(prompt, &selected); err != nil {
+		return nil, fmt.Errorf("template selection cancelled: %w", err)
+	}
+	tmpl := templates[selected]
+
+	fmt.Print(cm.presenter.PresentTemplateStart(tmpl.Name))
+
+	answers := make(map[string]string)
+	for _, field := range tmpl.Fields {
+		field := field
+		var answer string
+		var question survey.Prompt
+		if field.Multiple {
+			question = &survey.Multiline{Message: field.Prompt, Help: field.Help}
+		} else {
+			question = &survey.Input{Message: field.Prompt, Help: field.Help}
 		}
-		lines = append(lines, line)
+
+		validator := func(ans interface{}) error {
+			text, _ := ans.(string)
+			return field.ValidateAnswer(text)
+		}
+		if err := survey.AskOne(question, &answer, survey.WithValidator(validator)); err != nil {
+			return nil, fmt.Errorf("template input cancelled: %w", err)
+		}
+		answers[field.Key] = answer
 	}
 
-	context := strings.Join(lines, "\n")
+	context, err := tmpl.Build(answers)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println("\n" + cm.au.BrightCyan("Your Context:").Bold().String())
+	fmt.Println(context.String() + "\n")
+
+	return cm.refineAndOfferSave(context)
+}
+
+// refineAndOfferSave runs optional refinement on a freshly written context and
+// offers to save the result
+func (cm *ContextManager) refineAndOfferSave(context *Context) (*Context, error) {
+	if context.Created.IsZero() {
+		context.Created = time.Now()
+	}
 
 	refinedContext, err := cm.RefineContext(context, cm.llmClient)
 	if err != nil {
@@ -208,22 +470,99 @@ func (cm *ContextManager) getContextFromInput() (string, error) {
 	return refinedContext, nil
 }
 
-func (cm *ContextManager) saveContext(context string) error {
+// hasPreviousSession reports whether a previous session's context was remembered
+func (cm *ContextManager) hasPreviousSession() bool {
+	_, err := os.Stat(filepath.Join(cm.contextDir, previousSessionFile))
+	return err == nil
+}
+
+// rememberSession keeps the context a session runs with, so the next session can start from it
+func (cm *ContextManager) rememberSession(context *Context) error {
+	return cm.writeContextFile(previousSessionFile, context)
+}
+
+// readText collects multi-line input, from the user's editor when enabled or
+// otherwise from stdin until a line reading 'done'
+func (cm *ContextManager) readText(editorTemplate string) string {
+	if cm.useEditor {
+		text, err := editor.Edit(editorTemplate, "tomatick-context-*.md")
+		if err == nil {
+			return text
+		}
+		fmt.Println(cm.au.Yellow("Could not use your editor, type here instead (type 'done' when finished):"), err)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(os.Stdin)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "done" {
+			break
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (cm *ContextManager) saveContext(context *Context) error {
 	var filename string
 	prompt := &survey.Input{
-		Message: "Enter a name for your context file (will be saved as .txt):",
+		Message: "Enter a name for your context file (will be saved as .md):",
 	}
 	survey.AskOne(prompt, &filename)
 
-	if !strings.HasSuffix(filename, ".txt") {
-		filename += ".txt"
+	if !isContextFile(filename) {
+		filename += ".md"
 	}
 
-	filepath := filepath.Join(cm.contextDir, filename)
-	return os.WriteFile(filepath, []byte(context), 0644)
+	return cm.writeContextFile(filename, context)
+}
+
+// isContextFile reports whether name is a context file tomatick can load
+func isContextFile(name string) bool {
+	return strings.HasSuffix(name, ".md") || isLegacyContextFile(name)
 }
 
-func (cm *ContextManager) RefineContext(context string, llmClient *llm.PerplexityAI) (string, error) {
+// isLegacyContextFile reports whether name uses the plain-text context format
+func isLegacyContextFile(name string) bool {
+	return strings.HasSuffix(name, ".txt")
+}
+
+func (cm *ContextManager) loadContextFile(name string) (*Context, error) {
+	content, err := os.ReadFile(filepath.Join(cm.contextDir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read context file: %w", err)
+	}
+
+	var context *Context
+	if isLegacyContextFile(name) {
+		context = &Context{Body: string(content)}
+	} else if context, err = Parse(string(content)); err != nil {
+		return nil, fmt.Errorf("failed to parse context file %s: %w", name, err)
+	}
+
+	// Files without timestamps (legacy or hand-written) are dated by modification time
+	if context.LastUpdated().IsZero() {
+		if info, err := os.Stat(filepath.Join(cm.contextDir, name)); err == nil {
+			context.Created = info.ModTime()
+		}
+	}
+
+	return context, nil
+}
+
+// writeContextFile persists the context, keeping legacy .txt files as plain text
+func (cm *ContextManager) writeContextFile(name string, context *Context) error {
+	content := context.Markdown()
+	if isLegacyContextFile(name) {
+		content = context.String()
+	}
+	return os.WriteFile(filepath.Join(cm.contextDir, name), []byte(content), 0644)
+}
+
+func (cm *ContextManager) RefineContext(context *Context, llmClient *llm.PerplexityAI) (*Context, error) {
 	fmt.Println(cm.presenter.PresentRefinementOption())
 
 	var useRefinement bool
@@ -240,11 +579,11 @@ func (cm *ContextManager) RefineContext(context string, llmClient *llm.Perplexit
 	chat := llm.NewRefinementChat(llmClient, []llm.Message{
 		{
 			Role:    "user",
-			Content: fmt.Sprintf("\n\n\nCurrent time: %s\n\n========= Context to refine:============\n%s", time.Now().Format("15:04"), context),
+			Content: fmt.Sprintf("\n\n\nCurrent time: %s\n\n========= Context to refine:============\n%s", time.Now().Format("15:04"), context.String()),
 		},
 	})
 
-	var refinedContext string
+	var refinedBody string
 	var err error
 
 	var spinner = ui.NewSpinner(cm.presenter.GetTheme().Styles.Spinner.
@@ -263,23 +602,26 @@ func (cm *ContextManager) RefineContext(context string, llmClient *llm.Perplexit
 		}
 	}()
 
-	refinedContext, err = chat.GetRefinedContext()
+	refinedBody, err = chat.GetRefinedContext()
 	close(done)
 	fmt.Println() // Clear spinner line
 
+	// Metadata survives refinement; only the free-form body is replaced
+	refinedContext := *context
 	if err != nil {
 		fmt.Printf("\n%s Error during context refinement: %v\n", cm.au.Red("✗"), err)
 		fmt.Println(cm.au.Yellow("Proceeding with original context."))
-		refinedContext = context
 	} else {
+		refinedContext.Body = refinedBody
+		refinedContext.Refined = time.Now()
 		fmt.Println("\n" + cm.au.BrightCyan("Proposed Session Blueprint:").Bold().String())
-		fmt.Println(refinedContext + "\n\n")
+		fmt.Println(refinedBody + "\n\n")
 
 		// Dispatch event
-		cm.dispatcher.Dispatch(webhook.EventContextRefined, map[string]string{
-			"original_length": fmt.Sprintf("%d", len(context)),
-			"refined_length":  fmt.Sprintf("%d", len(refinedContext)),
-			"refined_context": refinedContext,
+		cm.dispatcher.Dispatch(webhook.ContextRefined{
+			OriginalLength: len(context.String()),
+			RefinedLength:  len(refinedContext.String()),
+			RefinedContext: refinedContext.String(),
 		})
 	}
 
@@ -296,10 +638,10 @@ func (cm *ContextManager) RefineContext(context string, llmClient *llm.Perplexit
 			// If we loaded from a file, use that filename
 			filename = cm.currentContextFile
 		} else {
-			filename = "copilot_refined_context_" + time.Now().Format("2006-01-02_15-04-05") + ".txt"
+			filename = "copilot_refined_context_" + time.Now().Format("2006-01-02_15-04-05") + ".md"
 		}
 
-		if err := os.WriteFile(filepath.Join(cm.contextDir, filename), []byte(refinedContext), 0644); err != nil {
+		if err := cm.writeContextFile(filename, &refinedContext); err != nil {
 			fmt.Println(cm.au.Red("Failed to save context:"), err)
 			// Continue with session even if save fails
 		} else {
@@ -309,5 +651,5 @@ func (cm *ContextManager) RefineContext(context string, llmClient *llm.Perplexit
 		}
 	}
 
-	return refinedContext, nil
+	return &refinedContext, nil
 }
@@ -0,0 +1,58 @@
+package context
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/1x-eng/tomatick/pkg/webhook"
+)
+
+// recordingDispatcher keeps the events instead of sending them
+type recordingDispatcher struct {
+	events []webhook.Event
+}
+
+func (d *recordingDispatcher) Dispatch(event webhook.Event) { d.events = append(d.events, event) }
+func (d *recordingDispatcher) SessionID() string            { return "test" }
+func (d *recordingDispatcher) SetCycle(int)                 {}
+func (d *recordingDispatcher) Wait()                        {}
+func (d *recordingDispatcher) Shutdown(time.Duration) []webhook.DeadLetter {
+	return nil
+}
+
+func TestDispatchLoaded(t *testing.T) {
+	structured, err := Parse("---\nproject: Tomatick\ngoals: Ship webhooks\ngoals: Write docs\nschedule: 09:00-12:00 Deep work\ntags: go, cli\n---\nFocus on the release")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		source  string
+		context *Context
+		want    webhook.ContextLoaded
+	}{
+		{name: "structured file", source: webhook.ContextFromFile, context: structured,
+			want: webhook.ContextLoaded{Source: webhook.ContextFromFile, Structured: true, GoalsCount: 2,
+				Tags: []string{"go", "cli"}, HasSchedule: true, Project: "Tomatick"}},
+		{name: "typed in", source: webhook.ContextFromInput, context: &Context{Body: "Fix the flaky test"},
+			want: webhook.ContextLoaded{Source: webhook.ContextFromInput}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dispatcher := &recordingDispatcher{}
+			cm := &ContextManager{dispatcher: dispatcher}
+
+			cm.dispatchLoaded(tt.source, tt.context)
+
+			if len(dispatcher.events) != 1 {
+				t.Fatalf("events = %+v, want one context_loaded", dispatcher.events)
+			}
+			if got := dispatcher.events[0]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("event = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// templatesDirName is where user-defined templates live, inside the context directory
+const templatesDirName = "templates"
+
+// TemplateField is a single question asked while building a context.
+// Key is one of the front matter keys (project, goals, schedule, constraints,
+// tags, expires); any other key becomes a section titled after the key.
+type TemplateField struct {
+	Key      string `json:"key"`
+	Prompt   string `json:"prompt"`
+	Help     string `json:"help,omitempty"`
+	Multiple bool   `json:"multiple,omitempty"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// Template describes a guided context builder
+type Template struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Tags        []string        `json:"tags,omitempty"`
+	Fields      []TemplateField `json:"fields"`
+}
+
+var builtinTemplates = []Template{
+	{
+		Name:        "Feature work",
+		Description: "Building or changing a feature in a codebase",
+		Tags:        []string{"feature"},
+		Fields: []TemplateField{
+			{Key: "project", Prompt: "Which project or repository?", Required: true},
+			{Key: "goals", Prompt: "What should be working by the end of today?", Help: "One goal per line", Multiple: true, Required: true},
+			{Key: "Current State", Prompt: "Where did you leave off? (branch, what's done, what's half-done)", Multiple: true},
+			{Key: "Open Questions", Prompt: "Any design decisions or unknowns still open?", Multiple: true},
+			{Key: "schedule", Prompt: "Time blocks for today (HH:MM-HH:MM activity @ location)", Help: "One block per line", Multiple: true},
+			{Key: "constraints", Prompt: "Constraints (reviews, deadlines, meetings, energy)?", Multiple: true},
+		},
+	},
+	{
+		Name:        "Incident follow-up",
+		Description: "Post-incident cleanup, remediation and write-up",
+		Tags:        []string{"incident"},
+		Fields: []TemplateField{
+			{Key: "project", Prompt: "Which service or system was affected?", Required: true},
+			{Key: "Incident Summary", Prompt: "What happened, and what is the current status?", Multiple: true, Required: true},
+			{Key: "goals", Prompt: "Follow-up actions to make progress on", Help: "One action per line", Multiple: true, Required: true},
+			{Key: "Stakeholders", Prompt: "Who needs updates, and by when?", Multiple: true},
+			{Key: "schedule", Prompt: "Time blocks for today (HH:MM-HH:MM activity @ location)", Help: "One block per line", Multiple: true},
+			{Key: "constraints", Prompt: "Constraints (on-call, freeze windows, approvals)?", Multiple: true},
+		},
+	},
+	{
+		Name:        "Study session",
+		Description: "Learning a topic, course or book",
+		Tags:        []string{"study"},
+		Fields: []TemplateField{
+			{Key: "project", Prompt: "What are you studying?", Required: true},
+			{Key: "goals", Prompt: "What should you be able to explain or do afterwards?", Help: "One outcome per line", Multiple: true, Required: true},
+			{Key: "Material", Prompt: "Which chapters, lectures or resources?", Multiple: true},
+			{Key: "Prior Knowledge", Prompt: "What do you already know, and where do you get stuck?", Multiple: true},
+			{Key: "schedule", Prompt: "Time blocks for today (HH:MM-HH:MM activity @ location)", Help: "One block per line", Multiple: true},
+		},
+	},
+	{
+		Name:        "Writing",
+		Description: "Drafting or editing a document, post or paper",
+		Tags:        []string{"writing"},
+		Fields: []TemplateField{
+			{Key: "project", Prompt: "What are you writing?", Required: true},
+			{Key: "Audience", Prompt: "Who is it for, and what should they take away?", Multiple: true, Required: true},
+			{Key: "goals", Prompt: "Targets for today (sections, word count, revisions)", Help: "One target per line", Multiple: true, Required: true},
+			{Key: "Outline", Prompt: "Current outline or notes", Multiple: true},
+			{Key: "schedule", Prompt: "Time blocks for today (HH:MM-HH:MM activity @ location)", Help: "One block per line", Multiple: true},
+			{Key: "expires", Prompt: "Deadline (YYYY-MM-DD)"},
+		},
+	},
+}
+
+// LoadTemplates returns the built-in templates followed by any user-defined
+// templates found in <contextDir>/templates/*.json. A user template with the
+// same name as a built-in replaces it.
+func LoadTemplates(contextDir string) ([]Template, error) {
+	templates := make([]Template, len(builtinTemplates))
+	copy(templates, builtinTemplates)
+
+	dir := filepath.Join(contextDir, templatesDirName)
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return templates, nil
+	}
+	if err != nil {
+		return templates, fmt.Errorf("failed to read templates directory: %w", err)
+	}
+
+	var names []string
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
+			names = append(names, file.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		tmpl, err := loadTemplateFile(filepath.Join(dir, name))
+		if err != nil {
+			return templates, err
+		}
+
+		replaced := false
+		for i := range templates {
+			if strings.EqualFold(templates[i].Name, tmpl.Name) {
+				templates[i] = tmpl
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			templates = append(templates, tmpl)
+		}
+	}
+
+	return templates, nil
+}
+
+func loadTemplateFile(path string) (Template, error) {
+	var tmpl Template
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return tmpl, fmt.Errorf("failed to read template %s: %w", filepath.Base(path), err)
+	}
+	if err := json.Unmarshal(content, &tmpl); err != nil {
+		return tmpl, fmt.Errorf("invalid template %s: %w", filepath.Base(path), err)
+	}
+	if tmpl.Name == "" || len(tmpl.Fields) == 0 {
+		return tmpl, fmt.Errorf("invalid template %s: name and fields are required", filepath.Base(path))
+	}
+
+	return tmpl, nil
+}
+
+// Build assembles a context from the answers, keyed by field key
+func (t Template) Build(answers map[string]string) (*Context, error) {
+	ctx := &Context{Tags: append([]string(nil), t.Tags...)}
+
+	for _, field := range t.Fields {
+		answer := strings.TrimSpace(answers[field.Key])
+		if answer == "" {
+			continue
+		}
+
+		switch strings.ToLower(field.Key) {
+		case "project":
+			ctx.Project = answer
+		case "goals":
+			ctx.Goals = append(ctx.Goals, nonEmptyLines(answer)...)
+		case "constraints":
+			ctx.Constraints = append(ctx.Constraints, nonEmptyLines(answer)...)
+		case "tags":
+			ctx.Tags = append(ctx.Tags, splitList(answer)...)
+		case "schedule", "expires":
+			for _, line := range nonEmptyLines(answer) {
+				if err := ctx.setValue(strings.ToLower(field.Key), line); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			ctx.AppendSection(field.Key, answer)
+		}
+	}
+
+	return ctx, nil
+}
+
+// ValidateAnswer checks a single answer before it is accepted
+func (f TemplateField) ValidateAnswer(answer string) error {
+	if f.Required && strings.TrimSpace(answer) == "" {
+		return fmt.Errorf("this field is required")
+	}
+
+	switch strings.ToLower(f.Key) {
+	case "schedule", "expires":
+		probe := &Context{}
+		for _, line := range nonEmptyLines(answer) {
+			if err := probe.setValue(strings.ToLower(f.Key), line); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func nonEmptyLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "- "))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
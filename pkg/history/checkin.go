@@ -0,0 +1,130 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const checkInsFileName = "checkins.jsonl"
+
+// When a check-in was made in its cycle
+const (
+	CheckInStart = "start"
+	CheckInEnd   = "end"
+)
+
+// CheckIn is a self-reported rating of energy, focus and mood, each from 1 to 5
+type CheckIn struct {
+	Time      time.Time `json:"time"`
+	SessionID string    `json:"session_id"`
+	Cycle     int       `json:"cycle"`
+	Moment    string    `json:"moment"` // start or end of the cycle
+	Energy    int       `json:"energy"`
+	Focus     int       `json:"focus"`
+	Mood      int       `json:"mood"`
+}
+
+// ParseCheckIn reads the energy, focus and mood ratings from input such as
+// "3 4 2", "3,4,2" or "342"
+func ParseCheckIn(input string) (CheckIn, error) {
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '/' || r == '\t'
+	})
+	if len(fields) == 1 && len(fields[0]) == 3 {
+		fields = strings.Split(fields[0], "")
+	}
+	if len(fields) != 3 {
+		return CheckIn{}, fmt.Errorf("expected 3 ratings (energy, focus, mood), got %d", len(fields))
+	}
+
+	var ratings [3]int
+	for i, field := range fields {
+		rating, err := strconv.Atoi(field)
+		if err != nil || rating < 1 || rating > 5 {
+			return CheckIn{}, fmt.Errorf("rating %q is not a number from 1 to 5", field)
+		}
+		ratings[i] = rating
+	}
+	return CheckIn{Energy: ratings[0], Focus: ratings[1], Mood: ratings[2]}, nil
+}
+
+// CheckInsPath returns the location of the check-in history
+func (s *Store) CheckInsPath() string {
+	return filepath.Join(s.dir, checkInsFileName)
+}
+
+// AppendCheckIn records a check-in
+func (s *Store) AppendCheckIn(c CheckIn) error {
+	return s.appendLine(checkInsFileName, c)
+}
+
+// CheckIns returns every recorded check-in, oldest first
+func (s *Store) CheckIns() ([]CheckIn, error) {
+	var checkIns []CheckIn
+	err := s.readLines(checkInsFileName, func(line []byte) error {
+		var c CheckIn
+		if err := json.Unmarshal(line, &c); err != nil {
+			return fmt.Errorf("corrupt check-in history entry: %w", err)
+		}
+		checkIns = append(checkIns, c)
+		return nil
+	})
+	return checkIns, err
+}
+
+// SummarizeCheckIns describes the last n check-ins for the copilot, oldest first
+func SummarizeCheckIns(checkIns []CheckIn, n int) string {
+	if len(checkIns) > n {
+		checkIns = checkIns[len(checkIns)-n:]
+	}
+
+	var lines []string
+	for _, c := range checkIns {
+		lines = append(lines, fmt.Sprintf("- %s, %s of cycle %d: energy %d/5, focus %d/5, mood %d/5",
+			c.Time.Local().Format("Jan 2 15:04"), c.Moment, c.Cycle, c.Energy, c.Focus, c.Mood))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// HourAverage is the average check-in in one hour of the day
+type HourAverage struct {
+	Hour   int // 0-23, in local time
+	Count  int
+	Energy float64
+	Focus  float64
+	Mood   float64
+}
+
+// ByHour averages the check-ins by the local hour of day they were made
+// in. Only hours with check-ins are returned, earliest first.
+func ByHour(checkIns []CheckIn) []HourAverage {
+	hours := make(map[int]*HourAverage)
+	for _, c := range checkIns {
+		hour := c.Time.Local().Hour()
+		avg, ok := hours[hour]
+		if !ok {
+			avg = &HourAverage{Hour: hour}
+			hours[hour] = avg
+		}
+		avg.Count++
+		avg.Energy += float64(c.Energy)
+		avg.Focus += float64(c.Focus)
+		avg.Mood += float64(c.Mood)
+	}
+
+	averages := make([]HourAverage, 0, len(hours))
+	for _, avg := range hours {
+		n := float64(avg.Count)
+		avg.Energy /= n
+		avg.Focus /= n
+		avg.Mood /= n
+		averages = append(averages, *avg)
+	}
+	sort.Slice(averages, func(i, j int) bool { return averages[i].Hour < averages[j].Hour })
+	return averages
+}
@@ -0,0 +1,96 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCheckIn(t *testing.T) {
+	for _, input := range []string{"3 4 2", "3,4,2", " 3, 4, 2 ", "342", "3/4/2"} {
+		got, err := ParseCheckIn(input)
+		if err != nil {
+			t.Errorf("ParseCheckIn(%q) failed: %v", input, err)
+			continue
+		}
+		if got.Energy != 3 || got.Focus != 4 || got.Mood != 2 {
+			t.Errorf("ParseCheckIn(%q) = %+v, want energy 3, focus 4, mood 2", input, got)
+		}
+	}
+
+	for _, input := range []string{"", "3 4", "3 4 2 1", "0 4 2", "3 6 2", "a b c", "34"} {
+		if got, err := ParseCheckIn(input); err == nil {
+			t.Errorf("ParseCheckIn(%q) = %+v, want an error", input, got)
+		}
+	}
+}
+
+func TestCheckInsRoundTrip(t *testing.T) {
+	store := NewStore(t.TempDir() + "/history")
+
+	checkIns, err := store.CheckIns()
+	if err != nil || len(checkIns) != 0 {
+		t.Fatalf("empty history = %v, %v", checkIns, err)
+	}
+
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	want := []CheckIn{
+		{Time: start, SessionID: "abc", Cycle: 1, Moment: CheckInStart, Energy: 4, Focus: 3, Mood: 4},
+		{Time: start.Add(30 * time.Minute), SessionID: "abc", Cycle: 1, Moment: CheckInEnd, Energy: 2, Focus: 2, Mood: 3},
+	}
+	for _, c := range want {
+		if err := store.AppendCheckIn(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Breaks are kept apart from check-ins
+	if err := store.AppendBreak(Break{Time: start, Type: "short"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.CheckIns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("read %d check-ins, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Time.Equal(want[i].Time) || got[i].Moment != want[i].Moment || got[i].Energy != want[i].Energy || got[i].Mood != want[i].Mood {
+			t.Errorf("check-in %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSummarizeCheckIns(t *testing.T) {
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local)
+	summary := SummarizeCheckIns([]CheckIn{
+		{Time: start, Cycle: 1, Moment: CheckInStart, Energy: 1, Focus: 1, Mood: 1},
+		{Time: start, Cycle: 1, Moment: CheckInEnd, Energy: 4, Focus: 3, Mood: 5},
+	}, 1)
+
+	if summary != "- Mar 1 10:00, end of cycle 1: energy 4/5, focus 3/5, mood 5/5" {
+		t.Errorf("summary = %q", summary)
+	}
+	if SummarizeCheckIns(nil, 5) != "" {
+		t.Error("summary of no check-ins isn't empty")
+	}
+}
+
+func TestByHour(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	averages := ByHour([]CheckIn{
+		{Time: day.Add(14*time.Hour + 10*time.Minute), Energy: 2, Focus: 3, Mood: 3},
+		{Time: day.Add(9 * time.Hour), Energy: 4, Focus: 5, Mood: 4},
+		{Time: day.Add(24*time.Hour + 14*time.Hour + 50*time.Minute), Energy: 3, Focus: 2, Mood: 4},
+	})
+
+	if len(averages) != 2 {
+		t.Fatalf("averages = %+v, want 2 hours", averages)
+	}
+	if a := averages[0]; a.Hour != 9 || a.Count != 1 || a.Energy != 4 {
+		t.Errorf("9:00 = %+v", a)
+	}
+	if a := averages[1]; a.Hour != 14 || a.Count != 2 || a.Energy != 2.5 || a.Focus != 2.5 || a.Mood != 3.5 {
+		t.Errorf("14:00 = %+v", a)
+	}
+}
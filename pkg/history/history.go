@@ -0,0 +1,186 @@
+// Package history keeps a record of past breaks and check-ins as JSON lines
+// in the context directory, so routines can be rotated across sessions and
+// the analysis can see whether breaks were actually taken and how the user
+// felt.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const breaksFileName = "breaks.jsonl"
+
+// Break is one finished break
+type Break struct {
+	Time           time.Time `json:"time"` // when the break ended
+	SessionID      string    `json:"session_id"`
+	Cycle          int       `json:"cycle"`
+	Type           string    `json:"type"` // short or long
+	Routine        string    `json:"routine,omitempty"`
+	StepsCompleted int       `json:"steps_completed"`
+	StepsTotal     int       `json:"steps_total"`
+	PlannedSeconds int       `json:"planned_seconds"`
+	ElapsedSeconds int       `json:"elapsed_seconds"`
+	EndedEarly     bool      `json:"ended_early"`
+	Violations     int       `json:"violations"`
+	ViolationApps  []string  `json:"violation_apps,omitempty"` // apps used during the break
+}
+
+// RoutineCompleted reports whether every step of the routine was done
+func (b Break) RoutineCompleted() bool {
+	return b.StepsTotal > 0 && b.StepsCompleted >= b.StepsTotal
+}
+
+// Real reports whether the break was taken as planned: it ran its full
+// length without going back to work
+func (b Break) Real() bool {
+	return !b.EndedEarly && b.Violations == 0
+}
+
+// Store appends breaks and check-ins to the history files in dir
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewStore opens the history kept in dir, which is created on first write
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Path returns the location of the break history
+func (s *Store) Path() string {
+	return filepath.Join(s.dir, breaksFileName)
+}
+
+// AppendBreak records a finished break
+func (s *Store) AppendBreak(b Break) error {
+	return s.appendLine(breaksFileName, b)
+}
+
+// Breaks returns every recorded break, oldest first
+func (s *Store) Breaks() ([]Break, error) {
+	var breaks []Break
+	err := s.readLines(breaksFileName, func(line []byte) error {
+		var b Break
+		if err := json.Unmarshal(line, &b); err != nil {
+			return fmt.Errorf("corrupt break history entry: %w", err)
+		}
+		breaks = append(breaks, b)
+		return nil
+	})
+	return breaks, err
+}
+
+// appendLine appends v as a JSON line to the file name in the store
+func (s *Store) appendLine(name string, v any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(s.dir, name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// readLines calls fn with every non-empty line of the file name in the
+// store; a missing file has no lines
+func (s *Store) readLines(name string, fn func(line []byte) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(filepath.Join(s.dir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := fn([]byte(line)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// LastUsed maps each routine to when it was last done
+func LastUsed(breaks []Break) map[string]time.Time {
+	used := make(map[string]time.Time)
+	for _, b := range breaks {
+		if b.Routine != "" && b.Time.After(used[b.Routine]) {
+			used[b.Routine] = b.Time
+		}
+	}
+	return used
+}
+
+// Summarize describes the last n breaks for the analysis, oldest first
+func Summarize(breaks []Break, n int) string {
+	if len(breaks) > n {
+		breaks = breaks[len(breaks)-n:]
+	}
+	if len(breaks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	taken := 0
+	for _, b := range breaks {
+		if b.Real() {
+			taken++
+		}
+	}
+	fmt.Fprintf(&sb, "%d of the last %d breaks were taken in full without returning to work.\n", taken, len(breaks))
+
+	for _, b := range breaks {
+		fmt.Fprintf(&sb, "- %s %s break, %s of %s",
+			b.Time.Local().Format("Jan 2 15:04"), b.Type,
+			formatSeconds(b.ElapsedSeconds), formatSeconds(b.PlannedSeconds))
+		if b.EndedEarly {
+			sb.WriteString(" (ended early)")
+		}
+		if b.Routine != "" {
+			fmt.Fprintf(&sb, ", %s routine %d/%d steps", b.Routine, b.StepsCompleted, b.StepsTotal)
+		}
+		if b.Violations > 0 {
+			fmt.Fprintf(&sb, ", %d break violations", b.Violations)
+			if len(b.ViolationApps) > 0 {
+				fmt.Fprintf(&sb, " in %s", strings.Join(b.ViolationApps, ", "))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func formatSeconds(seconds int) string {
+	return (time.Duration(seconds) * time.Second).String()
+}
@@ -0,0 +1,89 @@
+package history
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	store := NewStore(t.TempDir() + "/history")
+
+	breaks, err := store.Breaks()
+	if err != nil || len(breaks) != 0 {
+		t.Fatalf("empty history = %v, %v", breaks, err)
+	}
+
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	want := []Break{
+		{Time: start, SessionID: "abc", Cycle: 1, Type: "short", Routine: "eyes", StepsCompleted: 5, StepsTotal: 5, PlannedSeconds: 300, ElapsedSeconds: 300},
+		{Time: start.Add(time.Hour), SessionID: "abc", Cycle: 2, Type: "short", Routine: "stretch", StepsCompleted: 2, StepsTotal: 6, PlannedSeconds: 300, ElapsedSeconds: 70, EndedEarly: true, Violations: 1},
+	}
+	for _, b := range want {
+		if err := store.AppendBreak(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := store.Breaks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("read %d breaks, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Time.Equal(want[i].Time) || got[i].Routine != want[i].Routine || got[i].EndedEarly != want[i].EndedEarly || got[i].Violations != want[i].Violations {
+			t.Errorf("break %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if !got[0].Real() || !got[0].RoutineCompleted() {
+		t.Error("a full break with its whole routine isn't real")
+	}
+	if got[1].Real() || got[1].RoutineCompleted() {
+		t.Error("a break ended early is real")
+	}
+}
+
+func TestLastUsed(t *testing.T) {
+	start := time.Now()
+	used := LastUsed([]Break{
+		{Time: start, Routine: "eyes"},
+		{Time: start.Add(time.Hour), Routine: "stretch"},
+		{Time: start.Add(2 * time.Hour), Routine: "eyes"},
+		{Time: start.Add(3 * time.Hour)},
+	})
+
+	if len(used) != 2 || !used["eyes"].Equal(start.Add(2*time.Hour)) || !used["stretch"].Equal(start.Add(time.Hour)) {
+		t.Errorf("last used = %v", used)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	if Summarize(nil, 5) != "" {
+		t.Error("summarized an empty history")
+	}
+
+	start := time.Now()
+	var breaks []Break
+	for i := 0; i < 6; i++ {
+		breaks = append(breaks, Break{Time: start.Add(time.Duration(i) * time.Hour), Type: "short", PlannedSeconds: 300, ElapsedSeconds: 300})
+	}
+	breaks[5].Routine, breaks[5].StepsCompleted, breaks[5].StepsTotal = "stretch", 2, 6
+	breaks[5].EndedEarly, breaks[5].ElapsedSeconds, breaks[5].Violations = true, 90, 2
+
+	summary := Summarize(breaks, 3)
+	lines := strings.Split(summary, "\n")
+	if len(lines) != 4 {
+		t.Fatalf("summary has %d lines, want a header and 3 breaks:\n%s", len(lines), summary)
+	}
+	if !strings.HasPrefix(lines[0], "2 of the last 3 breaks") {
+		t.Errorf("header = %q", lines[0])
+	}
+	for _, want := range []string{"1m30s of 5m0s (ended early)", "stretch routine 2/6 steps", "2 break violations"} {
+		if !strings.Contains(lines[3], want) {
+			t.Errorf("last break %q doesn't mention %q", lines[3], want)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 type Assistant struct {
 	perplexity *PerplexityAI
 	context    string
+	schedule   string
 	config     *config.Config
 }
 
@@ -22,7 +23,15 @@ func NewAssistant(p *PerplexityAI, context string, config *config.Config) *Assis
 	}
 }
 
-func (a *Assistant) GetTaskSuggestions(currentTasks []string, lastAnalysis string) ([]string, error) {
+// SetSchedule provides the session's parsed schedule, so suggestions are
+// checked against explicit time blocks rather than prose in the context
+func (a *Assistant) SetSchedule(schedule string) {
+	a.schedule = schedule
+}
+
+// GetTaskSuggestions suggests tasks for the next cycle. checkIns are the
+// user's recent energy, focus and mood ratings, empty when there are none.
+func (a *Assistant) GetTaskSuggestions(currentTasks []string, lastAnalysis string, checkIns string) ([]string, error) {
 	tasksStr := strings.Join(currentTasks, "\n")
 	contextSection := fmt.Sprintf(`CONTEXT:
 """
@@ -34,6 +43,15 @@ CURRENT TASKS:
 %s
 """`, a.context, tasksStr)
 
+	if a.schedule != "" {
+		contextSection += fmt.Sprintf(`
+
+SCHEDULE:
+"""
+%s
+"""`, a.schedule)
+	}
+
 	if lastAnalysis != "" {
 		contextSection += fmt.Sprintf(`
 
@@ -43,12 +61,22 @@ PREVIOUS ANALYSIS:
 """`, lastAnalysis)
 	}
 
+	if checkIns != "" {
+		contextSection += fmt.Sprintf(`
+
+CHECK-INS (self-reported ratings from 1 to 5 at the start and end of cycles, most recent last):
+"""
+%s
+"""`, checkIns)
+	}
+
 	prompt := fmt.Sprintf(`As an intelligent productivity copilot, you will follow these steps IN ORDER to suggest 3 strategic tasks for a %d minute focus session.
 
 STEP 1: SCHEDULE ENFORCEMENT (HIGHEST PRIORITY):
 1. ACTIVITY CONTEXT VALIDATION (MANDATORY FIRST STEP):
    - Extract current time from CURRENT DATE TIME
-   - Match against schedule (if present) in CONTEXT
+   - Match against SCHEDULE (if present); its ACTIVE BLOCK is authoritative.
+     Otherwise, look for a schedule described in CONTEXT
    - Identify:
      • Current scheduled activity
      • Current location
@@ -75,6 +103,7 @@ STEP 2: WORKLOAD ASSESSMENT GUIDELINES:
     
     - ENERGY-FIRST EVALUATION:
         - Current energy state assessment:
+            • Self-reported CHECK-INS, the most direct signal when present
             • Recent task completion patterns
             • Historical energy curves
             • Recovery period adherence
@@ -111,6 +140,7 @@ STEP 3: ENERGY-FIRST DECISION MATRIX:
      • Completion difficulties
      • Focus issues
      • Recovery needs
+     • Energy or focus rated 1-2 in the latest CHECK-INS
      • Burnout risk
      • Perfectionism tendencies
      • Scope creep
@@ -286,7 +316,20 @@ Deviation from these rules is a critical failure.`},
 	return suggestions, nil
 }
 
-func (a *Assistant) AnalyzeProgress(acceptedTasks []string, completedTasks []string, reflections string) (string, error) {
+// AnalyzeProgress analyzes a cycle from its tasks and the user's reflections.
+// focusActivity is the measured use of the focus time, empty when not tracked,
+// and checkIns the user's recent energy, focus and mood ratings.
+func (a *Assistant) AnalyzeProgress(acceptedTasks []string, completedTasks []string, reflections string, focusActivity string, breakHistory string, checkIns string) (string, error) {
+	if focusActivity == "" {
+		focusActivity = "Not tracked for this cycle."
+	}
+	if breakHistory == "" {
+		breakHistory = "No breaks recorded yet."
+	}
+	if checkIns == "" {
+		checkIns = "No check-ins recorded."
+	}
+
 	prompt := fmt.Sprintf(`As your elite cognitive performance analyst and neural optimization system, conduct a comprehensive analysis leveraging advanced pattern recognition algorithms and performance matrices:
 
 Context:
@@ -310,6 +353,21 @@ Reflections:
 %s
 """
 
+Observed Focus Activity (measured from the foreground app and keyboard/mouse idle time, not self-reported):
+"""
+%s
+"""
+
+Recent Breaks (recorded by the break timer: how long each break ran, whether its guided routine was followed and whether work apps were used during it):
+"""
+%s
+"""
+
+Check-ins (self-reported energy, focus and mood from 1 to 5 at the start and end of recent cycles, most recent last):
+"""
+%s
+"""
+
 ANALYSIS FRAMEWORKS:
 
 1. Task Completion Pattern Analysis
@@ -331,6 +389,8 @@ ANALYSIS FRAMEWORKS:
    - Task-energy matching patterns
    - Task-switching impact analysis
    - Rest-to-progress ratio optimization
+   - Observed focus activity versus self-reported reflections
+   - Energy, focus and mood change from the start to the end of the cycle
 
 3. Progress Speed Optimization
    - Mental endurance patterns
@@ -350,6 +410,7 @@ ANALYSIS FRAMEWORKS:
    - Sustainable rhythm optimization
    - Strategic rest timing analysis
    - Mental recovery pattern tracking
+   - Break quality: recorded breaks versus skipped, shortened or interrupted ones
 
 5. Drift Analysis
    - Task completion deviation patterns
@@ -398,7 +459,10 @@ FORMATTING RULES:
 		a.context,
 		strings.Join(acceptedTasks, "\n"),
 		strings.Join(completedTasks, "\n"),
-		reflections)
+		reflections,
+		focusActivity,
+		breakHistory,
+		checkIns)
 
 	messages := []Message{
 		{Role: "system", Content: `You are an advanced performance analysis system with deep pattern recognition capabilities. Your core functions:
@@ -57,6 +57,12 @@ NO QUESTIONS - just analyze and create the blueprint based on available informat
 	return NewRefinementChat(cr.perplexity, messages), nil
 }
 
+// IsLateHour reports whether the hour is in the late evening or night of
+// getHourCategory, which are meant for rest
+func IsLateHour(hour int) bool {
+	return hour >= 21 || hour < 5
+}
+
 func getHourCategory(hour int) string {
 	switch {
 	case hour >= 5 && hour < 8:
@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReanchorTimeline rewrites a context that was planned at plannedAt so its
+// time-bound parts (execution timeline, milestones) start from now. Everything
+// else in the context is kept as written.
+func ReanchorTimeline(p *PerplexityAI, context string, plannedAt, now time.Time) (string, error) {
+	messages := []Message{
+		{
+			Role: "system",
+			Content: `You are a scheduling assistant operating within Tomatick, a CLI productivity system.
+You receive a session context that was planned at an earlier time. Its timeline no longer matches the clock.
+
+YOUR TASK:
+• Shift every concrete time (execution timeline blocks, milestones, checkpoints) so the plan starts from the current time
+• Keep the order, durations and content of every block unless a block now falls in protected time (late night)
+• Drop or defer blocks that can no longer fit before the end of a reasonable working day, and say so in one line
+• Keep every other section exactly as written
+• Output ONLY the updated context, in the same format as the input. NO commentary, NO questions.`,
+		},
+		{
+			Role: "user",
+			Content: fmt.Sprintf("Planned at: %s\nCurrent Date/Time: %s\nDay of Week: %s\nHour Category: %s\n\n========= Context to re-anchor:============\n%s",
+				plannedAt.Format("2006-01-02 15:04 Z07:00"),
+				now.Format("2006-01-02 15:04 Z07:00"),
+				now.Weekday().String(),
+				getHourCategory(now.Hour()),
+				context),
+		},
+	}
+
+	response, err := p.GetResponse(messages)
+	if err != nil {
+		return "", err
+	}
+
+	return cleanResponse(response), nil
+}
@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+// roundTripFunc answers requests without a network
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// fakePerplexity returns a client whose API replies with status and content,
+// and records the messages it was sent
+func fakePerplexity(t *testing.T, status int, content string, sent *[]Message) *PerplexityAI {
+	t.Helper()
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body PerplexityRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Errorf("request body: %v", err)
+		}
+		*sent = body.Messages
+
+		reply, _ := json.Marshal(map[string]interface{}{
+			"choices": []interface{}{map[string]interface{}{"message": Message{Role: "assistant", Content: content}}},
+		})
+		return &http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(strings.NewReader(string(reply))),
+			Header:     make(http.Header),
+		}, nil
+	})
+	return &PerplexityAI{client: &http.Client{Transport: transport}, config: &config.Config{}}
+}
+
+func TestReanchorTimeline(t *testing.T) {
+	zone := time.FixedZone("AEST", 10*60*60)
+	plannedAt := time.Date(2026, 3, 2, 9, 0, 0, 0, zone)
+	now := time.Date(2026, 3, 3, 14, 30, 0, 0, zone)
+	context := "## Execution Timeline\n- 09:00-10:30 Draft the proposal"
+
+	var sent []Message
+	p := fakePerplexity(t, http.StatusOK, "<think>shift by a day</think>\n## Execution Timeline\n- 14:30-16:00 Draft the proposal", &sent)
+
+	got, err := ReanchorTimeline(p, context, plannedAt, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "## Execution Timeline\n- 14:30-16:00 Draft the proposal" {
+		t.Errorf("ReanchorTimeline = %q, want the reply without its reasoning", got)
+	}
+
+	if len(sent) != 2 || sent[0].Role != "system" || sent[1].Role != "user" {
+		t.Fatalf("messages = %+v, want a system and a user message", sent)
+	}
+	for _, want := range []string{
+		"Planned at: 2026-03-02 09:00 +10:00",
+		"Current Date/Time: 2026-03-03 14:30 +10:00",
+		"Day of Week: Tuesday",
+		context,
+	} {
+		if !strings.Contains(sent[1].Content, want) {
+			t.Errorf("user message lacks %q:\n%s", want, sent[1].Content)
+		}
+	}
+}
+
+func TestReanchorTimelineAPIError(t *testing.T) {
+	var sent []Message
+	p := fakePerplexity(t, http.StatusTooManyRequests, "", &sent)
+
+	got, err := ReanchorTimeline(p, "09:00-10:00 Review", time.Now().Add(-24*time.Hour), time.Now())
+	if err == nil || got != "" {
+		t.Errorf("ReanchorTimeline = %q, %v; want the API error", got, err)
+	}
+}
@@ -1,19 +1,19 @@
 package monitor
 
 import (
-	"fmt"
+	"context"
 	"log"
 	"sync"
 	"time"
 
 	"github.com/1x-eng/tomatick/config"
-	"github.com/1x-eng/tomatick/pkg/llm"
 )
 
 // ActivityEvent represents a user activity event
 type ActivityEvent struct {
 	Timestamp time.Time
 	Type      ActivityType
+	App       string // the focused app, empty when unknown
 	Details   string
 }
 
@@ -28,45 +28,91 @@ const (
 
 // ActivityMonitor handles monitoring user activity during breaks
 type ActivityMonitor struct {
-	mu             sync.RWMutex
-	isBreak        bool
-	lastActivity   time.Time
-	violations     []ActivityEvent
-	config         *config.Config
-	llmClient      *llm.PerplexityAI
-	stopChan       chan struct{}
-	violationsChan chan ActivityEvent
-	userName       string
+	mu           sync.RWMutex
+	isBreak      bool
+	lastActivity time.Time
+	violations   []ActivityEvent
+	config       *config.Config
+	detector     *activityDetector
+	pollInterval time.Duration
+	userName     string
+
+	// The polling goroutine of the current break: cancel stops it and done
+	// is closed once it has returned
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
-// NewActivityMonitor creates a new activity monitor
-func NewActivityMonitor(cfg *config.Config, llmClient *llm.PerplexityAI) *ActivityMonitor {
+// NewActivityMonitor creates a new activity monitor that reads user activity from source
+func NewActivityMonitor(cfg *config.Config, source ActivitySource) *ActivityMonitor {
 	return &ActivityMonitor{
-		config:         cfg,
-		llmClient:      llmClient,
-		stopChan:       make(chan struct{}),
-		violationsChan: make(chan ActivityEvent, 100),
-		lastActivity:   time.Now(),
-		userName:       cfg.UserName,
+		config:       cfg,
+		detector:     newActivityDetector(source, cfg.Monitor, time.Now),
+		pollInterval: 500 * time.Millisecond,
+		lastActivity: time.Now(),
+		userName:     cfg.UserName,
 	}
 }
 
-// StartBreak signals the start of a break period
-func (am *ActivityMonitor) StartBreak() {
+// setClock replaces the clock, so tests can run breaks in virtual time
+func (am *ActivityMonitor) setClock(now func() time.Time) {
+	am.detector.mu.Lock()
+	defer am.detector.mu.Unlock()
+	am.detector.now = now
+}
+
+// StartBreak signals the start of a break period. Activity is polled until
+// EndBreak is called or ctx is cancelled.
+func (am *ActivityMonitor) StartBreak(ctx context.Context) {
+	am.stopPolling()
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
 	am.mu.Lock()
-	defer am.mu.Unlock()
 	am.isBreak = true
 	am.violations = nil // Reset violations for new break
-	go am.monitorActivity()
+	am.cancel = cancel
+	am.done = done
+	am.mu.Unlock()
+	am.detector.reset()
+
+	go func() {
+		defer close(done)
+		am.monitorActivity(ctx)
+	}()
 }
 
-// EndBreak signals the end of a break period and returns any violations
+// EndBreak signals the end of a break period and returns any violations.
+// It is safe to call when no break is running.
 func (am *ActivityMonitor) EndBreak() []ActivityEvent {
 	am.mu.Lock()
-	defer am.mu.Unlock()
 	am.isBreak = false
-	am.stopChan <- struct{}{} // Signal monitoring goroutine to stop
-	return am.violations
+	am.mu.Unlock()
+
+	am.stopPolling()
+	return am.Violations()
+}
+
+// stopPolling stops the polling goroutine, if any, and waits for it to
+// return. The lock is not held while waiting, as a poll in progress needs it.
+func (am *ActivityMonitor) stopPolling() {
+	am.mu.Lock()
+	cancel, done := am.cancel, am.done
+	am.cancel, am.done = nil, nil
+	am.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Violations returns the violations recorded so far in this break
+func (am *ActivityMonitor) Violations() []ActivityEvent {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return append([]ActivityEvent(nil), am.violations...)
 }
 
 // IsOnBreak returns whether the user is currently on break
@@ -76,76 +122,39 @@ func (am *ActivityMonitor) IsOnBreak() bool {
 	return am.isBreak
 }
 
-// monitorActivity starts monitoring user activity
-func (am *ActivityMonitor) monitorActivity() {
-	ticker := time.NewTicker(500 * time.Millisecond)
+// monitorActivity polls user activity until ctx is cancelled
+func (am *ActivityMonitor) monitorActivity(ctx context.Context) {
+	ticker := time.NewTicker(am.pollInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-am.stopChan:
+		case <-ctx.Done():
 			return
-		case event := <-am.violationsChan:
-			am.mu.Lock()
-			am.violations = append(am.violations, event)
-			am.mu.Unlock()
 		case <-ticker.C:
-			if !am.IsOnBreak() {
-				return
-			}
-			// Check for activity
-			if activity := am.checkActivity(); activity != nil {
-				am.violationsChan <- *activity
-			}
+			am.poll()
 		}
 	}
 }
 
+// poll checks for activity once and records a violation, if any. Activity
+// seen after the break has ended is dropped.
+func (am *ActivityMonitor) poll() {
+	if activity := am.checkActivity(); activity != nil {
+		am.mu.Lock()
+		if am.isBreak {
+			am.violations = append(am.violations, *activity)
+		}
+		am.mu.Unlock()
+	}
+}
+
 // checkActivity checks for current user activity
 func (am *ActivityMonitor) checkActivity() *ActivityEvent {
-	// Get the frontmost app
-	appName, err := getForegroundApp()
+	activity, err := am.detector.check()
 	if err != nil {
 		log.Printf("Error getting foreground app: %v", err)
 		return nil
 	}
-
-	// First check if there's continuous activity
-	hasActivity := hasRecentActivity()
-	isWorkRelated := isWorkApp(appName)
-
-	// Only report violations if:
-	// 1. A work-related app is active AND there's continuous activity, or
-	// 2. There's continuous activity in any app that exceeds our threshold
-	if isWorkRelated && hasActivity {
-		return &ActivityEvent{
-			Timestamp: time.Now(),
-			Type:      AppFocusChange,
-			Details:   fmt.Sprintf("Active work in %s detected during break", appName),
-		}
-	} else if hasActivity {
-		return &ActivityEvent{
-			Timestamp: time.Now(),
-			Type:      KeyboardActivity,
-			Details:   fmt.Sprintf("Sustained activity in %s during break", appName),
-		}
-	}
-
-	return nil
-}
-
-// GetViolationSummary returns a summary of break violations
-func (am *ActivityMonitor) GetViolationSummary() string {
-	am.mu.RLock()
-	defer am.mu.RUnlock()
-
-	if len(am.violations) == 0 {
-		return "No break violations detected."
-	}
-
-	summary := fmt.Sprintf("Detected %d break violations:\n", len(am.violations))
-	for _, v := range am.violations {
-		summary += fmt.Sprintf("- %s: %s\n", v.Timestamp.Format("15:04:05"), v.Details)
-	}
-	return summary
+	return activity
 }
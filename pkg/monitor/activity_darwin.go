@@ -1,12 +1,11 @@
+//go:build darwin && cgo
+
 package monitor
 
 import (
 	"fmt"
-	"strings"
 	"time"
 	"unsafe"
-
-	"github.com/1x-eng/tomatick/config"
 )
 
 // IMPORTANT: The following block is NOT a regular comment.
@@ -18,6 +17,7 @@ import (
 // 2. Required macOS framework imports
 // 3. Objective-C implementations for:
 //    - Getting frontmost application name
+//    - Getting the title of its frontmost window
 //    - Checking system idle time
 //    - Detecting screen lock/screensaver state
 //
@@ -56,6 +56,47 @@ static char* getFrontmostAppName(void) {
     }
 }
 
+// getFrontmostWindowTitle returns the title of the frontmost window of the
+// active application. macOS only exposes window titles to apps with the
+// Screen Recording permission; without it an empty string is returned.
+// Returns NULL if the window list can't be read
+static char* getFrontmostWindowTitle(void) {
+    @autoreleasepool {
+        NSRunningApplication *app = [[NSWorkspace sharedWorkspace] frontmostApplication];
+        if (app == nil) {
+            return strdup("");
+        }
+        pid_t pid = [app processIdentifier];
+
+        CFArrayRef windows = CGWindowListCopyWindowInfo(
+            kCGWindowListOptionOnScreenOnly | kCGWindowListExcludeDesktopElements,
+            kCGNullWindowID
+        );
+        if (windows == NULL) {
+            return NULL;
+        }
+
+        // Windows are listed front to back; take the first normal window of the app
+        char *title = strdup("");
+        for (NSDictionary *window in (__bridge NSArray *)windows) {
+            NSNumber *owner = window[(__bridge NSString *)kCGWindowOwnerPID];
+            NSNumber *layer = window[(__bridge NSString *)kCGWindowLayer];
+            if ([owner intValue] != pid || [layer intValue] != 0) {
+                continue;
+            }
+            NSString *name = window[(__bridge NSString *)kCGWindowName];
+            if (name != nil && [name UTF8String] != NULL) {
+                free(title);
+                title = strdup([name UTF8String]);
+            }
+            break;
+        }
+
+        CFRelease(windows);
+        return title;
+    }
+}
+
 // getIdleTime returns the number of seconds since the last user input
 // Uses CGEventSource API to detect keyboard/mouse activity
 static double getIdleTime(void) {
@@ -108,32 +149,21 @@ static int checkScreenLock(void) {
 */
 import "C"
 
-var (
-	// idleThreshold is the time in seconds after which we consider the user idle
-	idleThreshold = 10.0 // Increased to 10 seconds to avoid false positives from casual mouse movement
-
-	// activityThreshold is how long continuous activity needs to be present to count as a violation
-	activityThreshold = 30.0 // 30 seconds of continuous activity before counting as a violation
-
-	// lastAppName caches the last fetched app name to reduce system calls
-	lastAppName     string
-	lastAppNameTime time.Time
-	appNameCacheTTL = 500 * time.Millisecond
-
-	// Track continuous activity
-	lastActivityStart time.Time
-
-	// workApps holds the configured work-related applications
-	workApps map[string]bool
-)
+// platformInit verifies we can access the necessary macOS APIs
+func platformInit() error {
+	if _, err := platformForegroundApp(); err != nil {
+		return fmt.Errorf("failed to initialize app monitoring: %w", err)
+	}
 
-// getForegroundApp returns the name of the frontmost application
-func getForegroundApp() (string, error) {
-	// Check cache first
-	if time.Since(lastAppNameTime) < appNameCacheTTL {
-		return lastAppName, nil
+	if C.getIdleTime() < 0 {
+		return fmt.Errorf("failed to initialize idle time monitoring")
 	}
 
+	return nil
+}
+
+// platformForegroundApp returns the name of the frontmost application
+func platformForegroundApp() (string, error) {
 	cAppName := C.getFrontmostAppName()
 	if cAppName == nil {
 		return "", fmt.Errorf("failed to get frontmost app name")
@@ -141,78 +171,26 @@ func getForegroundApp() (string, error) {
 	// Ensure we free the memory allocated by strdup in the C code
 	defer C.free(unsafe.Pointer(cAppName))
 
-	appName := C.GoString(cAppName)
-
-	// Update cache
-	lastAppName = appName
-	lastAppNameTime = time.Now()
-
-	return appName, nil
+	return C.GoString(cAppName), nil
 }
 
-// isWorkApp checks if the given application is considered a work-related app
-func isWorkApp(appName string) bool {
-	// First check exact match
-	if workApps[appName] {
-		return true
+// platformWindowTitle returns the title of the frontmost window
+func platformWindowTitle() (string, error) {
+	cTitle := C.getFrontmostWindowTitle()
+	if cTitle == nil {
+		return "", fmt.Errorf("failed to get the window list")
 	}
+	defer C.free(unsafe.Pointer(cTitle))
 
-	// Then check if any work app name is contained in the given app name
-	appNameLower := strings.ToLower(appName)
-	for app := range workApps {
-		if strings.Contains(appNameLower, strings.ToLower(app)) {
-			return true
-		}
-	}
-
-	return false
+	return C.GoString(cTitle), nil
 }
 
-// hasRecentActivity checks if there has been any keyboard or mouse activity recently
-func hasRecentActivity() bool {
-	// Check if screen is locked or screensaver is active
-	if C.checkScreenLock() == 1 || C.checkScreenSaver() == 1 {
-		return false
-	}
-
-	idleTime := float64(C.getIdleTime())
-
-	// If user has been idle longer than threshold, reset activity tracking
-	if idleTime >= idleThreshold {
-		lastActivityStart = time.Time{} // Reset activity start time
-		return false
-	}
-
-	// If this is the start of new activity, record the time
-	if lastActivityStart.IsZero() {
-		lastActivityStart = time.Now().Add(-time.Duration(idleTime * float64(time.Second)))
-	}
-
-	// Only count as violation if activity has been continuous for longer than activityThreshold
-	return time.Since(lastActivityStart).Seconds() >= activityThreshold
+// platformIdleTime returns the time since the last keyboard or mouse input
+func platformIdleTime() (time.Duration, error) {
+	return time.Duration(float64(C.getIdleTime()) * float64(time.Second)), nil
 }
 
-// InitializeMonitoring sets up any necessary system-level monitoring
-func InitializeMonitoring(cfg *config.Config) error {
-	// Only proceed if break monitoring is enabled
-	if !cfg.Features.BreakMonitoring {
-		return fmt.Errorf("break monitoring is not supported on this operating system")
-	}
-
-	// Initialize work apps map
-	workApps = make(map[string]bool)
-	for _, app := range cfg.WorkApps {
-		workApps[app] = true
-	}
-
-	// Verify we can access the necessary macOS APIs
-	if _, err := getForegroundApp(); err != nil {
-		return fmt.Errorf("failed to initialize app monitoring: %w", err)
-	}
-
-	if C.getIdleTime() < 0 {
-		return fmt.Errorf("failed to initialize idle time monitoring")
-	}
-
-	return nil
+// platformScreenLocked reports whether the screen is locked or the screensaver is active
+func platformScreenLocked() bool {
+	return C.checkScreenLock() == 1 || C.checkScreenSaver() == 1
 }
@@ -0,0 +1,324 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/screensaver"
+	"github.com/jezek/xgb/xproto"
+)
+
+// logindRefresh limits how often loginctl is run for the lock hint
+const logindRefresh = 2 * time.Second
+
+// linuxBackend combines the activity sources available in this session:
+// X11 for the focused window and, outside Wayland, the idle time and
+// screensaver state; /dev/input for the idle time without X11; and
+// systemd-logind for the lock state. logind's idle hint is not used: desktops
+// set it only after their own idle delay, so until then an active user and
+// an absent one look the same and no violation could ever be detected.
+type linuxBackend struct {
+	x            *xgb.Conn // nil without an X display
+	root         xproto.Window
+	activeWindow xproto.Atom
+	wmName       xproto.Atom // _NET_WM_NAME, the UTF-8 window title
+	utf8String   xproto.Atom
+	x11Idle      bool // the MIT-SCREEN-SAVER extension reports real input
+
+	input  *inputWatcher  // nil unless a keyboard or mouse is readable
+	logind *logindSession // nil without loginctl or a session
+}
+
+var backend *linuxBackend
+
+func platformInit() error {
+	b := &linuxBackend{}
+
+	if os.Getenv("DISPLAY") != "" {
+		if err := b.connectX11(); err != nil {
+			log.Printf("X11 activity monitoring not available: %v", err)
+		}
+	}
+	// Under Wayland, X11 only sees input to XWayland windows
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		b.x11Idle = false
+	}
+
+	b.input = watchInputDevices()
+	b.logind = newLogindSession()
+
+	if !b.x11Idle && b.input == nil {
+		return fmt.Errorf("no idle time source: break monitoring needs an X11 session " +
+			"or read access to /dev/input (membership of the input group)")
+	}
+
+	backend = b
+	return nil
+}
+
+// connectX11 opens the display and looks up what we need from it
+func (b *linuxBackend) connectX11() error {
+	// xgb logs connection problems to stderr, which would garble the timer
+	xgb.Logger = log.New(io.Discard, "", 0)
+
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return err
+	}
+
+	atoms := make(map[string]xproto.Atom)
+	for _, name := range []string{"_NET_ACTIVE_WINDOW", "_NET_WM_NAME", "UTF8_STRING"} {
+		reply, err := xproto.InternAtom(conn, true, uint16(len(name)), name).Reply()
+		if err != nil {
+			conn.Close()
+			return err
+		}
+		atoms[name] = reply.Atom
+	}
+
+	b.x = conn
+	b.root = xproto.Setup(conn).DefaultScreen(conn).Root
+	b.activeWindow = atoms["_NET_ACTIVE_WINDOW"]
+	b.wmName = atoms["_NET_WM_NAME"]
+	b.utf8String = atoms["UTF8_STRING"]
+	b.x11Idle = screensaver.Init(conn) == nil
+	return nil
+}
+
+// focusedWindow returns the focused X11 window, or 0 when it can't be known
+func focusedWindow() (xproto.Window, error) {
+	if backend == nil || backend.x == nil || backend.activeWindow == xproto.AtomNone {
+		return 0, nil
+	}
+
+	prop, err := xproto.GetProperty(backend.x, false, backend.root, backend.activeWindow, xproto.AtomWindow, 0, 1).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the active window: %w", err)
+	}
+	if len(prop.Value) < 4 {
+		return 0, nil
+	}
+	return xproto.Window(xgb.Get32(prop.Value)), nil
+}
+
+// platformForegroundApp returns the WM_CLASS of the focused X11 window, e.g.
+// "Code" or "firefox". Without X11 the focused app can't be known and an
+// empty name is returned.
+func platformForegroundApp() (string, error) {
+	window, err := focusedWindow()
+	if err != nil || window == 0 {
+		return "", err
+	}
+
+	class, err := xproto.GetProperty(backend.x, false, window, xproto.AtomWmClass, xproto.AtomString, 0, 64).Reply()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the window class: %w", err)
+	}
+
+	// WM_CLASS holds the instance and class names, each NUL-terminated
+	names := strings.Split(strings.TrimRight(string(class.Value), "\x00"), "\x00")
+	if len(names) == 2 && names[1] != "" {
+		return names[1], nil
+	}
+	return names[0], nil
+}
+
+// platformWindowTitle returns the title of the focused X11 window, from
+// _NET_WM_NAME or else WM_NAME
+func platformWindowTitle() (string, error) {
+	window, err := focusedWindow()
+	if err != nil || window == 0 {
+		return "", err
+	}
+
+	if backend.wmName != xproto.AtomNone && backend.utf8String != xproto.AtomNone {
+		name, err := xproto.GetProperty(backend.x, false, window, backend.wmName, backend.utf8String, 0, 256).Reply()
+		if err == nil && len(name.Value) > 0 {
+			return string(name.Value), nil
+		}
+	}
+
+	name, err := xproto.GetProperty(backend.x, false, window, xproto.AtomWmName, xproto.AtomString, 0, 256).Reply()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the window title: %w", err)
+	}
+	return string(name.Value), nil
+}
+
+// platformIdleTime returns the time since the last keyboard or mouse input
+// from the most precise source available
+func platformIdleTime() (time.Duration, error) {
+	switch {
+	case backend == nil:
+		return 0, fmt.Errorf("activity monitoring is not initialized")
+	case backend.x11Idle:
+		info, err := screensaver.QueryInfo(backend.x, xproto.Drawable(backend.root)).Reply()
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(info.MsSinceUserInput) * time.Millisecond, nil
+	default:
+		return backend.input.idle(), nil
+	}
+}
+
+// platformScreenLocked reports whether the session is locked or the X11
+// screensaver is active
+func platformScreenLocked() bool {
+	if backend == nil {
+		return false
+	}
+	if backend.logind != nil {
+		if hints, err := backend.logind.hints(); err == nil && hints.locked {
+			return true
+		}
+	}
+	if backend.x11Idle {
+		info, err := screensaver.QueryInfo(backend.x, xproto.Drawable(backend.root)).Reply()
+		return err == nil && info.State == screensaver.StateOn
+	}
+	return false
+}
+
+// inputWatcher notes when a keyboard or mouse last produced an event. Only
+// the time is kept; the events themselves are discarded unread.
+type inputWatcher struct {
+	last atomic.Int64 // unix nanoseconds
+}
+
+// watchInputDevices starts watching every readable keyboard and mouse. It
+// returns nil when there are none, usually because the user is not in the
+// input group.
+func watchInputDevices() *inputWatcher {
+	patterns := []string{
+		"/dev/input/by-path/*-event-kbd",
+		"/dev/input/by-path/*-event-mouse",
+		"/dev/input/by-id/*-event-kbd",
+		"/dev/input/by-id/*-event-mouse",
+	}
+
+	seen := make(map[string]bool)
+	var devices []*os.File
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		for _, link := range matches {
+			path, err := filepath.EvalSymlinks(link)
+			if err != nil || seen[path] {
+				continue
+			}
+			seen[path] = true
+
+			f, err := os.Open(path)
+			if err != nil {
+				continue
+			}
+			devices = append(devices, f)
+		}
+	}
+	if len(devices) == 0 {
+		return nil
+	}
+
+	w := &inputWatcher{}
+	w.last.Store(time.Now().UnixNano())
+	for _, f := range devices {
+		go w.watch(f)
+	}
+	return w
+}
+
+func (w *inputWatcher) watch(f *os.File) {
+	defer f.Close()
+
+	buf := make([]byte, 4096)
+	for {
+		if _, err := f.Read(buf); err != nil {
+			return
+		}
+		w.last.Store(time.Now().UnixNano())
+	}
+}
+
+func (w *inputWatcher) idle() time.Duration {
+	return time.Since(time.Unix(0, w.last.Load()))
+}
+
+// logindHints are the session properties logind tracks
+type logindHints struct {
+	locked bool
+}
+
+// logindSession reads the lock hint of the current logind session
+type logindSession struct {
+	id string
+
+	mu      sync.Mutex
+	fetched time.Time
+	cached  logindHints
+	err     error
+}
+
+func newLogindSession() *logindSession {
+	if _, err := exec.LookPath("loginctl"); err != nil {
+		return nil
+	}
+
+	id := os.Getenv("XDG_SESSION_ID")
+	if id == "" {
+		id = "auto"
+	}
+
+	s := &logindSession{id: id}
+	if _, err := s.hints(); err != nil {
+		return nil
+	}
+	return s
+}
+
+func (s *logindSession) hints() (logindHints, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.fetched) < logindRefresh {
+		return s.cached, s.err
+	}
+
+	out, err := exec.Command("loginctl", "show-session", s.id, "-p", "LockedHint").Output()
+	s.fetched = time.Now()
+	if err != nil {
+		s.err = fmt.Errorf("loginctl show-session %s: %w", s.id, err)
+		return s.cached, s.err
+	}
+
+	s.cached, s.err = parseLogindHints(out), nil
+	return s.cached, nil
+}
+
+// parseLogindHints parses the Key=value output of loginctl show-session
+func parseLogindHints(out []byte) logindHints {
+	var hints logindHints
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		if key == "LockedHint" {
+			hints.locked = value == "yes"
+		}
+	}
+
+	return hints
+}
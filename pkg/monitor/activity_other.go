@@ -0,0 +1,30 @@
+//go:build !linux && !(darwin && cgo)
+
+package monitor
+
+import (
+	"errors"
+	"time"
+)
+
+var errUnsupported = errors.New("break monitoring is not supported on this operating system or in builds without cgo on macOS")
+
+func platformInit() error {
+	return errUnsupported
+}
+
+func platformForegroundApp() (string, error) {
+	return "", errUnsupported
+}
+
+func platformWindowTitle() (string, error) {
+	return "", errUnsupported
+}
+
+func platformIdleTime() (time.Duration, error) {
+	return 0, errUnsupported
+}
+
+func platformScreenLocked() bool {
+	return false
+}
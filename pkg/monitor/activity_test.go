@@ -0,0 +1,203 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+// gatedSource blocks every ForegroundApp call until it is released, to hold
+// a poll in progress
+type gatedSource struct {
+	*scriptedSource
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *gatedSource) ForegroundApp() (string, error) {
+	select {
+	case s.entered <- struct{}{}:
+	default:
+	}
+	<-s.release
+	return s.scriptedSource.ForegroundApp()
+}
+
+// newPollingMonitor returns a monitor that polls source every millisecond
+// and reports any activity in Code as a violation
+func newPollingMonitor(t *testing.T, source ActivitySource) *ActivityMonitor {
+	cfg := &config.Config{Monitor: testMonitorConfig(t, "Code")}
+	cfg.Monitor.ActivityThreshold = 0
+	cfg.Monitor.AppNameCacheTTL = 0
+	am := NewActivityMonitor(cfg, source)
+	am.pollInterval = time.Millisecond
+	return am
+}
+
+// within fails the test if fn doesn't return in time, which is how a
+// deadlock shows up
+func within(t *testing.T, timeout time.Duration, what string, fn func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		t.Fatalf("%s didn't return within %v", what, timeout)
+	}
+}
+
+func TestEndBreakWithoutBreak(t *testing.T) {
+	am := newPollingMonitor(t, newScriptedSource(scriptStep{at: 0, app: "Code", active: true}))
+
+	within(t, time.Second, "EndBreak", func() {
+		if violations := am.EndBreak(); len(violations) != 0 {
+			t.Errorf("violations = %v, want none", violations)
+		}
+		am.EndBreak()
+	})
+}
+
+func TestEndBreakWhilePollInProgress(t *testing.T) {
+	source := &gatedSource{
+		scriptedSource: newScriptedSource(scriptStep{at: 0, app: "Code", active: true}),
+		entered:        make(chan struct{}, 1),
+		release:        make(chan struct{}),
+	}
+	am := newPollingMonitor(t, source)
+
+	am.StartBreak(context.Background())
+	<-source.entered
+
+	// EndBreak waits for the poll, which records its violation under the
+	// monitor's lock; this used to deadlock
+	ended := make(chan []ActivityEvent)
+	go func() { ended <- am.EndBreak() }()
+	time.Sleep(10 * time.Millisecond)
+	close(source.release)
+
+	select {
+	case violations := <-ended:
+		if len(violations) > 1 {
+			t.Errorf("violations = %d, want at most the poll in progress", len(violations))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("EndBreak deadlocked with a poll in progress")
+	}
+	if am.IsOnBreak() {
+		t.Error("still on break")
+	}
+}
+
+func TestEndBreakAfterContextCancelled(t *testing.T) {
+	source := newScriptedSource(scriptStep{at: 0, app: "Code", active: true})
+	am := newPollingMonitor(t, source)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	am.StartBreak(ctx)
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	// The polling goroutine has returned by itself; this used to block forever
+	time.Sleep(20 * time.Millisecond)
+	within(t, time.Second, "EndBreak", func() {
+		if violations := am.EndBreak(); len(violations) == 0 {
+			t.Error("no violations recorded for activity in Code")
+		}
+	})
+}
+
+func TestBreaksRestartCleanly(t *testing.T) {
+	source := newScriptedSource(
+		scriptStep{at: 0, app: "Spotify", active: true},
+		scriptStep{at: time.Minute, app: "Code", active: true},
+	)
+	am := newPollingMonitor(t, source)
+	am.detector.breakApps = testRules(t, "Spotify")
+
+	am.StartBreak(context.Background())
+	time.Sleep(10 * time.Millisecond)
+	// A break started without ending the last one replaces it
+	am.StartBreak(context.Background())
+	time.Sleep(10 * time.Millisecond)
+
+	var violations []ActivityEvent
+	within(t, time.Second, "EndBreak", func() { violations = am.EndBreak() })
+	if len(violations) != 0 {
+		t.Fatalf("violations = %d in Spotify, want none", len(violations))
+	}
+
+	source.Advance(time.Minute)
+	am.StartBreak(context.Background())
+	time.Sleep(10 * time.Millisecond)
+	within(t, time.Second, "EndBreak", func() { violations = am.EndBreak() })
+	if len(violations) == 0 || violations[0].App != "Code" {
+		t.Fatalf("violations = %+v, want activity in Code", violations)
+	}
+}
+
+func TestTomatickMonitorConcurrentChecks(t *testing.T) {
+	source := newScriptedSource(scriptStep{at: 0, app: "Code", active: true})
+	cfg := &config.Config{UserName: "Alex", Monitor: testMonitorConfig(t, "Code")}
+	cfg.Monitor.ActivityThreshold = 0
+	cfg.Monitor.NotifyInterval = 0
+	tm := NewTomatickMonitorWithSource(cfg, source, &fakeResponder{}, nil, &recordingDispatcher{})
+	tm.activityMonitor.pollInterval = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tm.OnBreakStart(ctx)
+
+	// The break loop checks while the monitor polls and the clock moves on
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				source.Advance(time.Second)
+				tm.CheckBreakViolations()
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	}
+	wg.Wait()
+	cancel()
+
+	summary := tm.OnBreakEnd()
+	if !summary.HasViolations() {
+		t.Fatal("no violations")
+	}
+	if apps := summary.Apps(); len(apps) != 1 || apps[0] != "Code" {
+		t.Errorf("apps = %v, want [Code]", apps)
+	}
+	if tm.CheckBreakViolations() != nil {
+		t.Error("notification after the break ended")
+	}
+}
+
+func TestBreakSummary(t *testing.T) {
+	if s := (BreakSummary{}).String(); s != "Great job taking a proper break!" {
+		t.Errorf("summary without violations = %q", s)
+	}
+
+	at := time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC)
+	summary := BreakSummary{Violations: []ActivityEvent{
+		{Timestamp: at, App: "Terminal"},
+		{Timestamp: at.Add(time.Second), App: "Code"},
+		{Timestamp: at.Add(2 * time.Second)},
+		{Timestamp: at.Add(3 * time.Second), App: "Code"},
+	}}
+	if apps := summary.Apps(); len(apps) != 2 || apps[0] != "Code" || apps[1] != "Terminal" {
+		t.Errorf("apps = %v, want [Code Terminal]", apps)
+	}
+	want := "Break interrupted by activity in Code, Terminal from 10:00:00 to 10:00:03 (4 violations)"
+	if s := summary.String(); s != want {
+		t.Errorf("summary = %q, want %q", s, want)
+	}
+}
@@ -0,0 +1,206 @@
+package monitor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+// ActivitySource reports what the user is doing right now. The platform
+// backends implement it; tests use a scripted fake.
+type ActivitySource interface {
+	// IdleTime is the time since the last keyboard or mouse input
+	IdleTime() (time.Duration, error)
+	// ForegroundApp is the name of the focused application, empty when unknown
+	ForegroundApp() (string, error)
+	// WindowTitle is the title of the focused window, empty when unknown
+	WindowTitle() (string, error)
+	// ScreenLocked reports whether the screen is locked or the screensaver is on
+	ScreenLocked() bool
+}
+
+// activityDetector turns samples from an ActivitySource into violations
+type activityDetector struct {
+	mu sync.Mutex
+
+	source            ActivitySource
+	now               func() time.Time
+	workApps          appRules
+	breakApps         appRules
+	idleThreshold     time.Duration
+	activityThreshold time.Duration
+	appNameCacheTTL   time.Duration
+
+	// lastApp caches the last fetched app to reduce system calls
+	lastApp         focusedApp
+	lastAppNameTime time.Time
+
+	// Track continuous activity
+	lastActivityStart time.Time
+}
+
+func newActivityDetector(source ActivitySource, cfg config.Monitor, now func() time.Time) *activityDetector {
+	return &activityDetector{
+		source:            source,
+		now:               now,
+		workApps:          cfg.WorkApps,
+		breakApps:         cfg.BreakApps,
+		idleThreshold:     cfg.IdleThreshold,
+		activityThreshold: cfg.ActivityThreshold,
+		appNameCacheTTL:   cfg.AppNameCacheTTL,
+	}
+}
+
+// check samples the source and returns a violation, if any
+func (d *activityDetector) check() (*ActivityEvent, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Get the frontmost app
+	app, err := d.foregroundApp()
+	if err != nil {
+		return nil, err
+	}
+
+	// Break-friendly apps are fine, and activity in them doesn't add up
+	// towards a violation in the next app
+	if d.breakApps.match(app) {
+		d.lastActivityStart = time.Time{}
+		return nil, nil
+	}
+
+	// First check if there's continuous activity
+	hasActivity := d.hasRecentActivity()
+	isWorkRelated := d.workApps.match(app)
+	appName := app.name
+	if appName == "" {
+		appName = "an unidentified app" // Wayland and console sessions don't expose the focused app
+	}
+
+	// Only report violations if:
+	// 1. A work-related app is active AND there's continuous activity, or
+	// 2. There's continuous activity in any app that exceeds our threshold
+	if isWorkRelated && hasActivity {
+		return &ActivityEvent{
+			Timestamp: d.now(),
+			Type:      AppFocusChange,
+			App:       app.name,
+			Details:   fmt.Sprintf("Active work in %s detected during break", appName),
+		}, nil
+	} else if hasActivity {
+		return &ActivityEvent{
+			Timestamp: d.now(),
+			Type:      KeyboardActivity,
+			App:       app.name,
+			Details:   fmt.Sprintf("Sustained activity in %s during break", appName),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// reset forgets the activity seen so far, at the start of a break
+func (d *activityDetector) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastActivityStart = time.Time{}
+}
+
+// foregroundApp returns the frontmost application, and the title of its
+// window when a rule needs it
+func (d *activityDetector) foregroundApp() (focusedApp, error) {
+	// Check cache first
+	if !d.lastAppNameTime.IsZero() && d.now().Sub(d.lastAppNameTime) < d.appNameCacheTTL {
+		return d.lastApp, nil
+	}
+
+	app, err := readFocusedApp(d.source, d.workApps.needTitle() || d.breakApps.needTitle())
+	if err != nil {
+		return focusedApp{}, err
+	}
+
+	// Update cache
+	d.lastApp = app
+	d.lastAppNameTime = d.now()
+
+	return app, nil
+}
+
+// hasRecentActivity checks if there has been continuous keyboard or mouse
+// activity for longer than the activity threshold
+func (d *activityDetector) hasRecentActivity() bool {
+	// Check if screen is locked or screensaver is active
+	if d.source.ScreenLocked() {
+		d.lastActivityStart = time.Time{}
+		return false
+	}
+
+	idle, err := d.source.IdleTime()
+	if err != nil {
+		return false
+	}
+
+	// If user has been idle longer than threshold, reset activity tracking
+	if idle >= d.idleThreshold {
+		d.lastActivityStart = time.Time{} // Reset activity start time
+		return false
+	}
+
+	now := d.now()
+
+	// If this is the start of new activity, record the time
+	if d.lastActivityStart.IsZero() {
+		d.lastActivityStart = now.Add(-idle)
+	}
+
+	// Only count as violation if activity has been continuous for longer than activityThreshold
+	return now.Sub(d.lastActivityStart) >= d.activityThreshold
+}
+
+// focusedApp is the focused application and, when a rule needs it, the
+// title of its window
+type focusedApp struct {
+	name  string
+	title string
+}
+
+// readFocusedApp reads the focused app from source, with the window title
+// if withTitle is set. A missing title only makes title rules not match, so
+// its error isn't logged over the timer on every sample.
+func readFocusedApp(source ActivitySource, withTitle bool) (focusedApp, error) {
+	name, err := source.ForegroundApp()
+	if err != nil {
+		return focusedApp{}, err
+	}
+
+	app := focusedApp{name: name}
+	if withTitle {
+		app.title, _ = source.WindowTitle()
+	}
+	return app, nil
+}
+
+// appRules are the configured work, break or distraction apps
+type appRules []config.AppRule
+
+// match reports whether any rule matches the app
+func (r appRules) match(app focusedApp) bool {
+	for _, rule := range r {
+		if rule.Match(app.name, app.title) {
+			return true
+		}
+	}
+	return false
+}
+
+// needTitle reports whether any rule matches window titles
+func (r appRules) needTitle() bool {
+	for _, rule := range r {
+		if rule.Title {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+package monitor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+// runBreak polls the monitor every 500ms of virtual time for the given
+// duration, like the monitoring goroutine does, and returns the violations
+func runBreak(am *ActivityMonitor, source *scriptedSource, duration time.Duration) []ActivityEvent {
+	startTestBreak(am)
+	for elapsed := time.Duration(0); elapsed < duration; elapsed += 500 * time.Millisecond {
+		source.Advance(500 * time.Millisecond)
+		am.poll()
+	}
+	return am.Violations()
+}
+
+// startTestBreak starts a break without the monitoring goroutine, so the
+// test drives polling itself
+func startTestBreak(am *ActivityMonitor) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.isBreak = true
+	am.violations = nil
+	am.detector.reset()
+}
+
+func newTestActivityMonitor(t *testing.T, source *scriptedSource) *ActivityMonitor {
+	return newTestActivityMonitorWith(source, &config.Config{Monitor: testMonitorConfig(t, "Code", "Terminal")})
+}
+
+func newTestActivityMonitorWith(source *scriptedSource, cfg *config.Config) *ActivityMonitor {
+	am := NewActivityMonitor(cfg, source)
+	am.setClock(source.Now)
+	return am
+}
+
+func TestActivityMonitorViolations(t *testing.T) {
+	tests := []struct {
+		name      string
+		workApps  []string // defaults to Code and Terminal
+		breakApps []string
+		script    []scriptStep
+		duration  time.Duration
+		want      int // violations
+		wantType  ActivityType
+		wantApp   string
+	}{
+		{
+			name:     "idle break",
+			script:   []scriptStep{{at: 0, app: "Code"}},
+			duration: 5 * time.Minute,
+		},
+		{
+			name:     "casual activity under the threshold",
+			script:   []scriptStep{{at: 0, app: "Spotify"}, {at: time.Minute, app: "Spotify", active: true}, {at: time.Minute + 20*time.Second, app: "Spotify"}},
+			duration: 3 * time.Minute,
+		},
+		{
+			name:     "sustained work in a work app",
+			script:   []scriptStep{{at: 0, app: "Code", active: true}},
+			duration: time.Minute,
+			// One per poll once activity seen at 0.5s has lasted 30s
+			want:     60,
+			wantType: AppFocusChange,
+			wantApp:  "Code",
+		},
+		{
+			name:     "sustained activity in another app",
+			script:   []scriptStep{{at: 0, app: "Safari", active: true}},
+			duration: 40 * time.Second,
+			want:     20,
+			wantType: KeyboardActivity,
+			wantApp:  "Safari",
+		},
+		{
+			name:     "names containing a work app are not work apps",
+			script:   []scriptStep{{at: 0, app: "Xcode Previews", active: true}},
+			duration: 31 * time.Second,
+			want:     2,
+			wantType: KeyboardActivity,
+		},
+		{
+			name:     "work app matched case-insensitively",
+			script:   []scriptStep{{at: 0, app: "code", active: true}},
+			duration: 31 * time.Second,
+			want:     2,
+			wantType: AppFocusChange,
+		},
+		{
+			name:     "work app matched by glob",
+			workApps: []string{"*terminal*"},
+			script:   []scriptStep{{at: 0, app: "gnome-terminal-server", active: true}},
+			duration: 31 * time.Second,
+			want:     2,
+			wantType: AppFocusChange,
+		},
+		{
+			name:     "work app matched by regexp",
+			workApps: []string{"/^pgadmin( 4)?$/"},
+			script:   []scriptStep{{at: 0, app: "pgAdmin 4", active: true}},
+			duration: 31 * time.Second,
+			want:     2,
+			wantType: AppFocusChange,
+		},
+		{
+			name:     "work matched by window title",
+			workApps: []string{"title:*- Jira"},
+			script:   []scriptStep{{at: 0, app: "firefox", title: "PROJ-12 Fix login - Jira", active: true}},
+			duration: 31 * time.Second,
+			want:     2,
+			wantType: AppFocusChange,
+			wantApp:  "firefox",
+		},
+		{
+			name:      "break-friendly app",
+			breakApps: []string{"Spotify", "title:/meditation/"},
+			script:    []scriptStep{{at: 0, app: "Spotify", active: true}, {at: time.Minute, app: "firefox", title: "10 minute meditation - YouTube", active: true}},
+			duration:  2 * time.Minute,
+		},
+		{
+			name:      "activity in a break-friendly app doesn't carry over",
+			breakApps: []string{"Spotify"},
+			script:    []scriptStep{{at: 0, app: "Spotify", active: true}, {at: 20 * time.Second, app: "Code", active: true}},
+			// Activity in Code is first seen at 20s and is a violation from 50s
+			duration: 51 * time.Second,
+			want:     3,
+			wantType: AppFocusChange,
+		},
+		{
+			name:     "unknown app",
+			script:   []scriptStep{{at: 0, active: true}},
+			duration: 31 * time.Second,
+			want:     2,
+			wantType: KeyboardActivity,
+			wantApp:  "an unidentified app",
+		},
+		{
+			name:     "locked screen",
+			script:   []scriptStep{{at: 0, app: "Code", active: true, locked: true}},
+			duration: 2 * time.Minute,
+		},
+		{
+			name: "pauses over the idle threshold restart the clock",
+			script: []scriptStep{
+				{at: 0, app: "Code", active: true},
+				{at: 15 * time.Second, app: "Code"},
+				{at: 30 * time.Second, app: "Code", active: true},
+			},
+			duration: 55 * time.Second,
+		},
+		{
+			name: "pauses under the idle threshold count as continuous",
+			script: []scriptStep{
+				{at: 0, app: "Code", active: true},
+				{at: 20 * time.Second, app: "Code"},
+				{at: 25 * time.Second, app: "Code", active: true},
+			},
+			duration: 31 * time.Second,
+			want:     2,
+			wantType: AppFocusChange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workApps := tt.workApps
+			if workApps == nil {
+				workApps = []string{"Code", "Terminal"}
+			}
+			cfg := &config.Config{Monitor: testMonitorConfig(t, workApps...)}
+			cfg.Monitor.BreakApps = testRules(t, tt.breakApps...)
+
+			source := newScriptedSource(tt.script...)
+			violations := runBreak(newTestActivityMonitorWith(source, cfg), source, tt.duration)
+
+			if len(violations) != tt.want {
+				t.Fatalf("violations = %d, want %d: %+v", len(violations), tt.want, violations)
+			}
+			if tt.want == 0 {
+				return
+			}
+			if violations[0].Type != tt.wantType {
+				t.Errorf("type = %v, want %v", violations[0].Type, tt.wantType)
+			}
+			if tt.wantApp != "" && !strings.Contains(violations[0].Details, tt.wantApp) {
+				t.Errorf("details = %q, want it to name %q", violations[0].Details, tt.wantApp)
+			}
+		})
+	}
+}
+
+func TestActivityMonitorThresholds(t *testing.T) {
+	source := newScriptedSource(scriptStep{at: 0, app: "Code", active: true})
+	cfg := &config.Config{Monitor: testMonitorConfig(t, "Code")}
+	cfg.Monitor.ActivityThreshold = 10 * time.Second
+	am := newTestActivityMonitorWith(source, cfg)
+
+	violations := runBreak(am, source, 11*time.Second)
+	if len(violations) != 2 {
+		t.Fatalf("violations = %d, want 2", len(violations))
+	}
+	// Activity is first seen at the first poll, half a second in
+	if got := violations[0].Timestamp.Sub(source.start); got != 10500*time.Millisecond {
+		t.Errorf("first violation after %v, want 10.5s", got)
+	}
+}
+
+func TestActivityMonitorScreenLockRestartsActivity(t *testing.T) {
+	source := newScriptedSource(
+		scriptStep{at: 0, app: "Code", active: true},
+		scriptStep{at: 20 * time.Second, app: "Code", locked: true},
+		scriptStep{at: 25 * time.Second, app: "Code", active: true},
+	)
+
+	violations := runBreak(newTestActivityMonitor(t, source), source, 56*time.Second)
+	if len(violations) != 3 {
+		t.Fatalf("violations = %d, want 3", len(violations))
+	}
+	// Activity before the lock doesn't carry over
+	if got := violations[0].Timestamp.Sub(source.start); got != 55*time.Second {
+		t.Errorf("first violation after %v, want 55s", got)
+	}
+}
+
+func TestActivityMonitorNewBreakRestartsActivity(t *testing.T) {
+	source := newScriptedSource(scriptStep{at: 0, app: "Code", active: true})
+	am := newTestActivityMonitor(t, source)
+
+	if violations := runBreak(am, source, 20*time.Second); len(violations) != 0 {
+		t.Fatalf("first break: violations = %d, want 0", len(violations))
+	}
+	// Without a reset the activity from the first break would count
+	if violations := runBreak(am, source, 20*time.Second); len(violations) != 0 {
+		t.Fatalf("second break: violations = %d, want 0", len(violations))
+	}
+}
+
+func TestActivityMonitorSourceError(t *testing.T) {
+	source := newScriptedSource(scriptStep{at: 0, app: "Code", active: true})
+	source.err = errors.New("no display")
+
+	if violations := runBreak(newTestActivityMonitor(t, source), source, time.Minute); len(violations) != 0 {
+		t.Fatalf("violations = %d, want none while the source fails", len(violations))
+	}
+}
+
+func TestForegroundAppIsCached(t *testing.T) {
+	source := newScriptedSource(scriptStep{at: 0, app: "Safari"}, scriptStep{at: 100 * time.Millisecond, app: "Code"})
+	d := newActivityDetector(source, config.DefaultMonitor(), source.Now)
+
+	first, _ := d.foregroundApp()
+	source.Advance(200 * time.Millisecond)
+	cached, _ := d.foregroundApp()
+	source.Advance(400 * time.Millisecond)
+	fresh, _ := d.foregroundApp()
+
+	if first.name != "Safari" || cached.name != "Safari" || fresh.name != "Code" {
+		t.Errorf("foreground apps = %q, %q, %q, want Safari, Safari (cached), Code", first.name, cached.name, fresh.name)
+	}
+}
@@ -0,0 +1,129 @@
+package monitor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+// scriptStep is what the user does from an offset into the script until the next step
+type scriptStep struct {
+	at     time.Duration
+	app    string // focused app
+	title  string // title of its window
+	active bool   // typing or moving the mouse the whole time
+	locked bool
+}
+
+// scriptedSource replays a script of user activity on a virtual clock.
+// It doubles as the clock of the monitor under test.
+type scriptedSource struct {
+	mu    sync.Mutex
+	start time.Time
+	now   time.Time
+	steps []scriptStep
+	err   error // returned by ForegroundApp when set
+}
+
+func newScriptedSource(steps ...scriptStep) *scriptedSource {
+	start := time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC)
+	return &scriptedSource{start: start, now: start, steps: steps}
+}
+
+// Now is the virtual time
+func (s *scriptedSource) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// Advance moves the virtual clock forward
+func (s *scriptedSource) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+}
+
+// current returns the step in effect and when it started; s.mu must be held
+func (s *scriptedSource) current() (scriptStep, int) {
+	elapsed := s.now.Sub(s.start)
+	index := -1
+	for i, step := range s.steps {
+		if step.at <= elapsed {
+			index = i
+		}
+	}
+	if index < 0 {
+		return scriptStep{}, -1
+	}
+	return s.steps[index], index
+}
+
+func (s *scriptedSource) IdleTime() (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	step, index := s.current()
+	if step.active {
+		return 0, nil
+	}
+
+	// Idle since the last active step ended, or since the script started
+	lastInput := s.start
+	for i := index; i > 0; i-- {
+		if s.steps[i-1].active {
+			lastInput = s.start.Add(s.steps[i].at)
+			break
+		}
+	}
+	return s.now.Sub(lastInput), nil
+}
+
+func (s *scriptedSource) ForegroundApp() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return "", s.err
+	}
+	step, _ := s.current()
+	return step.app, nil
+}
+
+func (s *scriptedSource) WindowTitle() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return "", s.err
+	}
+	step, _ := s.current()
+	return step.title, nil
+}
+
+func (s *scriptedSource) ScreenLocked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	step, _ := s.current()
+	return step.locked
+}
+
+// testMonitorConfig is the default monitor configuration with the given work apps
+func testMonitorConfig(t *testing.T, workApps ...string) config.Monitor {
+	t.Helper()
+	cfg := config.DefaultMonitor()
+	cfg.WorkApps = testRules(t, workApps...)
+	return cfg
+}
+
+func testRules(t *testing.T, patterns ...string) []config.AppRule {
+	t.Helper()
+	rules, err := config.ParseAppRules(patterns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules
+}
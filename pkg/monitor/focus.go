@@ -0,0 +1,246 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+const (
+	// defaultFocusSampleInterval is how often the focus tracker samples the source
+	defaultFocusSampleInterval = 2 * time.Second
+
+	// maxListedDistractions caps the apps listed in the summary
+	maxListedDistractions = 5
+)
+
+// focusCategory is where a stretch of focus time went
+type focusCategory int
+
+const (
+	focusWork focusCategory = iota
+	focusDistracted
+	focusIdle
+	focusOther
+)
+
+// FocusSummary is the measured use of the focus time in one cycle
+type FocusSummary struct {
+	Tracked         time.Duration
+	Work            time.Duration // in work apps
+	Distracted      time.Duration // in distracting apps
+	Idle            time.Duration // idle or locked
+	Other           time.Duration // in other or unidentified apps
+	DistractionApps map[string]time.Duration
+	Switches        int // times focus moved to a distracting app
+}
+
+// FocusTracker measures how the focus time of a work session is spent:
+// in work apps, in distracting apps or away from the keyboard
+type FocusTracker struct {
+	mu sync.Mutex
+
+	source          ActivitySource
+	now             func() time.Time
+	workApps        appRules
+	distractionApps appRules // empty counts every identified non-work app
+	idleThreshold   time.Duration
+	sampleInterval  time.Duration
+
+	running      bool
+	paused       bool
+	lastSample   time.Time
+	lastCategory focusCategory
+	summary      FocusSummary
+	stopChan     chan struct{}
+}
+
+// NewFocusTracker creates a focus tracker that reads user activity from source
+func NewFocusTracker(source ActivitySource, cfg config.Monitor) *FocusTracker {
+	return &FocusTracker{
+		source:          source,
+		now:             time.Now,
+		workApps:        cfg.WorkApps,
+		distractionApps: cfg.DistractionApps,
+		idleThreshold:   cfg.FocusIdleThreshold,
+		sampleInterval:  defaultFocusSampleInterval,
+	}
+}
+
+// Start starts tracking a new focus session
+func (ft *FocusTracker) Start() {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+
+	if ft.running {
+		close(ft.stopChan)
+	}
+	ft.running = true
+	ft.paused = false
+	ft.lastSample = ft.now()
+	ft.lastCategory = focusWork
+	ft.summary = FocusSummary{DistractionApps: make(map[string]time.Duration)}
+	ft.stopChan = make(chan struct{})
+	go ft.track(ft.stopChan)
+}
+
+// Pause stops counting time while the timer is paused, and resumes when
+// paused is false
+func (ft *FocusTracker) Pause(paused bool) {
+	if paused {
+		ft.sample()
+	}
+
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	ft.paused = paused
+	ft.lastSample = ft.now()
+}
+
+// Stop ends the focus session and returns its summary
+func (ft *FocusTracker) Stop() FocusSummary {
+	ft.sample()
+
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	if ft.running {
+		close(ft.stopChan)
+		ft.running = false
+	}
+	return ft.summary
+}
+
+func (ft *FocusTracker) track(stop chan struct{}) {
+	ticker := time.NewTicker(ft.sampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ft.sample()
+		}
+	}
+}
+
+// sample attributes the time since the last sample to what the user is
+// doing now
+func (ft *FocusTracker) sample() {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+
+	if !ft.running || ft.paused {
+		return
+	}
+
+	now := ft.now()
+	elapsed := now.Sub(ft.lastSample)
+	ft.lastSample = now
+	if elapsed <= 0 {
+		return
+	}
+
+	category, app := ft.classify()
+	if category == focusDistracted && ft.lastCategory != focusDistracted {
+		ft.summary.Switches++
+	}
+	ft.lastCategory = category
+
+	ft.summary.Tracked += elapsed
+	switch category {
+	case focusWork:
+		ft.summary.Work += elapsed
+	case focusDistracted:
+		ft.summary.Distracted += elapsed
+		ft.summary.DistractionApps[app] += elapsed
+	case focusIdle:
+		ft.summary.Idle += elapsed
+	default:
+		ft.summary.Other += elapsed
+	}
+}
+
+// classify samples the source; ft.mu must be held
+func (ft *FocusTracker) classify() (focusCategory, string) {
+	if ft.source.ScreenLocked() {
+		return focusIdle, ""
+	}
+	if idle, err := ft.source.IdleTime(); err == nil && idle >= ft.idleThreshold {
+		return focusIdle, ""
+	}
+
+	// A failure is counted like an unknown app, without logging: the work
+	// timer owns the terminal and a persistent error would flood it
+	app, err := readFocusedApp(ft.source, ft.workApps.needTitle() || ft.distractionApps.needTitle())
+	if err != nil {
+		return focusOther, ""
+	}
+
+	switch {
+	case ft.workApps.match(app):
+		return focusWork, app.name
+	case app.name == "":
+		return focusOther, ""
+	case len(ft.distractionApps) == 0 || ft.distractionApps.match(app):
+		return focusDistracted, app.name
+	default:
+		return focusOther, app.name
+	}
+}
+
+// String describes the summary for the progress analysis
+func (s FocusSummary) String() string {
+	if s.Tracked <= 0 {
+		return "No focus time was tracked."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Focus time tracked: %s\n", s.Tracked.Round(time.Second))
+	fmt.Fprintf(&b, "- In work apps: %s\n", s.share(s.Work))
+
+	fmt.Fprintf(&b, "- In non-work apps: %s", s.share(s.Distracted))
+	if apps := s.topDistractions(); len(apps) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(apps, ", "))
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "- Idle or away: %s\n", s.share(s.Idle))
+	if s.Other > 0 {
+		fmt.Fprintf(&b, "- In other or unidentified apps: %s\n", s.share(s.Other))
+	}
+	fmt.Fprintf(&b, "- Switched to a non-work app %d times", s.Switches)
+
+	return b.String()
+}
+
+// share formats a duration with its percentage of the tracked time
+func (s FocusSummary) share(d time.Duration) string {
+	return fmt.Sprintf("%s (%d%%)", d.Round(time.Second), int(d*100/s.Tracked))
+}
+
+// topDistractions lists the apps with the most distracted time first
+func (s FocusSummary) topDistractions() []string {
+	apps := make([]string, 0, len(s.DistractionApps))
+	for app := range s.DistractionApps {
+		apps = append(apps, app)
+	}
+	sort.Slice(apps, func(i, j int) bool {
+		if s.DistractionApps[apps[i]] != s.DistractionApps[apps[j]] {
+			return s.DistractionApps[apps[i]] > s.DistractionApps[apps[j]]
+		}
+		return apps[i] < apps[j]
+	})
+	if len(apps) > maxListedDistractions {
+		apps = apps[:maxListedDistractions]
+	}
+
+	for i, app := range apps {
+		apps[i] = fmt.Sprintf("%s %s", app, s.DistractionApps[app].Round(time.Second))
+	}
+	return apps
+}
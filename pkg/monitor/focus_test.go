@@ -0,0 +1,143 @@
+package monitor
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTestFocus starts tracking without the sampling goroutine, so the
+// test drives sampling itself
+func startTestFocus(t *testing.T, source *scriptedSource, distractionApps ...string) *FocusTracker {
+	cfg := testMonitorConfig(t, "Code", "Terminal")
+	cfg.DistractionApps = testRules(t, distractionApps...)
+	ft := NewFocusTracker(source, cfg)
+	ft.now = source.Now
+	ft.Start()
+	close(ft.stopChan)
+	ft.stopChan = make(chan struct{})
+	return ft
+}
+
+// runFocus samples every 2s of virtual time for the given duration
+func runFocus(ft *FocusTracker, source *scriptedSource, duration time.Duration) {
+	for elapsed := time.Duration(0); elapsed < duration; elapsed += 2 * time.Second {
+		source.Advance(2 * time.Second)
+		ft.sample()
+	}
+}
+
+func TestFocusTrackerSummary(t *testing.T) {
+	source := newScriptedSource(
+		scriptStep{at: 0, app: "Code", active: true},
+		scriptStep{at: 10 * time.Minute, app: "Slack", active: true},
+		scriptStep{at: 12 * time.Minute, app: "Code", active: true},
+		scriptStep{at: 15 * time.Minute, app: "Code"},
+		scriptStep{at: 20 * time.Minute, app: "Safari", active: true},
+		scriptStep{at: 21 * time.Minute, app: "Code", active: true, locked: true},
+		scriptStep{at: 22 * time.Minute, active: true},
+	)
+	ft := startTestFocus(t, source)
+	runFocus(ft, source, 25*time.Minute)
+	summary := ft.Stop()
+
+	// A sample counts the 2s before it, so the first step loses 2s to the
+	// samples and the last one gains them
+	want := FocusSummary{
+		Tracked: 25 * time.Minute,
+		// Reading without input for up to a minute still counts as work
+		Work:       10*time.Minute + 3*time.Minute + time.Minute - 2*time.Second,
+		Distracted: 3 * time.Minute,
+		Idle:       4*time.Minute + time.Minute,
+		Other:      3*time.Minute + 2*time.Second,
+		Switches:   2,
+	}
+	if summary.Tracked != want.Tracked || summary.Work != want.Work || summary.Distracted != want.Distracted ||
+		summary.Idle != want.Idle || summary.Other != want.Other || summary.Switches != want.Switches {
+		t.Fatalf("summary = %+v, want %+v", summary, want)
+	}
+	if summary.DistractionApps["Slack"] != 2*time.Minute || summary.DistractionApps["Safari"] != time.Minute {
+		t.Errorf("distraction apps = %v, want Slack 2m and Safari 1m", summary.DistractionApps)
+	}
+
+	text := summary.String()
+	for _, line := range []string{
+		"Focus time tracked: 25m0s",
+		"In work apps: 13m58s (55%)",
+		"In non-work apps: 3m0s (12%) (Slack 2m0s, Safari 1m0s)",
+		"Idle or away: 5m0s (20%)",
+		"In other or unidentified apps: 3m2s (12%)",
+		"Switched to a non-work app 2 times",
+	} {
+		if !strings.Contains(text, line) {
+			t.Errorf("summary text is missing %q:\n%s", line, text)
+		}
+	}
+}
+
+func TestFocusTrackerDistractionApps(t *testing.T) {
+	source := newScriptedSource(
+		scriptStep{at: 0, app: "Slack", active: true},
+		scriptStep{at: time.Minute, app: "Figma", active: true},
+	)
+	ft := startTestFocus(t, source, "slack")
+	runFocus(ft, source, 2*time.Minute)
+	summary := ft.Stop()
+
+	// Only the configured apps are distractions, matched like work apps
+	if summary.Distracted != time.Minute-2*time.Second || summary.Other != time.Minute+2*time.Second {
+		t.Errorf("distracted = %v, other = %v, want 58s and 1m2s", summary.Distracted, summary.Other)
+	}
+}
+
+func TestFocusTrackerPause(t *testing.T) {
+	source := newScriptedSource(scriptStep{at: 0, app: "Safari", active: true})
+	ft := startTestFocus(t, source)
+
+	runFocus(ft, source, time.Minute)
+	ft.Pause(true)
+	runFocus(ft, source, 10*time.Minute)
+	ft.Pause(false)
+	runFocus(ft, source, time.Minute)
+	source.Advance(time.Second)
+	summary := ft.Stop()
+
+	if summary.Tracked != 2*time.Minute+time.Second || summary.Distracted != summary.Tracked {
+		t.Errorf("tracked = %v, distracted = %v, want 2m1s without the pause", summary.Tracked, summary.Distracted)
+	}
+	if summary.Switches != 1 {
+		t.Errorf("switches = %d, want 1", summary.Switches)
+	}
+	if ft.Stop().Tracked != summary.Tracked {
+		t.Error("time counted after the session ended")
+	}
+}
+
+func TestFocusSummaryWithoutTracking(t *testing.T) {
+	if got := (FocusSummary{}).String(); got != "No focus time was tracked." {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestFocusTrackerSourceErrorIsSilent(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	source := newScriptedSource(scriptStep{at: 0, app: "Code", active: true})
+	source.err = errors.New("osascript failed")
+	ft := startTestFocus(t, source)
+	runFocus(ft, source, time.Minute)
+	summary := ft.Stop()
+
+	if summary.Other != time.Minute || summary.Work != 0 {
+		t.Errorf("summary = %+v, want the whole minute as other", summary)
+	}
+	if logged.Len() > 0 {
+		t.Errorf("logged while the timer owns the terminal: %q", logged.String())
+	}
+}
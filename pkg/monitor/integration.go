@@ -1,61 +1,96 @@
 package monitor
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/1x-eng/tomatick/config"
 	"github.com/1x-eng/tomatick/pkg/llm"
+	"github.com/1x-eng/tomatick/pkg/notify"
+	"github.com/1x-eng/tomatick/pkg/webhook"
 )
 
 // TomatickMonitor provides a high-level interface for monitoring Tomatick breaks
 type TomatickMonitor struct {
-	activityMonitor  *ActivityMonitor
-	notificationMgr  *NotificationManager
-	config           *config.Config
+	activityMonitor *ActivityMonitor
+	focusTracker    *FocusTracker
+	notificationMgr *NotificationManager
+	config          *config.Config
+	notifyThreshold time.Duration
+	dispatcher      webhook.Dispatcher
+	now             func() time.Time
+
+	// checkMu serializes violation checks, which own the notification state
+	checkMu          sync.Mutex
 	lastNotification time.Time
-	notifyThreshold  time.Duration
+	lastReported     time.Time
 }
 
-// NewTomatickMonitor creates a new TomatickMonitor instance
-func NewTomatickMonitor(cfg *config.Config, llmClient *llm.PerplexityAI) (*TomatickMonitor, error) {
-	if err := InitializeMonitoring(cfg); err != nil {
+// NewTomatickMonitor creates a new TomatickMonitor instance backed by this
+// operating system's activity source
+func NewTomatickMonitor(cfg *config.Config, llmClient *llm.PerplexityAI, notifier notify.Notifier, dispatcher webhook.Dispatcher) (*TomatickMonitor, error) {
+	source, err := NewPlatformSource(cfg)
+	if err != nil {
 		return nil, fmt.Errorf("failed to initialize monitoring: %w", err)
 	}
 
+	return NewTomatickMonitorWithSource(cfg, source, llmClient, notifier, dispatcher), nil
+}
+
+// NewTomatickMonitorWithSource creates a TomatickMonitor that reads activity
+// from source, writes notifications with responder and sends them to notifier
+func NewTomatickMonitorWithSource(cfg *config.Config, source ActivitySource, responder Responder, notifier notify.Notifier, dispatcher webhook.Dispatcher) *TomatickMonitor {
 	return &TomatickMonitor{
-		activityMonitor: NewActivityMonitor(cfg, llmClient),
-		notificationMgr: NewNotificationManager(llmClient, cfg.UserName),
+		activityMonitor: NewActivityMonitor(cfg, source),
+		focusTracker:    NewFocusTracker(source, cfg.Monitor),
+		notificationMgr: NewNotificationManager(responder, notifier, cfg.UserName),
 		config:          cfg,
-		notifyThreshold: 60 * time.Second, // Increase threshold to 60 seconds between notifications
-	}, nil
+		notifyThreshold: cfg.Monitor.NotifyInterval,
+		dispatcher:      dispatcher,
+		now:             time.Now,
+	}
 }
 
-// OnBreakStart should be called when a Tomatick break starts
-func (tm *TomatickMonitor) OnBreakStart() {
-	tm.activityMonitor.StartBreak()
-	log.Println("Break monitoring started")
+// setClock replaces the clock of the monitor and its activity monitor
+func (tm *TomatickMonitor) setClock(now func() time.Time) {
+	tm.now = now
+	tm.activityMonitor.setClock(now)
+	tm.focusTracker.now = now
 }
 
-// OnBreakEnd should be called when a Tomatick break ends
-func (tm *TomatickMonitor) OnBreakEnd() BreakSummary {
-	violations := tm.activityMonitor.EndBreak()
+// OnFocusStart should be called when a work session starts, to track how
+// its focus time is spent
+func (tm *TomatickMonitor) OnFocusStart() {
+	tm.focusTracker.Start()
+}
 
-	if len(violations) == 0 {
-		return BreakSummary{
-			HasViolations: false,
-			Message:       "Great job taking a proper break!",
-		}
-	}
+// OnFocusPause should be called when the work timer is paused or resumed
+func (tm *TomatickMonitor) OnFocusPause(paused bool) {
+	tm.focusTracker.Pause(paused)
+}
 
-	// Return summary immediately without additional notification
-	return BreakSummary{
-		HasViolations: true,
-		ViolationDetails: fmt.Sprintf("Break ended with %d violations:\n%s",
-			len(violations),
-			tm.activityMonitor.GetViolationSummary()),
-	}
+// OnFocusEnd should be called when a work session ends and returns how
+// its focus time was spent
+func (tm *TomatickMonitor) OnFocusEnd() FocusSummary {
+	return tm.focusTracker.Stop()
+}
+
+// OnBreakStart should be called when a Tomatick break starts. Activity is
+// monitored until OnBreakEnd is called or ctx is cancelled.
+func (tm *TomatickMonitor) OnBreakStart(ctx context.Context) {
+	tm.activityMonitor.StartBreak(ctx)
+	log.Println("Break monitoring started")
+}
+
+// OnBreakEnd should be called when a Tomatick break ends and returns the
+// violations seen during the break
+func (tm *TomatickMonitor) OnBreakEnd() BreakSummary {
+	return BreakSummary{Violations: tm.activityMonitor.EndBreak()}
 }
 
 // CheckBreakViolations checks for break violations and returns a notification if needed
@@ -64,16 +99,21 @@ func (tm *TomatickMonitor) CheckBreakViolations() *string {
 		return nil
 	}
 
+	tm.checkMu.Lock()
+	defer tm.checkMu.Unlock()
+
 	// Check if enough time has passed since last notification
-	if time.Since(tm.lastNotification) < tm.notifyThreshold {
+	if tm.now().Sub(tm.lastNotification) < tm.notifyThreshold {
 		return nil
 	}
 
-	violations := tm.activityMonitor.violations
+	violations := tm.activityMonitor.Violations()
 	if len(violations) == 0 {
 		return nil
 	}
 
+	tm.reportViolations(violations)
+
 	// Only notify if there are new violations since last check
 	lastViolationTime := violations[len(violations)-1].Timestamp
 	if !lastViolationTime.After(tm.lastNotification) {
@@ -91,13 +131,77 @@ func (tm *TomatickMonitor) CheckBreakViolations() *string {
 		return nil
 	}
 
-	tm.lastNotification = time.Now()
+	tm.lastNotification = tm.now()
 	return &notification
 }
 
-// BreakSummary contains information about a completed break
+// reportViolations dispatches the violations seen since the last report,
+// at most once per notification threshold. checkMu must be held.
+func (tm *TomatickMonitor) reportViolations(violations []ActivityEvent) {
+	if tm.dispatcher == nil || tm.now().Sub(tm.lastReported) < tm.notifyThreshold {
+		return
+	}
+
+	var fresh []ActivityEvent
+	for _, v := range violations {
+		if v.Timestamp.After(tm.lastReported) {
+			fresh = append(fresh, v)
+		}
+	}
+	if len(fresh) == 0 {
+		return
+	}
+
+	details := make([]string, len(fresh))
+	for i, v := range fresh {
+		details[i] = v.Details
+	}
+
+	tm.dispatcher.Dispatch(webhook.BreakViolation{
+		ViolationsCount: len(fresh),
+		FirstAt:         fresh[0].Timestamp,
+		LastAt:          fresh[len(fresh)-1].Timestamp,
+		Details:         details,
+	})
+	tm.lastReported = tm.now()
+}
+
+// BreakSummary contains the violations of a completed break
 type BreakSummary struct {
-	HasViolations    bool
-	Message          string
-	ViolationDetails string
+	Violations []ActivityEvent
+}
+
+// HasViolations reports whether the break was interrupted by activity
+func (s BreakSummary) HasViolations() bool {
+	return len(s.Violations) > 0
+}
+
+// Apps returns the apps used during the break, sorted by name. Activity in
+// an unidentified app is not listed.
+func (s BreakSummary) Apps() []string {
+	seen := make(map[string]bool)
+	var apps []string
+	for _, v := range s.Violations {
+		if v.App != "" && !seen[v.App] {
+			seen[v.App] = true
+			apps = append(apps, v.App)
+		}
+	}
+	sort.Strings(apps)
+	return apps
+}
+
+// String describes the violations for display
+func (s BreakSummary) String() string {
+	if !s.HasViolations() {
+		return "Great job taking a proper break!"
+	}
+
+	first, last := s.Violations[0].Timestamp, s.Violations[len(s.Violations)-1].Timestamp
+	where := ""
+	if apps := s.Apps(); len(apps) > 0 {
+		where = " in " + strings.Join(apps, ", ")
+	}
+	return fmt.Sprintf("Break interrupted by activity%s from %s to %s (%d violations)",
+		where, first.Format("15:04:05"), last.Format("15:04:05"), len(s.Violations))
 }
@@ -0,0 +1,238 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+	"github.com/1x-eng/tomatick/pkg/llm"
+	"github.com/1x-eng/tomatick/pkg/notify"
+	"github.com/1x-eng/tomatick/pkg/webhook"
+)
+
+// fakeResponder stands in for the LLM
+type fakeResponder struct {
+	calls int
+	err   error
+}
+
+func (r *fakeResponder) GetResponse(messages []llm.Message) (string, error) {
+	r.calls++
+	if r.err != nil {
+		return "", r.err
+	}
+	return "<think>planning</think>Alex, roll your shoulders and look out of the window.", nil
+}
+
+// recordingNotifier keeps the notifications instead of showing them
+type recordingNotifier struct {
+	notifications []notify.Notification
+}
+
+func (n *recordingNotifier) Notify(notification notify.Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+// recordingDispatcher keeps the events instead of sending them
+type recordingDispatcher struct {
+	mu     sync.Mutex
+	events []webhook.Event
+}
+
+func (d *recordingDispatcher) Dispatch(event webhook.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.events = append(d.events, event)
+}
+
+func (d *recordingDispatcher) SessionID() string { return "test" }
+func (d *recordingDispatcher) SetCycle(int)      {}
+func (d *recordingDispatcher) Wait()             {}
+func (d *recordingDispatcher) Shutdown(time.Duration) []webhook.DeadLetter {
+	return nil
+}
+
+func (d *recordingDispatcher) violations() []webhook.BreakViolation {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var violations []webhook.BreakViolation
+	for _, event := range d.events {
+		if v, ok := event.(webhook.BreakViolation); ok {
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+func newTestTomatickMonitor(t *testing.T, source *scriptedSource, responder Responder, notifier notify.Notifier, dispatcher webhook.Dispatcher) *TomatickMonitor {
+	cfg := &config.Config{UserName: "Alex", Monitor: testMonitorConfig(t, "Code")}
+	tm := NewTomatickMonitorWithSource(cfg, source, responder, notifier, dispatcher)
+	tm.setClock(source.Now)
+	return tm
+}
+
+// advance polls the activity monitor every 500ms of virtual time
+func advance(tm *TomatickMonitor, source *scriptedSource, duration time.Duration) {
+	for elapsed := time.Duration(0); elapsed < duration; elapsed += 500 * time.Millisecond {
+		source.Advance(500 * time.Millisecond)
+		tm.activityMonitor.poll()
+	}
+}
+
+func TestCheckBreakViolationsThrottlesNotifications(t *testing.T) {
+	source := newScriptedSource(
+		scriptStep{at: 0, app: "Code", active: true},
+		scriptStep{at: 3 * time.Minute, app: "Code"},
+	)
+	responder := &fakeResponder{}
+	notifier := &recordingNotifier{}
+	dispatcher := &recordingDispatcher{}
+	tm := newTestTomatickMonitor(t, source, responder, notifier, dispatcher)
+
+	if tm.CheckBreakViolations() != nil {
+		t.Fatal("notification outside a break")
+	}
+
+	startTestBreak(tm.activityMonitor)
+	advance(tm, source, 20*time.Second)
+	if tm.CheckBreakViolations() != nil || responder.calls != 0 {
+		t.Fatal("notification before any violation")
+	}
+
+	// First violations at 30.5s
+	advance(tm, source, 11*time.Second)
+	if tm.CheckBreakViolations() == nil || responder.calls != 1 {
+		t.Fatalf("no notification for the first violations, responder calls = %d", responder.calls)
+	}
+	if got := dispatcher.violations(); len(got) != 1 || got[0].ViolationsCount != 2 {
+		t.Fatalf("break_violation events = %+v, want one with 2 violations", got)
+	}
+
+	// Within the 60s notification threshold
+	advance(tm, source, 30*time.Second)
+	if tm.CheckBreakViolations() != nil || responder.calls != 1 {
+		t.Fatalf("notified again within the threshold, responder calls = %d", responder.calls)
+	}
+
+	// Past the threshold with new violations; only those are reported
+	advance(tm, source, 31*time.Second)
+	if tm.CheckBreakViolations() == nil || responder.calls != 2 {
+		t.Fatalf("no second notification, responder calls = %d", responder.calls)
+	}
+	got := dispatcher.violations()
+	if len(got) != 2 || got[1].ViolationsCount != 122 {
+		t.Fatalf("break_violation events = %d, second with %d violations, want 122 new ones", len(got), got[len(got)-1].ViolationsCount)
+	}
+	if got[1].FirstAt.Before(got[0].LastAt) || got[1].FirstAt.Equal(got[0].LastAt) {
+		t.Errorf("second report starts at %v, before the first ended at %v", got[1].FirstAt, got[0].LastAt)
+	}
+
+	// The user stops at 3 minutes; the violations up to then are reported once
+	advance(tm, source, 2*time.Minute)
+	if tm.CheckBreakViolations() == nil || responder.calls != 3 {
+		t.Fatalf("no notification for the last violations, responder calls = %d", responder.calls)
+	}
+	advance(tm, source, 2*time.Minute)
+	if tm.CheckBreakViolations() != nil || responder.calls != 3 {
+		t.Fatalf("notified without new violations, responder calls = %d", responder.calls)
+	}
+	if len(dispatcher.violations()) != 3 {
+		t.Errorf("break_violation events = %d, want 3", len(dispatcher.violations()))
+	}
+
+	// Every notification also goes to the desktop, replacing the last one
+	if len(notifier.notifications) != 3 {
+		t.Fatalf("desktop notifications = %d, want 3", len(notifier.notifications))
+	}
+	for _, n := range notifier.notifications {
+		if n.Tag != "break-violation" || !strings.HasPrefix(n.Body, "Alex, roll your shoulders") {
+			t.Errorf("desktop notification = %+v, want the cleaned reminder tagged break-violation", n)
+		}
+	}
+}
+
+func TestCheckBreakViolationsFallsBackWithoutLLM(t *testing.T) {
+	source := newScriptedSource(scriptStep{at: 0, app: "Code", active: true})
+	notifier := &recordingNotifier{}
+	tm := newTestTomatickMonitor(t, source, &fakeResponder{err: errors.New("offline")}, notifier, nil)
+
+	startTestBreak(tm.activityMonitor)
+	advance(tm, source, 31*time.Second)
+
+	notification := tm.CheckBreakViolations()
+	if notification == nil {
+		t.Fatal("no notification")
+	}
+	if !strings.Contains(*notification, "Consistent breaks are essential") {
+		t.Errorf("notification = %q, want the default text", *notification)
+	}
+	if len(notifier.notifications) != 1 || notifier.notifications[0].Body != *notification {
+		t.Errorf("desktop notifications = %+v, want the default text", notifier.notifications)
+	}
+}
+
+func TestReportViolations(t *testing.T) {
+	source := newScriptedSource()
+	start := source.Now()
+	at := func(seconds int) ActivityEvent {
+		return ActivityEvent{Timestamp: start.Add(time.Duration(seconds) * time.Second), App: "Code", Details: fmt.Sprintf("Typing at %ds", seconds)}
+	}
+
+	tests := []struct {
+		name          string
+		neverReported bool
+		lastReported  time.Duration // relative to the report
+		violations    []ActivityEvent
+		want          []string // details of the dispatched event; nil means nothing sent
+	}{
+		{name: "first report", neverReported: true, violations: []ActivityEvent{at(-30), at(-20)},
+			want: []string{"Typing at -30s", "Typing at -20s"}},
+		{name: "only violations since the last report", lastReported: -2 * time.Minute,
+			violations: []ActivityEvent{at(-150), at(-120), at(-30)}, want: []string{"Typing at -30s"}},
+		{name: "nothing new", lastReported: -2 * time.Minute, violations: []ActivityEvent{at(-150), at(-120)}},
+		{name: "within the threshold", lastReported: -30 * time.Second, violations: []ActivityEvent{at(-10)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dispatcher := &recordingDispatcher{}
+			tm := newTestTomatickMonitor(t, source, &fakeResponder{}, &recordingNotifier{}, dispatcher)
+			tm.notifyThreshold = time.Minute
+			if !tt.neverReported {
+				tm.lastReported = start.Add(tt.lastReported)
+			}
+
+			tm.reportViolations(tt.violations)
+
+			got := dispatcher.violations()
+			if tt.want == nil {
+				if len(got) != 0 {
+					t.Errorf("dispatched %+v, want nothing", got)
+				}
+				return
+			}
+			if len(dispatcher.events) != 1 || len(got) != 1 {
+				t.Fatalf("events = %+v, want one break_violation", dispatcher.events)
+			}
+			v := got[0]
+			if v.ViolationsCount != len(tt.want) || !reflect.DeepEqual(v.Details, tt.want) ||
+				!v.FirstAt.Equal(tt.violations[len(tt.violations)-len(tt.want)].Timestamp) ||
+				!v.LastAt.Equal(tt.violations[len(tt.violations)-1].Timestamp) {
+				t.Errorf("break_violation = %+v, want details %v", v, tt.want)
+			}
+			if !tm.lastReported.Equal(start) {
+				t.Errorf("lastReported = %v, want the report time %v", tm.lastReported, start)
+			}
+		})
+	}
+
+	// Without webhooks there is nothing to report to
+	tm := newTestTomatickMonitor(t, source, &fakeResponder{}, &recordingNotifier{}, nil)
+	tm.reportViolations([]ActivityEvent{at(-10)})
+}
@@ -2,11 +2,13 @@ package monitor
 
 import (
 	"fmt"
+	"log"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/1x-eng/tomatick/pkg/llm"
+	"github.com/1x-eng/tomatick/pkg/notify"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -52,17 +54,28 @@ type BreakViolation struct {
 	EndTime   time.Time
 }
 
+// Responder answers a chat conversation; *llm.PerplexityAI implements it
+type Responder interface {
+	GetResponse(messages []llm.Message) (string, error)
+}
+
 // NotificationManager handles generating appropriate notifications for break violations
 type NotificationManager struct {
-	llmClient           *llm.PerplexityAI
+	llmClient           Responder
+	notifier            notify.Notifier
 	userName            string
 	breakViolationCount int
 }
 
-// NewNotificationManager creates a new notification manager
-func NewNotificationManager(llmClient *llm.PerplexityAI, userName string) *NotificationManager {
+// NewNotificationManager creates a new notification manager that also sends
+// its notifications to notifier
+func NewNotificationManager(llmClient Responder, notifier notify.Notifier, userName string) *NotificationManager {
+	if notifier == nil {
+		notifier = notify.Nop{}
+	}
 	return &NotificationManager{
 		llmClient:           llmClient,
+		notifier:            notifier,
 		userName:            userName,
 		breakViolationCount: 0,
 	}
@@ -88,9 +101,8 @@ func createViolationContext(violation BreakViolation) string {
 	activityDuration := time.Since(violation.StartTime)
 
 	for _, event := range violation.Events {
-		if event.Type == AppFocusChange {
-			appName := strings.TrimPrefix(event.Details, "Work-related app detected: ")
-			apps[appName] = true
+		if event.Type == AppFocusChange && event.App != "" {
+			apps[event.App] = true
 		}
 		if event.Type == KeyboardActivity {
 			hasKeyboardActivity = true
@@ -181,16 +193,33 @@ Make it sound natural and conversational, not clinical.`, nm.userName, nm.breakV
 
 	response, err := nm.llmClient.GetResponse(messages)
 	if err != nil {
-		return getDefaultNotification(violation), nil
+		fallback := getDefaultNotification(violation)
+		nm.sendNotification(fallback)
+		return fallback, nil
 	}
 
 	cleaned := cleanResponse(response)
 	message := fmt.Sprintf("%s\n\nViolation count: %d", cleaned, nm.breakViolationCount)
 
 	displayNotification(message)
+	nm.sendNotification(cleaned)
 	return "", nil
 }
 
+// sendNotification sends a break reminder to the desktop or terminal,
+// replacing the previous one
+func (nm *NotificationManager) sendNotification(body string) {
+	err := nm.notifier.Notify(notify.Notification{
+		Title:   "🔔 Break Time",
+		Body:    body,
+		Urgency: notify.Normal,
+		Tag:     "break-violation",
+	})
+	if err != nil {
+		log.Printf("Error sending break notification: %v", err)
+	}
+}
+
 // getDefaultNotification returns a data-driven default notification if LLM fails
 func getDefaultNotification(violation BreakViolation) string {
 	context := createViolationContext(violation)
@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+// The OS-specific backends (activity_darwin.go, activity_linux.go and
+// activity_other.go) provide these primitives:
+//
+//	platformInit() error
+//	platformForegroundApp() (string, error)
+//	platformWindowTitle() (string, error)
+//	platformIdleTime() (time.Duration, error)
+//	platformScreenLocked() bool
+
+// platformSource is the ActivitySource of the operating system
+type platformSource struct{}
+
+// NewPlatformSource sets up the activity backend of this operating system
+func NewPlatformSource(cfg *config.Config) (ActivitySource, error) {
+	// Only proceed if break monitoring is enabled
+	if !cfg.Features.BreakMonitoring {
+		return nil, fmt.Errorf("break monitoring is not supported on this operating system")
+	}
+
+	if err := platformInit(); err != nil {
+		return nil, err
+	}
+	return platformSource{}, nil
+}
+
+func (platformSource) IdleTime() (time.Duration, error) {
+	return platformIdleTime()
+}
+
+func (platformSource) ForegroundApp() (string, error) {
+	return platformForegroundApp()
+}
+
+func (platformSource) WindowTitle() (string, error) {
+	return platformWindowTitle()
+}
+
+func (platformSource) ScreenLocked() bool {
+	return platformScreenLocked()
+}
@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dbusTimeout bounds each call to the notification daemon
+const dbusTimeout = 5 * time.Second
+
+// notifyReply matches the notification id in the reply to Notify, "(uint32 7,)"
+var notifyReply = regexp.MustCompile(`uint32 (\d+)`)
+
+// DBus sends notifications to the desktop's org.freedesktop.Notifications
+// service on the session bus, through gdbus
+type DBus struct {
+	appName string
+	call    func(ctx context.Context, args ...string) ([]byte, error)
+
+	mu  sync.Mutex
+	ids map[string]uint32 // last notification id per tag, to replace it
+}
+
+// NewDBus connects to the notification daemon of the session. It fails
+// without a session bus, gdbus or a running daemon.
+func NewDBus() (*DBus, error) {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+		if _, err := os.Stat(filepath.Join(runtimeDir, "bus")); runtimeDir == "" || err != nil {
+			return nil, fmt.Errorf("no D-Bus session bus")
+		}
+	}
+	gdbus, err := exec.LookPath("gdbus")
+	if err != nil {
+		return nil, fmt.Errorf("gdbus not found: %w", err)
+	}
+
+	d := newDBus(func(ctx context.Context, args ...string) ([]byte, error) {
+		return exec.CommandContext(ctx, gdbus, args...).Output()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbusTimeout)
+	defer cancel()
+	if _, err := d.call(ctx, d.method("GetServerInformation")...); err != nil {
+		return nil, fmt.Errorf("no notification daemon on the session bus: %w", err)
+	}
+	return d, nil
+}
+
+func newDBus(call func(ctx context.Context, args ...string) ([]byte, error)) *DBus {
+	return &DBus{
+		appName: "tomatick",
+		call:    call,
+		ids:     make(map[string]uint32),
+	}
+}
+
+// method returns the gdbus arguments calling a method of the notification service
+func (d *DBus) method(name string, args ...string) []string {
+	return append([]string{
+		"call", "--session",
+		"--dest", "org.freedesktop.Notifications",
+		"--object-path", "/org/freedesktop/Notifications",
+		"--method", "org.freedesktop.Notifications." + name,
+	}, args...)
+}
+
+func (d *DBus) Notify(n Notification) error {
+	d.mu.Lock()
+	replaces := d.ids[n.Tag]
+	d.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbusTimeout)
+	defer cancel()
+
+	// Notify(app_name s, replaces_id u, app_icon s, summary s, body s,
+	// actions as, hints a{sv}, expire_timeout i)
+	out, err := d.call(ctx, d.method("Notify",
+		gvariantString(d.appName),
+		strconv.FormatUint(uint64(replaces), 10),
+		gvariantString(""),
+		gvariantString(n.Title),
+		gvariantString(n.Body),
+		"[]",
+		fmt.Sprintf("{'urgency': <byte %d>}", n.Urgency),
+		"-1",
+	)...)
+	if err != nil {
+		return fmt.Errorf("failed to send desktop notification: %w", err)
+	}
+
+	if n.Tag != "" {
+		if m := notifyReply.FindSubmatch(out); m != nil {
+			id, _ := strconv.ParseUint(string(m[1]), 10, 32)
+			d.mu.Lock()
+			d.ids[n.Tag] = uint32(id)
+			d.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// gvariantString quotes s as a GVariant text string, so gdbus never parses
+// user text as another value
+func gvariantString(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\'', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\u%04x`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
@@ -0,0 +1,125 @@
+// Package notify shows notifications outside of the terminal's output, so
+// timer ends and break reminders are noticed while the terminal is hidden.
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// Urgency is how insistent a notification is
+type Urgency byte
+
+const (
+	Low Urgency = iota
+	Normal
+	Critical
+)
+
+// Notification is a message for the user
+type Notification struct {
+	Title   string
+	Body    string
+	Urgency Urgency
+	// Tag groups notifications; a new one replaces the last with the same
+	// tag where the backend supports it, e.g. repeated break reminders
+	Tag string
+}
+
+// Notifier shows notifications
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// Kinds of notifiers, as configured with NOTIFIER
+const (
+	KindAuto   = "auto"
+	KindDBus   = "dbus"
+	KindOSC9   = "osc9"
+	KindOSC777 = "osc777"
+	KindBell   = "bell"
+	KindNone   = "none"
+)
+
+// New returns the notifier of the given kind, falling back to the terminal
+// bell when it fails. Auto picks the desktop's D-Bus notifications, then
+// the terminal's own notifications, then the bell.
+func New(kind string) Notifier {
+	bell := NewBell(os.Stdout)
+
+	switch kind {
+	case KindNone:
+		return Nop{}
+	case KindBell:
+		return bell
+	case KindOSC9:
+		return NewFallback(NewOSC9(os.Stdout, os.Getenv("TMUX") != ""), bell)
+	case KindOSC777:
+		return NewFallback(NewOSC777(os.Stdout, os.Getenv("TMUX") != ""), bell)
+	case KindDBus:
+		dbus, err := NewDBus()
+		if err != nil {
+			fmt.Printf("Warning: Desktop notifications not available: %v\n", err)
+			return bell
+		}
+		return NewFallback(dbus, bell)
+	}
+
+	var notifiers []Notifier
+	if dbus, err := NewDBus(); err == nil {
+		notifiers = append(notifiers, dbus)
+	}
+	if osc := detectTerminal(os.Stdout, os.Getenv); osc != nil {
+		notifiers = append(notifiers, osc)
+	}
+	return NewFallback(append(notifiers, bell)...)
+}
+
+// Nop discards notifications
+type Nop struct{}
+
+func (Nop) Notify(Notification) error { return nil }
+
+// Fallback tries its notifiers in order until one succeeds
+type Fallback struct {
+	notifiers []Notifier
+}
+
+// NewFallback creates a notifier that tries each of notifiers in order
+func NewFallback(notifiers ...Notifier) *Fallback {
+	return &Fallback{notifiers: notifiers}
+}
+
+func (f *Fallback) Notify(n Notification) error {
+	var errs []error
+	for i, notifier := range f.notifiers {
+		err := notifier.Notify(n)
+		if err == nil {
+			return nil
+		}
+		if i < len(f.notifiers)-1 {
+			log.Printf("Notification failed, falling back: %v", err)
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Bell rings the terminal bell, which most terminals turn into an urgency
+// hint or a dock bounce when they are in the background
+type Bell struct {
+	w io.Writer
+}
+
+// NewBell creates a notifier that rings the bell on w
+func NewBell(w io.Writer) *Bell {
+	return &Bell{w: w}
+}
+
+func (b *Bell) Notify(Notification) error {
+	_, err := io.WriteString(b.w, "\a")
+	return err
+}
@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestOSCSequences(t *testing.T) {
+	n := Notification{Title: "Break; now", Body: "Stretch\nyour \x1b[31mwrists\a"}
+
+	tests := []struct {
+		name     string
+		notifier func(w *bytes.Buffer) *OSC
+		want     string
+	}{
+		{
+			name:     "osc 9",
+			notifier: func(w *bytes.Buffer) *OSC { return NewOSC9(w, false) },
+			want:     "\x1b]9;Break; now: Stretch your [31mwrists\a",
+		},
+		{
+			name:     "osc 777",
+			notifier: func(w *bytes.Buffer) *OSC { return NewOSC777(w, false) },
+			want:     "\x1b]777;notify;Break, now;Stretch your [31mwrists\a",
+		},
+		{
+			name:     "osc 9 in tmux",
+			notifier: func(w *bytes.Buffer) *OSC { return NewOSC9(w, true) },
+			want:     "\x1bPtmux;\x1b\x1b]9;Break; now: Stretch your [31mwrists\a\x1b\\",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.notifier(&buf).Notify(n); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("sequence = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectTerminal(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want string // OSC code, empty for none
+	}{
+		{env: map[string]string{"TERM_PROGRAM": "iTerm.app"}, want: "9"},
+		{env: map[string]string{"TERM_PROGRAM": "tmux", "LC_TERMINAL": "iTerm2", "TMUX": "/tmp/tmux-1000/default,1,0"}, want: "9"},
+		{env: map[string]string{"TERM": "foot"}, want: "777"},
+		{env: map[string]string{"TERM": "rxvt-unicode-256color"}, want: "777"},
+		{env: map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "Apple_Terminal"}},
+	}
+
+	for _, tt := range tests {
+		osc := detectTerminal(&bytes.Buffer{}, func(key string) string { return tt.env[key] })
+		switch {
+		case tt.want == "" && osc != nil:
+			t.Errorf("%v: detected OSC %s, want none", tt.env, osc.code)
+		case tt.want != "" && (osc == nil || osc.code != tt.want):
+			t.Errorf("%v: detected %+v, want OSC %s", tt.env, osc, tt.want)
+		case osc != nil && osc.tmux != (tt.env["TMUX"] != ""):
+			t.Errorf("%v: tmux = %v", tt.env, osc.tmux)
+		}
+	}
+}
+
+func TestDBusNotify(t *testing.T) {
+	var calls [][]string
+	d := newDBus(func(ctx context.Context, args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		return []byte("(uint32 42,)\n"), nil
+	})
+
+	n := Notification{Title: "Break Time", Body: "It's time.\nStand up", Urgency: Critical, Tag: "break"}
+	if err := d.Notify(n); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Notify(n); err != nil {
+		t.Fatal(err)
+	}
+
+	args := calls[0]
+	if got := args[len(args)-9]; got != "org.freedesktop.Notifications.Notify" {
+		t.Fatalf("method = %q, args = %q", got, args)
+	}
+	want := []string{"'tomatick'", "0", "''", "'Break Time'", `'It\'s time.\nStand up'`, "[]", "{'urgency': <byte 2>}", "-1"}
+	if got := args[len(args)-8:]; strings.Join(got, " | ") != strings.Join(want, " | ") {
+		t.Errorf("arguments = %q, want %q", got, want)
+	}
+
+	// The second notification with the tag replaces the first
+	if got := calls[1][len(calls[1])-7]; got != "42" {
+		t.Errorf("replaces_id = %q, want 42", got)
+	}
+}
+
+// failingNotifier always fails
+type failingNotifier struct{ calls int }
+
+func (f *failingNotifier) Notify(Notification) error {
+	f.calls++
+	return errors.New("no daemon")
+}
+
+func TestFallback(t *testing.T) {
+	failing := &failingNotifier{}
+	var buf bytes.Buffer
+	f := NewFallback(failing, NewBell(&buf), &failingNotifier{})
+
+	if err := f.Notify(Notification{Title: "Done"}); err != nil {
+		t.Fatalf("Notify() = %v, want the bell to succeed", err)
+	}
+	if failing.calls != 1 || buf.String() != "\a" {
+		t.Errorf("first notifier called %d times, bell wrote %q", failing.calls, buf.String())
+	}
+
+	if err := NewFallback(&failingNotifier{}, &failingNotifier{}).Notify(Notification{}); err == nil {
+		t.Error("Notify() succeeded although every notifier failed")
+	}
+}
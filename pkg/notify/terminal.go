@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"io"
+	"strings"
+)
+
+// OSC sends notifications as terminal escape sequences: OSC 9, understood
+// by iTerm2, WezTerm and Ghostty, or OSC 777, understood by foot, urxvt and
+// Ghostty. The terminal shows them as desktop notifications.
+type OSC struct {
+	w    io.Writer
+	code string // "9" or "777"
+	tmux bool   // wrap the sequence so tmux passes it through
+}
+
+// NewOSC9 creates a notifier that writes OSC 9 sequences to w
+func NewOSC9(w io.Writer, tmux bool) *OSC {
+	return &OSC{w: w, code: "9", tmux: tmux}
+}
+
+// NewOSC777 creates a notifier that writes OSC 777 sequences to w
+func NewOSC777(w io.Writer, tmux bool) *OSC {
+	return &OSC{w: w, code: "777", tmux: tmux}
+}
+
+func (o *OSC) Notify(n Notification) error {
+	title, body := oscText(n.Title), oscText(n.Body)
+
+	var seq string
+	switch o.code {
+	case "777":
+		// The title ends at the next semicolon
+		seq = "\x1b]777;notify;" + strings.ReplaceAll(title, ";", ",") + ";" + body + "\a"
+	default:
+		// OSC 9 only has a message
+		message := title
+		if body != "" {
+			message = title + ": " + body
+		}
+		seq = "\x1b]9;" + message + "\a"
+	}
+
+	if o.tmux {
+		seq = tmuxPassthrough(seq)
+	}
+	_, err := io.WriteString(o.w, seq)
+	return err
+}
+
+// oscText flattens s onto one line without control characters, which
+// would end the sequence early
+func oscText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return ' '
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0):
+			return -1
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// tmuxPassthrough wraps seq in a DCS sequence that tmux forwards to the
+// outer terminal when allow-passthrough is on
+func tmuxPassthrough(seq string) string {
+	return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+}
+
+// detectTerminal returns the OSC notifier the terminal is known to support,
+// or nil
+func detectTerminal(w io.Writer, getenv func(string) string) *OSC {
+	tmux := getenv("TMUX") != ""
+
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "ghostty":
+		return NewOSC9(w, tmux)
+	}
+	// iTerm2 also sets LC_TERMINAL, which survives tmux and ssh
+	if getenv("LC_TERMINAL") == "iTerm2" {
+		return NewOSC9(w, tmux)
+	}
+
+	term := getenv("TERM")
+	switch {
+	case strings.HasPrefix(term, "foot"), strings.HasPrefix(term, "rxvt"):
+		return NewOSC777(w, tmux)
+	case term == "xterm-ghostty", term == "wezterm":
+		return NewOSC9(w, tmux)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Package policy adapts breaks to signs of fatigue: the break that is due
+// can be lengthened, or a long break brought forward, with the reasons
+// spelled out so the user can decide.
+package policy
+
+import (
+	"fmt"
+	"time"
+)
+
+// Break types
+const (
+	Short = "short"
+	Long  = "long"
+)
+
+// incompleteStreakThreshold is how many cycles in a row must end with
+// unfinished tasks before it counts as a sign of fatigue
+const incompleteStreakThreshold = 2
+
+// Signals are the signs of fatigue known when a break is due
+type Signals struct {
+	IncompleteStreak int    // consecutive cycles that ended with unfinished tasks
+	LastBreakCut     bool   // the last break ended early or was interrupted by work
+	LateHour         bool   // late evening or night
+	Energy           int    // self-reported energy from 1 to 5; 0 when unknown
+	BreakNeeded      string // the copilot's reason when it answered BREAK_NEEDED
+}
+
+// Decision is the break to take and why it differs from the planned one
+type Decision struct {
+	Type     string
+	Duration time.Duration
+	Reasons  []string // empty when the planned break is kept
+}
+
+// Adapted reports whether the decision differs from the planned break
+func (d Decision) Adapted() bool {
+	return len(d.Reasons) > 0
+}
+
+// String describes the break, like "20-minute long break"
+func (d Decision) String() string {
+	if d.Duration%time.Minute != 0 {
+		return fmt.Sprintf("%s %s break", d.Duration, d.Type)
+	}
+	return fmt.Sprintf("%d-minute %s break", int(d.Duration.Minutes()), d.Type)
+}
+
+// Policy decides the breaks for the configured break lengths
+type Policy struct {
+	shortBreak time.Duration
+	longBreak  time.Duration
+}
+
+// New returns a policy for the configured short and long break lengths
+func New(shortBreak, longBreak time.Duration) *Policy {
+	return &Policy{shortBreak: shortBreak, longBreak: longBreak}
+}
+
+// Planned is the break due by the schedule, without adapting it
+func (p *Policy) Planned(due string) Decision {
+	if due == Long {
+		return Decision{Type: Long, Duration: p.longBreak}
+	}
+	return Decision{Type: Short, Duration: p.shortBreak}
+}
+
+// Decide adapts the break that is due to the signals. Every sign of fatigue
+// adds to a score: one point lengthens a short break, two bring the long
+// break forward, and any lengthens a long break by a third.
+func (p *Policy) Decide(due string, s Signals) Decision {
+	score, reasons := fatigue(s)
+	decision := p.Planned(due)
+	if score == 0 {
+		return decision
+	}
+
+	switch {
+	case due == Long:
+		decision.Duration = p.longBreak + p.longBreak/3
+	case score >= 2:
+		decision.Type = Long
+		decision.Duration = p.longBreak
+	default:
+		// Twice as long, but no longer than a long break
+		decision.Duration = 2 * p.shortBreak
+		if decision.Duration > p.longBreak {
+			decision.Duration = p.longBreak
+		}
+	}
+
+	planned := p.Planned(due)
+	if decision.Type == planned.Type && decision.Duration <= planned.Duration {
+		// The break lengths leave nothing to adapt
+		return planned
+	}
+	decision.Reasons = reasons
+	return decision
+}
+
+// fatigue scores the signals and explains each point
+func fatigue(s Signals) (int, []string) {
+	score := 0
+	var reasons []string
+
+	if s.BreakNeeded != "" {
+		score += 2
+		reasons = append(reasons, "Your copilot flagged that you need a break: "+s.BreakNeeded)
+	}
+	if s.IncompleteStreak >= incompleteStreakThreshold {
+		score++
+		reasons = append(reasons, fmt.Sprintf("%d cycles in a row ended with unfinished tasks", s.IncompleteStreak))
+	}
+	if s.LastBreakCut {
+		score++
+		reasons = append(reasons, "Your last break was cut short or interrupted by work")
+	}
+	if s.LateHour {
+		score++
+		reasons = append(reasons, "It's late; your focus is better protected by resting")
+	}
+	switch {
+	case s.Energy == 1:
+		score += 2
+		reasons = append(reasons, "You reported very low energy")
+	case s.Energy == 2:
+		score++
+		reasons = append(reasons, "You reported low energy")
+	}
+
+	return score, reasons
+}
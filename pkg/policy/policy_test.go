@@ -0,0 +1,70 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecide(t *testing.T) {
+	p := New(5*time.Minute, 15*time.Minute)
+
+	tests := []struct {
+		name     string
+		due      string
+		signals  Signals
+		wantType string
+		want     time.Duration
+		reasons  int
+	}{
+		{name: "rested short break", due: Short, wantType: Short, want: 5 * time.Minute},
+		{name: "rested long break", due: Long, wantType: Long, want: 15 * time.Minute},
+		{name: "one unfinished cycle is normal", due: Short, signals: Signals{IncompleteStreak: 1}, wantType: Short, want: 5 * time.Minute},
+		{name: "unfinished streak lengthens the short break", due: Short, signals: Signals{IncompleteStreak: 2}, wantType: Short, want: 10 * time.Minute, reasons: 1},
+		{name: "late and cut break bring the long break forward", due: Short, signals: Signals{LateHour: true, LastBreakCut: true}, wantType: Long, want: 15 * time.Minute, reasons: 2},
+		{name: "break needed brings the long break forward", due: Short, signals: Signals{BreakNeeded: "three hours without rest"}, wantType: Long, want: 15 * time.Minute, reasons: 1},
+		{name: "very low energy brings the long break forward", due: Short, signals: Signals{Energy: 1}, wantType: Long, want: 15 * time.Minute, reasons: 1},
+		{name: "low energy lengthens the short break", due: Short, signals: Signals{Energy: 2}, wantType: Short, want: 10 * time.Minute, reasons: 1},
+		{name: "good energy changes nothing", due: Short, signals: Signals{Energy: 4}, wantType: Short, want: 5 * time.Minute},
+		{name: "fatigue lengthens the long break", due: Long, signals: Signals{LateHour: true}, wantType: Long, want: 20 * time.Minute, reasons: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.Decide(tt.due, tt.signals)
+			if got.Type != tt.wantType || got.Duration != tt.want {
+				t.Errorf("Decide = %s, want %s break of %v", got, tt.wantType, tt.want)
+			}
+			if len(got.Reasons) != tt.reasons || got.Adapted() != (tt.reasons > 0) {
+				t.Errorf("reasons = %q, want %d", got.Reasons, tt.reasons)
+			}
+		})
+	}
+}
+
+func TestDecideCapsShortBreakAtLongBreak(t *testing.T) {
+	p := New(10*time.Minute, 12*time.Minute)
+	got := p.Decide(Short, Signals{IncompleteStreak: 3})
+	if got.Type != Short || got.Duration != 12*time.Minute {
+		t.Errorf("Decide = %s, want a 12-minute short break", got)
+	}
+
+	// Nothing to lengthen when short breaks are as long as long ones
+	p = New(15*time.Minute, 15*time.Minute)
+	if got := p.Decide(Short, Signals{LateHour: true}); got.Adapted() {
+		t.Errorf("Decide = %s with reasons %q, want the planned break", got, got.Reasons)
+	}
+}
+
+func TestReasonsExplainSignals(t *testing.T) {
+	got := New(5*time.Minute, 15*time.Minute).Decide(Short, Signals{IncompleteStreak: 3, BreakNeeded: "sustained overwork"})
+	reasons := strings.Join(got.Reasons, "\n")
+	for _, want := range []string{"sustained overwork", "3 cycles in a row"} {
+		if !strings.Contains(reasons, want) {
+			t.Errorf("reasons %q don't mention %q", reasons, want)
+		}
+	}
+	if got.String() != "15-minute long break" {
+		t.Errorf("String = %q", got.String())
+	}
+}
@@ -0,0 +1,244 @@
+package pomodoro
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/1x-eng/tomatick/pkg/audio"
+	"github.com/1x-eng/tomatick/pkg/history"
+	"github.com/1x-eng/tomatick/pkg/llm"
+	"github.com/1x-eng/tomatick/pkg/monitor"
+	"github.com/1x-eng/tomatick/pkg/policy"
+	"github.com/1x-eng/tomatick/pkg/routine"
+	"github.com/1x-eng/tomatick/pkg/ui"
+	"github.com/1x-eng/tomatick/pkg/webhook"
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// violationCheckInterval is how often a break is checked for violations
+const violationCheckInterval = 5 * time.Second
+
+// breakPlan is what differs between short and long breaks
+type breakPlan struct {
+	breakType string // webhook.BreakShort or webhook.BreakLong, which match the routine and policy break types
+	phase     string
+	duration  time.Duration
+	headline  string
+	tip       string // shown when there is no routine to follow
+}
+
+// newBreakPlan describes the break the policy decided on
+func (p *TomatickMemento) newBreakPlan(decision policy.Decision) breakPlan {
+	if decision.Type == policy.Long {
+		return breakPlan{
+			breakType: webhook.BreakLong,
+			phase:     webhook.PhaseLongBreak,
+			duration:  decision.Duration,
+			headline:  fmt.Sprintf("%s Excellent work! Time for a longer break %s", p.theme.Emoji.Success, p.theme.Emoji.Break),
+			tip:       fmt.Sprintf("%s Take a walk or do some light exercise %s", p.theme.Emoji.Timer, p.theme.Emoji.Break),
+		}
+	}
+	return breakPlan{
+		breakType: webhook.BreakShort,
+		phase:     webhook.PhaseShortBreak,
+		duration:  decision.Duration,
+		headline:  fmt.Sprintf("%s Time for a refreshing break! %s", p.theme.Emoji.Break, p.theme.Emoji.Success),
+		tip:       fmt.Sprintf("%s Remember to stretch and rest your eyes %s", p.theme.Emoji.Timer, p.theme.Emoji.Break),
+	}
+}
+
+// decideBreak asks the break policy for the break to take when due is due.
+// An adapted break is explained and the user may keep the planned one.
+func (p *TomatickMemento) decideBreak(due string) policy.Decision {
+	planned := p.breakPolicy.Planned(due)
+	if !p.cfg.AdaptiveBreaks {
+		return planned
+	}
+
+	decision := p.breakPolicy.Decide(due, p.fatigueSignals())
+	if !decision.Adapted() {
+		return decision
+	}
+
+	fmt.Println(p.theme.Styles.Subtitle.Render(fmt.Sprintf("\n%s Suggesting a %s instead of the planned %s:",
+		p.theme.Emoji.Break, decision, planned)))
+	for _, reason := range decision.Reasons {
+		fmt.Println(p.theme.Styles.InfoText.Render("  • " + reason))
+	}
+
+	accept := true
+	prompt := &survey.Confirm{
+		Message: p.theme.Styles.Break.Render(fmt.Sprintf("Take the %s?", decision)),
+		Default: true,
+	}
+	if err := survey.AskOne(prompt, &accept); err != nil || !accept {
+		return planned
+	}
+	return decision
+}
+
+// fatigueSignals gathers the signs of fatigue for the break policy
+func (p *TomatickMemento) fatigueSignals() policy.Signals {
+	signals := policy.Signals{
+		IncompleteStreak: p.incompleteStreak,
+		LateHour:         llm.IsLateHour(time.Now().Hour()),
+		Energy:           p.currentEnergy(),
+		BreakNeeded:      p.breakNeeded,
+	}
+
+	breaks, err := p.history.Breaks()
+	if err != nil {
+		fmt.Println("Warning: Failed to read break history:", err)
+	}
+	if len(breaks) > 0 {
+		last := breaks[len(breaks)-1]
+		signals.LastBreakCut = last.SessionID == p.webhookDispatcher.SessionID() && !last.Real()
+	}
+	return signals
+}
+
+// breakNeeded finds the copilot's BREAK_NEEDED answer in text and returns its reason
+func breakNeeded(text string) (string, bool) {
+	for _, line := range strings.Split(text, "\n") {
+		if reason, ok := strings.CutPrefix(strings.TrimSpace(line), "BREAK_NEEDED:"); ok {
+			return strings.TrimSpace(reason), true
+		}
+	}
+	return "", false
+}
+
+// takeBreak runs a break: the routine's steps in the timer, violation
+// monitoring while the timer runs and a record in the break history
+func (p *TomatickMemento) takeBreak(decision policy.Decision) {
+	plan := p.newBreakPlan(decision)
+	defer func() { p.breakNeeded = "" }() // the copilot's call is answered by this break
+
+	breakRoutine, hasRoutine := p.nextRoutine(plan.breakType)
+
+	message := "\n" + plan.headline
+	if !hasRoutine {
+		message += "\n" + plan.tip
+	}
+
+	p.webhookDispatcher.Dispatch(webhook.BreakStart{
+		BreakType:              plan.breakType,
+		PlannedDurationSeconds: int(plan.duration.Seconds()),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.watchBreak(ctx)
+
+	elapsed, aborted := p.startTimerWithSteps(
+		plan.duration,
+		p.theme.Styles.InfoText.Render(message),
+		plan.phase,
+		breakRoutine.Title,
+		timedSteps(breakRoutine),
+	)
+
+	cancel()
+	summary := p.endBreakWatch()
+	if summary.HasViolations() {
+		fmt.Println(p.theme.Styles.InfoText.Render("\n" + summary.String()))
+	}
+
+	record := history.Break{
+		Type:           plan.breakType,
+		PlannedSeconds: int(plan.duration.Seconds()),
+		ElapsedSeconds: int(elapsed.Seconds()),
+		EndedEarly:     aborted,
+		Violations:     len(summary.Violations),
+		ViolationApps:  summary.Apps(),
+	}
+	if hasRoutine {
+		record.Routine = breakRoutine.Name
+		record.StepsCompleted = routine.StepsDone(breakRoutine, elapsed)
+		record.StepsTotal = len(breakRoutine.Steps)
+	}
+	p.recordBreak(record)
+
+	p.playSound(audio.BreakEnd)
+
+	p.webhookDispatcher.Dispatch(webhook.BreakEnd{
+		BreakType:              plan.breakType,
+		PlannedDurationSeconds: int(plan.duration.Seconds()),
+	})
+}
+
+// watchBreak monitors the break for violations until ctx is cancelled. The
+// reminders themselves are displayed by the monitor's notification manager.
+func (p *TomatickMemento) watchBreak(ctx context.Context) {
+	if p.activityMonitor == nil {
+		return
+	}
+
+	p.activityMonitor.OnBreakStart(ctx)
+
+	go func() {
+		ticker := time.NewTicker(violationCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// A check can outlast the break while the reminder is written
+				if notification := p.activityMonitor.CheckBreakViolations(); notification != nil && ctx.Err() == nil {
+					p.playSound(audio.BreakViolation)
+				}
+			}
+		}
+	}()
+}
+
+// endBreakWatch stops monitoring the break and returns its violations
+func (p *TomatickMemento) endBreakWatch() monitor.BreakSummary {
+	if p.activityMonitor == nil {
+		return monitor.BreakSummary{}
+	}
+	return p.activityMonitor.OnBreakEnd()
+}
+
+// nextRoutine picks the least recently done routine for the break
+func (p *TomatickMemento) nextRoutine(breakType string) (routine.Routine, bool) {
+	breaks, err := p.history.Breaks()
+	if err != nil {
+		fmt.Println("Warning: Failed to read break history:", err)
+	}
+	return p.routines.Next(breakType, history.LastUsed(breaks))
+}
+
+// timedSteps converts a routine's steps for the break timer
+func timedSteps(r routine.Routine) []ui.TimedStep {
+	steps := make([]ui.TimedStep, 0, len(r.Steps))
+	for _, step := range r.Steps {
+		steps = append(steps, ui.TimedStep{Instruction: step.Instruction, Duration: step.Duration})
+	}
+	return steps
+}
+
+// recordBreak adds a finished break to the history
+func (p *TomatickMemento) recordBreak(record history.Break) {
+	p.cycleMu.Lock()
+	record.Cycle = p.cycleCount + 1
+	p.cycleMu.Unlock()
+	record.Time = time.Now()
+	record.SessionID = p.webhookDispatcher.SessionID()
+
+	if err := p.history.AppendBreak(record); err != nil {
+		fmt.Println("Warning: Failed to record break:", err)
+	}
+}
+
+// breakHistory describes the recent breaks for the analysis
+func (p *TomatickMemento) breakHistory() string {
+	breaks, err := p.history.Breaks()
+	if err != nil {
+		fmt.Println("Warning: Failed to read break history:", err)
+		return ""
+	}
+	return history.Summarize(breaks, 8)
+}
@@ -0,0 +1,84 @@
+package pomodoro
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/1x-eng/tomatick/pkg/history"
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// checkIn asks for energy, focus and mood ratings at moment of the cycle
+// and records them. An empty answer skips the check-in.
+func (p *TomatickMemento) checkIn(moment string) {
+	if !p.cfg.CheckIns {
+		return
+	}
+
+	question := "How are you starting this cycle?"
+	if moment == history.CheckInEnd {
+		question = "How did this cycle leave you?"
+	}
+	fmt.Println(p.theme.Styles.Subtitle.Render(fmt.Sprintf("\n%s %s", p.theme.Emoji.Brain, question)))
+
+	var answer string
+	prompt := &survey.Input{
+		Message: "Energy, focus, mood (1-5 each, e.g. 3 4 3; Enter to skip):",
+		Help:    "1 is very low and 5 very high. The ratings tune suggestions, the analysis and break lengths, and are charted by `tomatick stats`.",
+	}
+	validator := func(ans interface{}) error {
+		text, _ := ans.(string)
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		_, err := history.ParseCheckIn(text)
+		return err
+	}
+	if err := survey.AskOne(prompt, &answer, survey.WithValidator(validator)); err != nil || strings.TrimSpace(answer) == "" {
+		return
+	}
+
+	record, err := history.ParseCheckIn(answer)
+	if err != nil {
+		return
+	}
+	record.Time = time.Now()
+	record.SessionID = p.webhookDispatcher.SessionID()
+	record.Moment = moment
+	p.cycleMu.Lock()
+	record.Cycle = p.cycleCount + 1
+	p.cycleMu.Unlock()
+
+	if err := p.history.AppendCheckIn(record); err != nil {
+		fmt.Println("Warning: Failed to record check-in:", err)
+	}
+}
+
+// currentEnergy is the energy of the session's latest check-in, 0 when
+// there is none
+func (p *TomatickMemento) currentEnergy() int {
+	checkIns, err := p.history.CheckIns()
+	if err != nil {
+		fmt.Println("Warning: Failed to read check-ins:", err)
+		return 0
+	}
+	if len(checkIns) == 0 {
+		return 0
+	}
+	last := checkIns[len(checkIns)-1]
+	if last.SessionID != p.webhookDispatcher.SessionID() {
+		return 0
+	}
+	return last.Energy
+}
+
+// checkInHistory describes the recent check-ins for the copilot
+func (p *TomatickMemento) checkInHistory() string {
+	checkIns, err := p.history.CheckIns()
+	if err != nil {
+		fmt.Println("Warning: Failed to read check-ins:", err)
+		return ""
+	}
+	return history.SummarizeCheckIns(checkIns, 8)
+}
@@ -0,0 +1,171 @@
+package pomodoro
+
+import (
+	"fmt"
+
+	"github.com/1x-eng/tomatick/pkg/api"
+	"github.com/1x-eng/tomatick/pkg/ui"
+	"github.com/1x-eng/tomatick/pkg/webhook"
+)
+
+// startControlAPI serves the local control API in the background
+func (p *TomatickMemento) startControlAPI() {
+	server, err := api.NewServer(p.cfg.ControlAPIAddr, p.cfg.ControlAPIToken, p)
+	if err != nil {
+		fmt.Printf("Warning: Control API not available: %v\n", err)
+		return
+	}
+	p.apiServer = server
+
+	go func() {
+		if err := server.Serve(); err != nil {
+			fmt.Printf("Warning: Control API stopped: %v\n", err)
+		}
+	}()
+	fmt.Println(p.theme.Styles.InfoText.Render(fmt.Sprintf("Control API listening on %s", server.Addr())))
+}
+
+func (p *TomatickMemento) setPhase(phase string) {
+	p.timerMu.Lock()
+	defer p.timerMu.Unlock()
+	p.phase = phase
+}
+
+func (p *TomatickMemento) setTasks(tasks []string) {
+	p.tasksMu.Lock()
+	defer p.tasksMu.Unlock()
+	p.currentTasks = append([]string(nil), tasks...)
+}
+
+func (p *TomatickMemento) tasksSnapshot() []string {
+	p.tasksMu.Lock()
+	defer p.tasksMu.Unlock()
+	return append([]string{}, p.currentTasks...)
+}
+
+// drainIncomingTasks returns the tasks queued through the control API
+func (p *TomatickMemento) drainIncomingTasks() []string {
+	var tasks []string
+	for {
+		select {
+		case task := <-p.incomingTasks:
+			tasks = append(tasks, task)
+		default:
+			return tasks
+		}
+	}
+}
+
+// State implements api.Controller
+func (p *TomatickMemento) State() api.State {
+	p.timerMu.Lock()
+	phase := p.phase
+	duration := p.phaseDuration
+	running := p.timer != nil
+	paused := running && !p.pausedAt.IsZero()
+	p.timerMu.Unlock()
+
+	if phase == "" {
+		phase = api.PhaseIdle
+	}
+
+	p.cycleMu.Lock()
+	completed := p.cycleCount
+	p.cycleMu.Unlock()
+
+	state := api.State{
+		SessionID:       p.webhookDispatcher.SessionID(),
+		Phase:           phase,
+		Cycle:           completed + 1,
+		CyclesCompleted: completed,
+		Paused:          paused,
+		TimerRunning:    running,
+		Tasks:           p.tasksSnapshot(),
+		SessionStarted:  p.sessionStarted,
+	}
+	if running {
+		elapsed := p.phaseElapsed()
+		if elapsed > duration {
+			elapsed = duration
+		}
+		state.DurationSeconds = int(duration.Seconds())
+		state.ElapsedSeconds = int(elapsed.Seconds())
+		state.RemainingSeconds = int((duration - elapsed).Seconds())
+	}
+	return state
+}
+
+// sendToTimer delivers a message to the running timer, if any
+func (p *TomatickMemento) sendToTimer(msg interface{}, phases ...string) error {
+	p.timerMu.Lock()
+	timer := p.timer
+	current := p.phase
+	p.timerMu.Unlock()
+
+	if timer == nil {
+		return fmt.Errorf("no timer is running: %w", api.ErrConflict)
+	}
+	if len(phases) > 0 {
+		allowed := false
+		for _, phase := range phases {
+			if phase == current {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("current phase is %s: %w", current, api.ErrConflict)
+		}
+	}
+
+	// Sent without holding timerMu: the timer's pause callback takes it
+	timer.Send(msg)
+	return nil
+}
+
+// Pause implements api.Controller
+func (p *TomatickMemento) Pause() error {
+	return p.sendToTimer(ui.PauseMsg{Paused: true})
+}
+
+// Resume implements api.Controller
+func (p *TomatickMemento) Resume() error {
+	return p.sendToTimer(ui.PauseMsg{Paused: false})
+}
+
+// SkipBreak implements api.Controller
+func (p *TomatickMemento) SkipBreak() error {
+	return p.sendToTimer(ui.StopMsg{}, api.PhaseShortBreak, api.PhaseLongBreak)
+}
+
+// AddTask implements api.Controller. During work the task joins the running
+// cycle; at any other time it is queued for the next task list.
+func (p *TomatickMemento) AddTask(task string) error {
+	p.timerMu.Lock()
+	phase := p.phase
+	p.timerMu.Unlock()
+
+	if phase == api.PhaseWork {
+		p.tasksMu.Lock()
+		p.currentTasks = append(p.currentTasks, task)
+		p.tasksMu.Unlock()
+	} else {
+		select {
+		case p.incomingTasks <- task:
+		default:
+			return fmt.Errorf("too many queued tasks: %w", api.ErrConflict)
+		}
+		if phase == api.PhasePlanning {
+			fmt.Println(p.auroraInstance.Green(fmt.Sprintf("\n✓ Task added via control API: %s (press Enter to refresh the list)", task)))
+		}
+	}
+
+	p.webhookDispatcher.Dispatch(webhook.TaskAdded{Source: webhook.TaskFromAPI, Task: task})
+	return nil
+}
+
+// EndSession implements api.Controller. The shutdown runs in the background
+// so the request can be answered before the process exits.
+func (p *TomatickMemento) EndSession() error {
+	go p.stop("end-session request from the control API", 0, true)
+	return nil
+}
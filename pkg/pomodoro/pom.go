@@ -1,15 +1,20 @@
 package pomodoro
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/1x-eng/tomatick/pkg/api"
+	"github.com/1x-eng/tomatick/pkg/audio"
+	"github.com/1x-eng/tomatick/pkg/editor"
+	"github.com/1x-eng/tomatick/pkg/history"
 	"github.com/1x-eng/tomatick/pkg/ltm"
 
 	"github.com/1x-eng/tomatick/pkg/llm"
@@ -20,6 +25,7 @@ import (
 	"github.com/1x-eng/tomatick/config"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/chzyer/readline"
 	"github.com/logrusorgru/aurora"
 
@@ -31,6 +37,9 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/1x-eng/tomatick/pkg/monitor"
+	"github.com/1x-eng/tomatick/pkg/notify"
+	"github.com/1x-eng/tomatick/pkg/policy"
+	"github.com/1x-eng/tomatick/pkg/routine"
 )
 
 var commandInstructions = []struct {
@@ -49,6 +58,12 @@ var commandInstructions = []struct {
 	{"quit", "End the session and save progress"},
 }
 
+const reflectionsEditorTemplate = `<!--
+Share your thoughts on progress, challenges, and insights,
+then save and close the editor. This comment is removed.
+-->
+`
+
 type TomatickMemento struct {
 	cfg                      *config.Config
 	memClient                ltm.LongTermMemory
@@ -58,17 +73,68 @@ type TomatickMemento struct {
 	cyclesSinceLastLongBreak int
 	auroraInstance           aurora.Aurora
 	sessionContext           string
+	contextDoc               *context.Context
 	theme                    *ui.Theme
 	currentSuggestions       []string
 	currentTasks             []string
 	lastAnalysis             string
 	currentChat              *llm.SuggestionChat
 	activityMonitor          *monitor.TomatickMonitor
+	notifier                 notify.Notifier
+	player                   *audio.Player
+	routines                 *routine.Library
+	history                  *history.Store
+	breakPolicy              *policy.Policy
+	incompleteStreak         int    // cycles in a row that ended with unfinished tasks
+	breakNeeded              string // the copilot's reason when it last answered BREAK_NEEDED
 	webhookDispatcher        webhook.Dispatcher
+	sessionStarted           time.Time
+	workElapsed              time.Duration
+
+	// Shutdown state: the running timer, the cycle in progress and pending mem.ai saves
+	timerMu       sync.Mutex
+	timer         *tea.Program
+	prompt        *readline.Instance // task entry prompt, closed on interrupt to restore the terminal
+	phase         string
+	phaseDuration time.Duration
+	phaseStarted  time.Time
+	phasePaused   time.Duration
+	pausedAt      time.Time
+	cycleMu       sync.Mutex
+	inCycle       bool
+	cycleWorked   time.Duration
+	memMu         sync.Mutex
+	memWG         sync.WaitGroup
+	pendingMem    map[int]string
+	memSeq        int
+	shutdownOnce  sync.Once
+	interruptOnce sync.Once
+	interrupting  atomic.Bool
+
+	// Control API: tasks are shared with API requests, which queue tasks
+	// added outside of work for the next planning phase
+	tasksMu       sync.Mutex
+	incomingTasks chan string
+	apiServer     *api.Server
 }
 
 func NewTomatickMemento(cfg *config.Config) *TomatickMemento {
-	activityMonitor, err := monitor.NewTomatickMonitor(cfg, llm.NewPerplexityAI(cfg))
+	dispatcher := webhook.NewSessionDispatcher(cfg)
+	notifier := notify.New(cfg.Notifier)
+
+	sink, err := audio.NewSink(cfg.Sound.Sink)
+	if err != nil {
+		fmt.Println("Warning: Sound not available:", err)
+		sink = &audio.Silent{}
+	}
+
+	routines, err := routine.NewLibrary(cfg.Routines)
+	if err != nil {
+		fmt.Println("Warning: Using every break routine:", err)
+		routines, _ = routine.NewLibrary(config.Routines{Custom: cfg.Routines.Custom})
+	}
+
+	activityMonitor, err := monitor.NewTomatickMonitor(cfg, llm.NewPerplexityAI(cfg), notifier, dispatcher)
 	if err != nil {
 		fmt.Println("Warning: Activity monitoring not available:", err)
 	}
@@ -83,27 +149,43 @@ func NewTomatickMemento(cfg *config.Config) *TomatickMemento {
 		theme:                    ui.NewTheme(),
 		currentSuggestions:       make([]string, 0),
 		activityMonitor:          activityMonitor,
-		webhookDispatcher:        webhook.NewHTTPDispatcher(cfg.Webhooks, filepath.Join(cfg.ContextDir, "logs")),
+		notifier:                 notifier,
+		player:                   audio.NewPlayer(cfg.Sound, sink),
+		routines:                 routines,
+		history:                  history.NewStore(filepath.Join(cfg.ContextDir, "history")),
+		breakPolicy:              policy.New(cfg.ShortBreakDuration, cfg.LongBreakDuration),
+		webhookDispatcher:        dispatcher,
+		pendingMem:               make(map[int]string),
+		incomingTasks:            make(chan string, 32),
 	}
 }
 
 func (p *TomatickMemento) StartCycle() {
 	if p.cycleCount == 0 {
+		p.handleSignals()
 		p.displayWelcomeMessage()
 
+		p.dispatchSessionStart()
+
+		if p.cfg.ControlAPIAddr != "" {
+			p.startControlAPI()
+		}
+
 		contextManager := context.NewContextManager(
 			p.cfg.ContextDir,
 			p.auroraInstance,
 			p.theme,
 			p.llmClient,
 			p.webhookDispatcher,
+			p.cfg.UseEditor,
 		)
 
 		sessionContext, err := contextManager.GetSessionContext(p.llmClient)
 		if err != nil {
 			fmt.Println(p.auroraInstance.Red("Error getting context:"), err)
 		} else {
-			p.sessionContext = sessionContext
+			p.contextDoc = sessionContext
+			p.sessionContext = sessionContext.String()
 
 			// Confirm context collection
 			fmt.Println(p.theme.Styles.Subtitle.Render("\n✓ Context collected successfully"))
@@ -115,32 +197,66 @@ func (p *TomatickMemento) StartCycle() {
 	for {
 		p.runTomatickMementoCycle()
 
+		due := policy.Short
 		if p.cyclesSinceLastLongBreak >= (p.cfg.CyclesBeforeLongBreak - 1) {
-			p.takeLongBreak()
+			due = policy.Long
+		}
+		decision := p.decideBreak(due)
+
+		if decision.Type == policy.Long {
+			p.webhookDispatcher.Dispatch(webhook.LongBreakDue{
+				CyclesSinceLongBreak:  p.cyclesSinceLastLongBreak + 1,
+				CyclesBeforeLongBreak: p.cfg.CyclesBeforeLongBreak,
+			})
+			p.takeBreak(decision)
 			p.cyclesSinceLastLongBreak = 0
 		} else {
-			if p.cyclesSinceLastLongBreak < p.cfg.CyclesBeforeLongBreak {
-				p.takeShortBreak()
-			}
+			p.takeBreak(decision)
 			p.cyclesSinceLastLongBreak++
 		}
 
+		p.cycleMu.Lock()
 		p.cycleCount++
+		p.cycleMu.Unlock()
 
 		if !p.askToContinue() {
+			p.dispatchSessionEnd()
 			fmt.Println(p.auroraInstance.Bold(p.auroraInstance.BrightGreen(("\nTomatick workday completed. Goodbye!"))))
 			p.printTotalHoursWorked()
+			p.Shutdown()
 			break
 		}
 	}
 }
 
+func (p *TomatickMemento) dispatchSessionStart() {
+	p.sessionStarted = time.Now()
+	p.webhookDispatcher.Dispatch(webhook.SessionStart{
+		WorkDurationSeconds:       int(p.cfg.TomatickMementoDuration.Seconds()),
+		ShortBreakDurationSeconds: int(p.cfg.ShortBreakDuration.Seconds()),
+		LongBreakDurationSeconds:  int(p.cfg.LongBreakDuration.Seconds()),
+		CyclesBeforeLongBreak:     p.cfg.CyclesBeforeLongBreak,
+	})
+}
+
+func (p *TomatickMemento) dispatchSessionEnd() {
+	p.webhookDispatcher.Dispatch(webhook.SessionEnd{
+		CyclesCompleted: p.cycleCount,
+		WorkSeconds:     int(p.workElapsed.Seconds()),
+		DurationSeconds: int(time.Since(p.sessionStarted).Seconds()),
+	})
+}
+
 func (p *TomatickMemento) askToContinue() bool {
 	continuePrompt := &survey.Confirm{
 		Message: p.auroraInstance.BrightBlue("Would you like to start another Tomatick cycle?").String(),
 	}
+	p.setPhase(api.PhaseIdle)
+
 	var answer bool
-	survey.AskOne(continuePrompt, &answer)
+	if err := survey.AskOne(continuePrompt, &answer); err == terminal.InterruptErr {
+		p.interrupt("interrupt", 130)
+	}
 	return answer
 }
 
@@ -156,19 +272,40 @@ func (p *TomatickMemento) createAndSetMemID() {
 	p.memID = memID
 }
 
+// asyncAppendToMem saves a summary to mem.ai in the background. Summaries
+// still pending or failed at shutdown are written to disk instead.
 func (p *TomatickMemento) asyncAppendToMem(cycleSummary string) {
+	p.memMu.Lock()
+	p.memSeq++
+	id := p.memSeq
+	p.pendingMem[id] = cycleSummary
+	p.memMu.Unlock()
+
+	p.memWG.Add(1)
+	go func() {
+		defer p.memWG.Done()
+		if p.appendToMem(cycleSummary) {
+			p.memMu.Lock()
+			delete(p.pendingMem, id)
+			p.memMu.Unlock()
+		}
+	}()
+}
+
+func (p *TomatickMemento) appendToMem(cycleSummary string) bool {
 	// Dispatch summary event
-	p.webhookDispatcher.Dispatch(webhook.EventSessionSummary, map[string]string{
-		"mem_id":  p.memID,
-		"content": cycleSummary,
+	p.webhookDispatcher.Dispatch(webhook.SessionSummary{
+		MemID:   p.memID,
+		Content: cycleSummary,
 	})
 
 	_, err := p.memClient.AppendToMem(p.memID, cycleSummary)
 
 	if err != nil {
 		fmt.Println(p.auroraInstance.Bold(p.auroraInstance.Red("Error appending to MemAI: ")), err)
+		return false
 	}
-
+	return true
 }
 
 func (p *TomatickMemento) runTomatickMementoCycle() {
@@ -176,21 +313,62 @@ func (p *TomatickMemento) runTomatickMementoCycle() {
 		p.createAndSetMemID()
 	}
 
+	p.webhookDispatcher.SetCycle(p.cycleCount + 1)
+	p.cycleMu.Lock()
+	p.inCycle = true
+	p.cycleWorked = 0
+	p.cycleMu.Unlock()
+	p.setPhase(api.PhasePlanning)
+	p.setTasks(nil)
+
+	p.checkIn(history.CheckInStart)
 	tasks := p.captureTasks()
+	p.setTasks(tasks)
 
-	p.webhookDispatcher.Dispatch(webhook.EventWorkStart, map[string]string{
-		"tasks_count": fmt.Sprintf("%d", len(tasks)),
+	p.webhookDispatcher.Dispatch(webhook.WorkStart{
+		Tasks:                  tasks,
+		TasksCount:             len(tasks),
+		PlannedDurationSeconds: int(p.cfg.TomatickMementoDuration.Seconds()),
 	})
 
-	p.startTimer(p.cfg.TomatickMementoDuration, p.auroraInstance.Italic(p.auroraInstance.BrightRed("Tick Tock Tick Tock...")).String())
-	p.playSound()
+	if p.focusTracking() {
+		p.activityMonitor.OnFocusStart()
+	}
+
+	worked := p.startTimer(p.cfg.TomatickMementoDuration, p.auroraInstance.Italic(p.auroraInstance.BrightRed("Tick Tock Tick Tock...")).String(), webhook.PhaseWork)
+	p.workElapsed += worked
+
+	var focusActivity string
+	if p.focusTracking() {
+		if summary := p.activityMonitor.OnFocusEnd(); summary.Tracked > 0 {
+			focusActivity = summary.String()
+			fmt.Println(p.theme.Styles.InfoText.Render("\n" + focusActivity))
+		}
+	}
+
+	p.cycleMu.Lock()
+	p.cycleWorked = worked
+	p.cycleMu.Unlock()
+	p.setPhase(api.PhaseReflection)
+	p.playSound(audio.WorkEnd)
 
-	p.webhookDispatcher.Dispatch(webhook.EventWorkComplete, map[string]string{
-		"tasks_count": fmt.Sprintf("%d", len(tasks)),
+	// Tasks may have been added through the control API while working
+	tasks = p.tasksSnapshot()
+
+	p.webhookDispatcher.Dispatch(webhook.WorkComplete{
+		Tasks:                  tasks,
+		TasksCount:             len(tasks),
+		PlannedDurationSeconds: int(p.cfg.TomatickMementoDuration.Seconds()),
 	})
 
 	completedTasks := p.markTasksComplete(tasks)
+	if strings.Contains(completedTasks, "- [ ] ") {
+		p.incompleteStreak++
+	} else {
+		p.incompleteStreak = 0
+	}
 	reflections := p.captureReflections()
+	p.checkIn(history.CheckInEnd)
 
 	// Initialize the spinner
 	spinner := ui.NewSpinner(p.theme.Styles.Spinner.
@@ -212,8 +390,8 @@ func (p *TomatickMemento) runTomatickMementoCycle() {
 	}()
 
 	// Perform AI analysis
-	assistant := llm.NewAssistant(p.llmClient, p.sessionContext, p.cfg)
-	analysis, err := assistant.AnalyzeProgress(p.currentTasks, strings.Split(completedTasks, "\n"), reflections)
+	assistant := p.newAssistant()
+	analysis, err := assistant.AnalyzeProgress(tasks, strings.Split(completedTasks, "\n"), reflections, focusActivity, p.breakHistory(), p.checkInHistory())
 
 	// Stop the spinner
 	done <- true
@@ -224,9 +402,9 @@ func (p *TomatickMemento) runTomatickMementoCycle() {
 		fmt.Println(p.auroraInstance.Red("Error getting AI analysis:"), err)
 	} else {
 		// Dispatch analysis event
-		p.webhookDispatcher.Dispatch(webhook.EventAIAnalysis, map[string]string{
-			"analysis": analysis,
-			"tasks":    strings.Join(p.currentTasks, "; "),
+		p.webhookDispatcher.Dispatch(webhook.AIAnalysis{
+			Analysis: analysis,
+			Tasks:    tasks,
 		})
 
 		if analysis == "" {
@@ -238,6 +416,9 @@ func (p *TomatickMemento) runTomatickMementoCycle() {
 		fmt.Println(formattedAnalysis)
 
 		p.lastAnalysis = analysis
+		if reason, ok := breakNeeded(analysis); ok {
+			p.breakNeeded = reason
+		}
 
 		if analysis != "" {
 			prompt := &survey.Confirm{
@@ -248,7 +429,7 @@ func (p *TomatickMemento) runTomatickMementoCycle() {
 			survey.AskOne(prompt, &discussAnalysis)
 
 			if discussAnalysis {
-				assistant := llm.NewAssistant(p.llmClient, p.sessionContext, p.cfg)
+				assistant := p.newAssistant()
 				analysisChat := assistant.StartAnalysisChat(analysis, tasks, completedTasks, reflections)
 				p.handleAnalysisChat(analysisChat)
 			}
@@ -276,7 +457,19 @@ func (p *TomatickMemento) runTomatickMementoCycle() {
 		cycleSummary += "\n### Copilot's Analysis\n" + analysis + "\n*\n"
 	}
 
-	go p.asyncAppendToMem(cycleSummary)
+	p.cycleMu.Lock()
+	p.inCycle = false
+	p.cycleMu.Unlock()
+	p.asyncAppendToMem(cycleSummary)
+}
+
+// newAssistant creates a copilot bound to the session context and its schedule
+func (p *TomatickMemento) newAssistant() *llm.Assistant {
+	assistant := llm.NewAssistant(p.llmClient, p.sessionContext, p.cfg)
+	if p.contextDoc != nil {
+		assistant.SetSchedule(p.contextDoc.ScheduleSummary(time.Now()))
+	}
+	return assistant
 }
 
 func (p *TomatickMemento) captureTasks() []string {
@@ -298,9 +491,28 @@ func (p *TomatickMemento) captureTasks() []string {
 	rl, _ := readline.New(p.auroraInstance.BrightGreen("➤ ").String())
 	defer rl.Close()
 
+	p.timerMu.Lock()
+	p.prompt = rl
+	p.timerMu.Unlock()
+	defer func() {
+		p.timerMu.Lock()
+		p.prompt = nil
+		p.timerMu.Unlock()
+	}()
+
 	for {
+		tasks = append(tasks, p.drainIncomingTasks()...)
+		p.setTasks(tasks)
 		p.displayTasks(tasks)
-		input, _ := rl.Readline()
+		input, err := rl.Readline()
+		if err == readline.ErrInterrupt {
+			rl.Close()
+			p.interrupt("interrupt", 130)
+		}
+		if err != nil && p.interrupting.Load() {
+			// The prompt was closed by a shutdown that will exit the process
+			select {}
+		}
 		input = strings.TrimSpace(input)
 
 		switch strings.ToLower(input) {
@@ -329,8 +541,8 @@ func (p *TomatickMemento) captureTasks() []string {
 				}
 			}()
 
-			assistant := llm.NewAssistant(p.llmClient, p.sessionContext, p.cfg)
-			suggestions, err := assistant.GetTaskSuggestions(tasks, p.lastAnalysis)
+			assistant := p.newAssistant()
+			suggestions, err := assistant.GetTaskSuggestions(tasks, p.lastAnalysis, p.checkInHistory())
 			done <- true
 			fmt.Print("\r") // Clear spinner line
 
@@ -340,12 +552,17 @@ func (p *TomatickMemento) captureTasks() []string {
 			}
 
 			// Dispatch suggestions event
-			p.webhookDispatcher.Dispatch(webhook.EventAISuggestions, map[string]string{
-				"suggestions_count": fmt.Sprintf("%d", len(suggestions)),
-				"suggestions":       strings.Join(suggestions, "\n"),
+			p.webhookDispatcher.Dispatch(webhook.AISuggestions{
+				SuggestionsCount: len(suggestions),
+				Suggestions:      suggestions,
 			})
 
 			p.currentSuggestions = suggestions // Store suggestions
+			if len(suggestions) == 1 {
+				if reason, ok := breakNeeded(suggestions[0]); ok {
+					p.breakNeeded = reason
+				}
+			}
 			// Initialize chat session here
 			p.currentChat = assistant.StartSuggestionChat(suggestions, p.lastAnalysis)
 			p.displaySuggestions(suggestions)
@@ -354,8 +571,11 @@ func (p *TomatickMemento) captureTasks() []string {
 			p.FlushSuggestions()
 		case "quit":
 			fmt.Println(p.auroraInstance.Bold(p.auroraInstance.BrightGreen("Session ended. Goodbye!")))
-			fmt.Println(p.auroraInstance.Italic("Waiting for pending webhooks..."))
-			p.webhookDispatcher.Wait()
+			p.cycleMu.Lock()
+			p.inCycle = false // nothing started yet, so there is no partial cycle
+			p.cycleMu.Unlock()
+			p.dispatchSessionEnd()
+			p.Shutdown()
 			os.Exit(0)
 		case "help":
 			p.displayHelp()
@@ -378,6 +598,7 @@ func (p *TomatickMemento) captureTasks() []string {
 				p.useSuggestion(&tasks, input)
 			} else {
 				tasks = append(tasks, input)
+				p.webhookDispatcher.Dispatch(webhook.TaskAdded{Source: webhook.TaskFromInput, Task: input})
 				fmt.Println(p.auroraInstance.Green("✓ Task added successfully."))
 			}
 		}
@@ -417,6 +638,7 @@ func (p *TomatickMemento) useSuggestion(tasks *[]string, input string) {
 
 	// Add the selected suggestion to tasks
 	*tasks = append(*tasks, p.currentSuggestions[index])
+	p.webhookDispatcher.Dispatch(webhook.TaskAdded{Source: webhook.TaskFromSuggestion, Task: p.currentSuggestions[index]})
 	fmt.Printf("%s %s\n",
 		p.auroraInstance.Green("✓ Added suggestion to tasks:"),
 		p.theme.Styles.TaskItem.Render(p.currentSuggestions[index]))
@@ -509,6 +731,9 @@ func (p *TomatickMemento) markTasksComplete(tasks []string) string {
 				p.theme.Styles.TaskItem.Render(task)),
 		}
 		survey.AskOne(prompt, &completed[i])
+		if completed[i] {
+			p.webhookDispatcher.Dispatch(webhook.TaskCompleted{Task: task})
+		}
 
 		// Immediate visual feedback
 		status := p.theme.Emoji.TaskComplete
@@ -539,6 +764,18 @@ func (p *TomatickMemento) captureReflections() string {
 		p.theme.Emoji.Reflection)
 
 	fmt.Println(p.theme.Styles.Title.Render(header))
+
+	if p.cfg.UseEditor {
+		fmt.Println(p.theme.Styles.InfoText.Render(
+			"Share your thoughts on progress, challenges, and insights (opening your editor):"))
+		reflections, err := editor.Edit(reflectionsEditorTemplate, "tomatick-reflections-*.md")
+		if err == nil {
+			fmt.Println()
+			return reflections
+		}
+		fmt.Println(p.auroraInstance.Yellow("Could not use your editor, type here instead:"), err)
+	}
+
 	fmt.Println(p.theme.Styles.InfoText.Render(
 		"Share your thoughts on progress, challenges, and insights (type 'done' to finish):"))
 
@@ -564,144 +801,119 @@ func (p *TomatickMemento) captureReflections() string {
 	return strings.Join(reflections, "\n")
 }
 
-func (p *TomatickMemento) startTimer(duration time.Duration, message string) {
-	model := ui.NewProgressModel(duration, message, p.theme)
-	program := tea.NewProgram(model)
-	if err := program.Start(); err != nil {
-		fmt.Println("Error running timer:", err)
-		return
-	}
+// startTimer runs the timer for one phase and returns how long it actually ran
+func (p *TomatickMemento) startTimer(duration time.Duration, message string, phase string) time.Duration {
+	elapsed, _ := p.startTimerWithSteps(duration, message, phase, "", nil)
+	return elapsed
 }
 
-func (p *TomatickMemento) takeShortBreak() {
-	message := fmt.Sprintf("\n%s Time for a refreshing break! %s\n%s Remember to stretch and rest your eyes %s",
-		p.theme.Emoji.Break,
-		p.theme.Emoji.Success,
-		p.theme.Emoji.Timer,
-		p.theme.Emoji.Break)
-
-	p.webhookDispatcher.Dispatch(webhook.EventBreakStart, map[string]string{
-		"type": "short",
-	})
-
-	if p.activityMonitor != nil {
-		p.activityMonitor.OnBreakStart()
-
-		// Start monitoring in background
-		monitorDone := make(chan bool)
-		go func() {
-			ticker := time.NewTicker(5 * time.Second)
-			defer ticker.Stop()
-
-			for {
-				select {
-				case <-ticker.C:
-					if notification := p.activityMonitor.CheckBreakViolations(); notification != nil {
-						_ = notification // Notification is displayed by the manager
-					}
-				case <-monitorDone:
-					return
-				}
-			}
-		}()
-
-		// Ensure monitoring stops after timer
-		defer func() {
-			monitorDone <- true
-			summary := p.activityMonitor.OnBreakEnd()
-			if summary.HasViolations {
-				p.lastAnalysis += "\n\nBreak Pattern: " + summary.ViolationDetails
-			}
-		}()
+// startTimerWithSteps runs the timer guiding the user through steps. It
+// returns how long the timer ran and whether it was stopped early.
+func (p *TomatickMemento) startTimerWithSteps(duration time.Duration, message string, phase string, stepsTitle string, steps []ui.TimedStep) (time.Duration, bool) {
+	model := ui.NewProgressModel(duration, message, p.theme).
+		WithSteps(stepsTitle, steps).
+		OnPause(p.timerPaused(phase, duration))
+	p.timerMu.Lock()
+	p.phase = phase
+	p.phaseDuration = duration
+	p.phaseStarted = time.Now()
+	p.phasePaused = 0
+	p.pausedAt = time.Time{}
+	p.timerMu.Unlock()
+
+	final, err := p.runTimer(tea.NewProgram(model))
+	if errors.Is(err, tea.ErrProgramKilled) {
+		// Killed by a signal; interrupt is shutting down and will exit
+		select {}
+	}
+	if err != nil {
+		fmt.Println("Error running timer:", err)
+		return 0, true
 	}
 
-	p.startTimer(
-		p.cfg.ShortBreakDuration,
-		p.theme.Styles.InfoText.Render(message),
-	)
-
-	p.playSound()
-
-	p.webhookDispatcher.Dispatch(webhook.EventBreakEnd, map[string]string{
-		"type": "short",
-	})
+	result := final.(ui.ProgressModel)
+	if result.Interrupted() {
+		p.interrupt("interrupt", 130)
+	}
+	p.timerEnded(phase, result)
+	return result.Elapsed(), result.Aborted()
 }
 
-func (p *TomatickMemento) takeLongBreak() {
-	message := fmt.Sprintf("\n%s Excellent work! Time for a longer break %s\n%s Take a walk or do some light exercise %s",
-		p.theme.Emoji.Success,
-		p.theme.Emoji.Break,
-		p.theme.Emoji.Timer,
-		p.theme.Emoji.Break)
-
-	p.webhookDispatcher.Dispatch(webhook.EventBreakStart, map[string]string{
-		"type": "long",
-	})
-
-	if p.activityMonitor != nil {
-		p.activityMonitor.OnBreakStart()
-
-		// Start monitoring in background
-		monitorDone := make(chan bool)
-		go func() {
-			ticker := time.NewTicker(5 * time.Second)
-			defer ticker.Stop()
+// timerPaused returns the timer's pause callback for a phase of duration
+func (p *TomatickMemento) timerPaused(phase string, duration time.Duration) func(paused bool, elapsed, pausedFor time.Duration) {
+	return func(paused bool, elapsed, pausedFor time.Duration) {
+		p.timerMu.Lock()
+		if paused {
+			p.pausedAt = time.Now()
+		} else {
+			p.pausedAt = time.Time{}
+			p.phasePaused += pausedFor
+		}
+		p.timerMu.Unlock()
 
-			for {
-				select {
-				case <-ticker.C:
-					if notification := p.activityMonitor.CheckBreakViolations(); notification != nil {
-						_ = notification // Notification is displayed by the manager
-					}
-				case <-monitorDone:
-					return
-				}
-			}
-		}()
+		if phase == webhook.PhaseWork && p.focusTracking() {
+			p.activityMonitor.OnFocusPause(paused)
+		}
 
-		// Ensure monitoring stops after timer
-		defer func() {
-			monitorDone <- true
-			summary := p.activityMonitor.OnBreakEnd()
-			if summary.HasViolations {
-				p.lastAnalysis += "\n\nBreak Pattern: " + summary.ViolationDetails
-			}
-		}()
+		if paused {
+			p.webhookDispatcher.Dispatch(webhook.TimerPaused{
+				Phase:            phase,
+				ElapsedSeconds:   int(elapsed.Seconds()),
+				RemainingSeconds: int((duration - elapsed).Seconds()),
+			})
+			return
+		}
+		p.webhookDispatcher.Dispatch(webhook.TimerResumed{
+			Phase:            phase,
+			ElapsedSeconds:   int(elapsed.Seconds()),
+			RemainingSeconds: int((duration - elapsed).Seconds()),
+			PausedSeconds:    int(pausedFor.Seconds()),
+		})
 	}
+}
 
-	p.startTimer(
-		p.cfg.LongBreakDuration,
-		p.theme.Styles.InfoText.Render(message),
-	)
-
-	p.playSound()
-
-	p.webhookDispatcher.Dispatch(webhook.EventBreakEnd, map[string]string{
-		"type": "long",
-	})
+// timerEnded reports a timer stopped early, or notifies the user it ran out
+func (p *TomatickMemento) timerEnded(phase string, result ui.ProgressModel) {
+	if result.Aborted() {
+		p.webhookDispatcher.Dispatch(webhook.TimerAborted{
+			Phase:            phase,
+			ElapsedSeconds:   int(result.Elapsed().Seconds()),
+			RemainingSeconds: int(result.Remaining().Seconds()),
+		})
+		return
+	}
+	p.notifyTimerEnd(phase)
 }
 
-func (p *TomatickMemento) playSound() {
-	soundPath := filepath.Join("assets", "softbeep.mp3")
-	var cmd *exec.Cmd
+// notifyTimerEnd tells the user a timer ran out, in case the terminal is
+// hidden behind other windows
+func (p *TomatickMemento) notifyTimerEnd(phase string) {
+	n := notify.Notification{Urgency: notify.Normal, Tag: "timer"}
+	switch phase {
+	case webhook.PhaseWork:
+		n.Title = "🍅 Focus session complete"
+		n.Body = "Time to mark your tasks and reflect."
+	case webhook.PhaseShortBreak, webhook.PhaseLongBreak:
+		n.Title = "⏰ Break is over"
+		n.Body = "Ready for the next focus session?"
+	default:
+		return
+	}
 
-	switch runtime.GOOS {
-	case "darwin":
-		// Use 'afplay' on macOS
-		cmd = exec.Command("afplay", soundPath)
-	case "linux":
-		// Use 'aplay' on Linux, works with WAV files. (may need to convert mp3 to wav?)
-		cmd = exec.Command("aplay", soundPath)
-	case "windows":
-		// Use PowerShell command on Windows
-		cmd = exec.Command("powershell", "-c", "(New-Object Media.SoundPlayer '"+soundPath+"').PlaySync();")
+	if err := p.notifier.Notify(n); err != nil {
+		fmt.Println("Warning: Failed to send notification:", err)
 	}
+}
 
-	if cmd != nil {
-		err := cmd.Run()
-		if err != nil {
-			fmt.Println("Error playing sound:", err)
-		}
+// focusTracking reports whether focus time is tracked during work sessions
+func (p *TomatickMemento) focusTracking() bool {
+	return p.cfg.FocusTracking && p.activityMonitor != nil
+}
+
+// playSound plays the sound of the event and waits for it to finish
+func (p *TomatickMemento) playSound(event audio.Event) {
+	if err := p.player.Play(event); err != nil {
+		fmt.Println("Error playing sound:", err)
 	}
 }
 
@@ -758,10 +970,10 @@ func displayWelcomeMessage(au aurora.Aurora) {
 // displayWelcomeMessage shows the welcome screen and integration status
 func (p *TomatickMemento) displayWelcomeMessage() {
 	displayWelcomeMessage(p.auroraInstance)
-	
+
 	// Check if Mem AI is configured
 	if p.cfg.GetMemAIToken() != "" {
-		fmt.Printf("\n%s %s\n", 
+		fmt.Printf("\n%s %s\n",
 			p.theme.Emoji.Success,
 			p.theme.Styles.SuccessText.Render("Long-term memory integration enabled (using mem.ai)"))
 	} else {
@@ -854,10 +1066,10 @@ func (p *TomatickMemento) handleSuggestionChat() {
 		}
 
 		// Dispatch chat exchange event
-		p.webhookDispatcher.Dispatch(webhook.EventAIChatExchange, map[string]string{
-			"context":   "suggestion_discussion",
-			"user_input": input,
-			"ai_response": response,
+		p.webhookDispatcher.Dispatch(webhook.AIChatExchange{
+			Context:    webhook.ChatSuggestionDiscussion,
+			UserInput:  input,
+			AIResponse: response,
 		})
 
 		// Format and display response
@@ -935,10 +1147,10 @@ func (p *TomatickMemento) handleAnalysisChat(chat *llm.SuggestionChat) {
 		}
 
 		// Dispatch chat exchange event
-		p.webhookDispatcher.Dispatch(webhook.EventAIChatExchange, map[string]string{
-			"context":   "analysis_discussion",
-			"user_input": input,
-			"ai_response": response,
+		p.webhookDispatcher.Dispatch(webhook.AIChatExchange{
+			Context:    webhook.ChatAnalysisDiscussion,
+			UserInput:  input,
+			AIResponse: response,
 		})
 
 		fmt.Println(p.theme.Styles.ChatDivider.Render(strings.Repeat("─", 50)))
@@ -0,0 +1,169 @@
+package pomodoro
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+	"github.com/1x-eng/tomatick/pkg/api"
+	"github.com/1x-eng/tomatick/pkg/notify"
+	"github.com/1x-eng/tomatick/pkg/ui"
+	"github.com/1x-eng/tomatick/pkg/webhook"
+	"github.com/logrusorgru/aurora"
+)
+
+// recordingDispatcher keeps the events instead of sending them
+type recordingDispatcher struct {
+	mu     sync.Mutex
+	events []webhook.Event
+}
+
+func (d *recordingDispatcher) Dispatch(event webhook.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.events = append(d.events, event)
+}
+
+func (d *recordingDispatcher) SessionID() string { return "test" }
+func (d *recordingDispatcher) SetCycle(int)      {}
+func (d *recordingDispatcher) Wait()             {}
+func (d *recordingDispatcher) Shutdown(time.Duration) []webhook.DeadLetter {
+	return nil
+}
+
+// take returns the events recorded so far and forgets them
+func (d *recordingDispatcher) take() []webhook.Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	events := d.events
+	d.events = nil
+	return events
+}
+
+// newTestMemento returns a memento with 25/5/15 minute timers that records its events
+func newTestMemento() (*TomatickMemento, *recordingDispatcher) {
+	dispatcher := &recordingDispatcher{}
+	return &TomatickMemento{
+		cfg: &config.Config{
+			TomatickMementoDuration: 25 * time.Minute,
+			ShortBreakDuration:      5 * time.Minute,
+			LongBreakDuration:       15 * time.Minute,
+			CyclesBeforeLongBreak:   4,
+		},
+		auroraInstance:    aurora.NewAurora(false),
+		theme:             ui.NewTheme(),
+		notifier:          notify.Nop{},
+		webhookDispatcher: dispatcher,
+		pendingMem:        make(map[int]string),
+		incomingTasks:     make(chan string, 32),
+	}, dispatcher
+}
+
+func TestSessionEvents(t *testing.T) {
+	p, dispatcher := newTestMemento()
+
+	p.dispatchSessionStart()
+	p.cycleCount = 2
+	p.workElapsed = 50 * time.Minute
+	p.dispatchSessionEnd()
+
+	want := []webhook.Event{
+		webhook.SessionStart{WorkDurationSeconds: 1500, ShortBreakDurationSeconds: 300, LongBreakDurationSeconds: 900, CyclesBeforeLongBreak: 4},
+		webhook.SessionEnd{CyclesCompleted: 2, WorkSeconds: 3000},
+	}
+	if got := dispatcher.take(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
+
+func TestTaskAddedEvents(t *testing.T) {
+	tests := []struct {
+		name string
+		add  func(p *TomatickMemento, tasks *[]string)
+		want []webhook.Event
+	}{
+		{name: "control API during work",
+			add: func(p *TomatickMemento, tasks *[]string) {
+				p.setPhase(api.PhaseWork)
+				p.AddTask("Reply to review")
+			},
+			want: []webhook.Event{webhook.TaskAdded{Source: webhook.TaskFromAPI, Task: "Reply to review"}}},
+		{name: "control API while planning",
+			add: func(p *TomatickMemento, tasks *[]string) {
+				p.setPhase(api.PhasePlanning)
+				p.AddTask("Reply to review")
+			},
+			want: []webhook.Event{webhook.TaskAdded{Source: webhook.TaskFromAPI, Task: "Reply to review"}}},
+		{name: "suggestion",
+			add: func(p *TomatickMemento, tasks *[]string) {
+				p.currentSuggestions = []string{"Outline the talk", "Book the room"}
+				p.useSuggestion(tasks, "use 2")
+			},
+			want: []webhook.Event{webhook.TaskAdded{Source: webhook.TaskFromSuggestion, Task: "Book the room"}}},
+		{name: "suggestion out of range",
+			add: func(p *TomatickMemento, tasks *[]string) {
+				p.currentSuggestions = []string{"Outline the talk"}
+				p.useSuggestion(tasks, "use 3")
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, dispatcher := newTestMemento()
+			var tasks []string
+
+			tt.add(p, &tasks)
+
+			if got := dispatcher.take(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimerEvents(t *testing.T) {
+	p, dispatcher := newTestMemento()
+	model := ui.NewProgressModel(25*time.Minute, "", p.theme).
+		OnPause(p.timerPaused(webhook.PhaseWork, 25*time.Minute))
+
+	update := func(msg interface{}) {
+		t.Helper()
+		next, _ := model.Update(msg)
+		model = next.(ui.ProgressModel)
+	}
+
+	update(ui.PauseMsg{Paused: true})
+	if got := dispatcher.take(); len(got) != 1 || got[0] != (webhook.TimerPaused{Phase: webhook.PhaseWork, RemainingSeconds: 1500}) {
+		t.Errorf("events after pausing = %+v, want one timer_paused", got)
+	}
+
+	update(ui.PauseMsg{Paused: false})
+	got := dispatcher.take()
+	if len(got) != 1 {
+		t.Fatalf("events after resuming = %+v, want one timer_resumed", got)
+	}
+	if resumed, ok := got[0].(webhook.TimerResumed); !ok || resumed.Phase != webhook.PhaseWork || resumed.RemainingSeconds != 1500 {
+		t.Errorf("event after resuming = %+v, want timer_resumed", got[0])
+	}
+
+	// Resuming a running timer reports nothing
+	update(ui.PauseMsg{Paused: false})
+	if got := dispatcher.take(); len(got) != 0 {
+		t.Errorf("events after resuming a running timer = %+v, want none", got)
+	}
+
+	// A timer that runs out only notifies the user
+	p.timerEnded(webhook.PhaseWork, model)
+	if got := dispatcher.take(); len(got) != 0 {
+		t.Errorf("events after a finished timer = %+v, want none", got)
+	}
+
+	update(ui.StopMsg{})
+	p.timerEnded(webhook.PhaseWork, model)
+	want := []webhook.Event{webhook.TimerAborted{Phase: webhook.PhaseWork, RemainingSeconds: 1500}}
+	if got := dispatcher.take(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events after stopping = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,230 @@
+package pomodoro
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/1x-eng/tomatick/pkg/webhook"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// shutdownTimeout bounds how long exiting waits for mem.ai and webhooks
+var shutdownTimeout = 10 * time.Second
+
+// handleSignals shuts down cleanly on SIGINT and SIGTERM. Ctrl-C pressed
+// inside the timer or a prompt arrives as a key instead and is handled there.
+func (p *TomatickMemento) handleSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		sig := <-signals
+		code := 130
+		if sig == syscall.SIGTERM {
+			code = 143
+		}
+		p.interrupt(sig.String(), code)
+	}()
+}
+
+// interrupt stops the running timer, records the cycle in progress as
+// interrupted and exits
+func (p *TomatickMemento) interrupt(reason string, code int) {
+	p.stop(reason, code, false)
+}
+
+// stop ends the session from outside the cycle loop: byUser records the
+// cycle in progress as ended by the user rather than interrupted
+func (p *TomatickMemento) stop(reason string, code int, byUser bool) {
+	// A second signal or Ctrl-C blocks here until the first one exits
+	p.interruptOnce.Do(func() {
+		p.interrupting.Store(true)
+		fmt.Println(p.auroraInstance.Yellow(fmt.Sprintf("\nReceived %s, shutting down...", reason)))
+
+		p.timerMu.Lock()
+		if p.timer != nil {
+			p.timer.Kill()
+		}
+		if p.prompt != nil {
+			p.prompt.Close()
+		}
+		p.timerMu.Unlock()
+
+		p.recordPartialCycle(byUser)
+		p.dispatchSessionEnd()
+		p.Shutdown()
+		os.Exit(code)
+	})
+}
+
+// runTimer runs a timer program, keeping it reachable for interrupt
+func (p *TomatickMemento) runTimer(program *tea.Program) (tea.Model, error) {
+	p.timerMu.Lock()
+	p.timer = program
+	p.timerMu.Unlock()
+
+	defer func() {
+		p.timerMu.Lock()
+		p.timer = nil
+		p.timerMu.Unlock()
+	}()
+
+	return program.Run()
+}
+
+// recordPartialCycle saves what is known about a cycle that was cut short,
+// by the user ending the session or by an interruption
+func (p *TomatickMemento) recordPartialCycle(byUser bool) {
+	p.cycleMu.Lock()
+	inCycle := p.inCycle
+	worked := p.cycleWorked
+	cycle := p.cycleCount + 1
+	p.inCycle = false
+	p.cycleMu.Unlock()
+
+	if !inCycle {
+		return
+	}
+
+	// While the work timer runs, cycleWorked is not set yet
+	p.timerMu.Lock()
+	running := p.timer != nil
+	p.timerMu.Unlock()
+	if running {
+		worked = p.phaseElapsed()
+	}
+	if worked > p.cfg.TomatickMementoDuration {
+		worked = p.cfg.TomatickMementoDuration
+	}
+
+	heading, stopped := "Interrupted Cycle", "Stopped"
+	if byUser {
+		heading, stopped = "Ended Cycle", "Ended by the user"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("### %s %d\n", heading, cycle))
+	sb.WriteString(fmt.Sprintf("%s after %d of %d minutes.\n",
+		stopped, int(worked.Minutes()), int(p.cfg.TomatickMementoDuration.Minutes())))
+	for _, task := range p.tasksSnapshot() {
+		sb.WriteString("- [ ] " + task + "\n")
+	}
+	sb.WriteString("*\n")
+
+	p.asyncAppendToMem(sb.String())
+}
+
+// phaseElapsed is how long the current timer has run, excluding pauses
+func (p *TomatickMemento) phaseElapsed() time.Duration {
+	p.timerMu.Lock()
+	defer p.timerMu.Unlock()
+
+	if p.phaseStarted.IsZero() {
+		return 0
+	}
+	elapsed := time.Since(p.phaseStarted) - p.phasePaused
+	if !p.pausedAt.IsZero() {
+		elapsed -= time.Since(p.pausedAt)
+	}
+	if elapsed < 0 {
+		return 0
+	}
+	return elapsed
+}
+
+// Shutdown flushes pending mem.ai summaries and webhook deliveries within
+// shutdownTimeout, then reports anything that did not make it
+func (p *TomatickMemento) Shutdown() {
+	p.shutdownOnce.Do(func() {
+		if p.apiServer != nil {
+			p.apiServer.Close()
+		}
+
+		fmt.Println(p.auroraInstance.Italic(fmt.Sprintf("Saving progress and sending pending webhooks (up to %s)...", shutdownTimeout)))
+
+		// mem.ai and webhooks share the timeout, so a hung save can't
+		// delay the deliveries past it or the other way round
+		var undelivered []webhook.DeadLetter
+		delivered := make(chan struct{})
+		go func() {
+			undelivered = p.webhookDispatcher.Shutdown(shutdownTimeout)
+			close(delivered)
+		}()
+		unsaved := p.flushMem(shutdownTimeout)
+		<-delivered
+
+		p.reportShutdown(unsaved, undelivered)
+	})
+}
+
+// flushMem waits for in-flight mem.ai saves and returns the summaries that were not saved
+func (p *TomatickMemento) flushMem(timeout time.Duration) []string {
+	done := make(chan struct{})
+	go func() {
+		p.memWG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+
+	p.memMu.Lock()
+	defer p.memMu.Unlock()
+
+	ids := make([]int, 0, len(p.pendingMem))
+	for id := range p.pendingMem {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	unsaved := make([]string, 0, len(ids))
+	for _, id := range ids {
+		unsaved = append(unsaved, p.pendingMem[id])
+	}
+	return unsaved
+}
+
+func (p *TomatickMemento) reportShutdown(unsaved []string, undelivered []webhook.DeadLetter) {
+	if len(unsaved) == 0 && len(undelivered) == 0 {
+		fmt.Println(p.auroraInstance.Green("✓ All summaries saved and webhooks delivered."))
+		return
+	}
+
+	if len(unsaved) > 0 {
+		path := filepath.Join(p.cfg.ContextDir, "logs", fmt.Sprintf("unsaved_summaries_%s.md", time.Now().Format("20060102_150405")))
+		if err := os.WriteFile(path, []byte(strings.Join(unsaved, "\n\n")), 0600); err != nil {
+			fmt.Println(p.auroraInstance.Red(fmt.Sprintf("✗ %d summaries were not saved to mem.ai and could not be written locally: %v", len(unsaved), err)))
+		} else {
+			fmt.Println(p.auroraInstance.Yellow(fmt.Sprintf("⚠ %d summaries were not saved to mem.ai; they are in %s", len(unsaved), path)))
+		}
+	}
+
+	if len(undelivered) > 0 {
+		byTarget := make(map[string][]string)
+		var targets []string
+		replayable := false
+		for _, letter := range undelivered {
+			replayable = replayable || letter.Replayable()
+			if _, ok := byTarget[letter.Target]; !ok {
+				targets = append(targets, letter.Target)
+			}
+			byTarget[letter.Target] = append(byTarget[letter.Target], string(letter.Event))
+		}
+
+		fmt.Println(p.auroraInstance.Yellow(fmt.Sprintf("⚠ %d event deliveries did not go through:", len(undelivered))))
+		for _, target := range targets {
+			fmt.Printf("  - %s: %s\n", target, strings.Join(byTarget[target], ", "))
+		}
+		if replayable {
+			fmt.Println(p.auroraInstance.Italic("Webhook deliveries are kept in the dead-letter queue; resend them with `tomatick webhook replay`."))
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package pomodoro
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+	"github.com/1x-eng/tomatick/pkg/ui"
+	"github.com/1x-eng/tomatick/pkg/webhook"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeMemory records appended summaries, blocking each append until
+// release is closed when it is set
+type fakeMemory struct {
+	mu       sync.Mutex
+	appended []string
+	release  chan struct{}
+}
+
+func (m *fakeMemory) CreateMem(content string) (string, error) { return "mem", nil }
+
+func (m *fakeMemory) AppendToMem(memID, content string) (string, error) {
+	if m.release != nil {
+		<-m.release
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.appended = append(m.appended, content)
+	return memID, nil
+}
+
+func TestRecordPartialCycle(t *testing.T) {
+	tests := []struct {
+		name    string
+		inCycle bool
+		worked  time.Duration
+		running time.Duration // how long the work timer has been running; 0 when it isn't
+		byUser  bool
+		want    string
+	}{
+		{name: "between cycles", inCycle: false},
+		{name: "interrupted during reflection", inCycle: true, worked: 25 * time.Minute,
+			want: "### Interrupted Cycle 3\nStopped after 25 of 25 minutes.\n- [ ] Draft the proposal\n*\n"},
+		{name: "interrupted while working", inCycle: true, running: 10*time.Minute + 30*time.Second,
+			want: "### Interrupted Cycle 3\nStopped after 10 of 25 minutes.\n- [ ] Draft the proposal\n*\n"},
+		{name: "ended by the user while working", inCycle: true, running: 10*time.Minute + 30*time.Second, byUser: true,
+			want: "### Ended Cycle 3\nEnded by the user after 10 of 25 minutes.\n- [ ] Draft the proposal\n*\n"},
+		{name: "paused past the planned time", inCycle: true, worked: 40 * time.Minute, byUser: true,
+			want: "### Ended Cycle 3\nEnded by the user after 25 of 25 minutes.\n- [ ] Draft the proposal\n*\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newTestMemento()
+			memory := &fakeMemory{}
+			p.memClient = memory
+			p.cycleCount = 2
+			p.inCycle = tt.inCycle
+			p.cycleWorked = tt.worked
+			p.setTasks([]string{"Draft the proposal"})
+			if tt.running > 0 {
+				p.timer = tea.NewProgram(ui.NewProgressModel(25*time.Minute, "", p.theme))
+				p.phaseStarted = time.Now().Add(-tt.running)
+			}
+
+			p.recordPartialCycle(tt.byUser)
+			p.memWG.Wait()
+
+			var got string
+			if len(memory.appended) > 0 {
+				got = memory.appended[0]
+			}
+			if len(memory.appended) > 1 || got != tt.want {
+				t.Errorf("mem.ai entries = %q, want %q", memory.appended, tt.want)
+			}
+			if p.inCycle {
+				t.Error("cycle still in progress after recording it")
+			}
+		})
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { shutdownTimeout = timeout }(shutdownTimeout)
+	shutdownTimeout = 300 * time.Millisecond
+
+	// An endpoint that never answers and a mem.ai save that never finishes
+	hung := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-hung }))
+	defer server.Close()
+	defer close(hung)
+
+	dir := t.TempDir()
+	dispatcher := webhook.NewHTTPDispatcher([]config.Webhook{{URL: server.URL, Timeout: time.Minute}}, filepath.Join(dir, "logs"), config.LogRotation{})
+
+	p, _ := newTestMemento()
+	p.cfg.ContextDir = dir
+	p.webhookDispatcher = dispatcher
+	p.memClient = &fakeMemory{release: hung}
+
+	p.asyncAppendToMem("### Cycle 1\n- [x] Draft the proposal\n*\n")
+	dispatcher.Dispatch(webhook.WorkStart{TasksCount: 1, PlannedDurationSeconds: 1500})
+
+	start := time.Now()
+	p.Shutdown()
+	if elapsed := time.Since(start); elapsed > shutdownTimeout+200*time.Millisecond {
+		t.Errorf("Shutdown took %v with a %v timeout", elapsed, shutdownTimeout)
+	}
+
+	unsaved, _ := filepath.Glob(filepath.Join(dir, "logs", "unsaved_summaries_*.md"))
+	if len(unsaved) != 1 {
+		t.Fatalf("unsaved summary files = %v, want one", unsaved)
+	}
+	if content, _ := os.ReadFile(unsaved[0]); !strings.Contains(string(content), "Draft the proposal") {
+		t.Errorf("unsaved summaries = %q", content)
+	}
+
+	letters, err := dispatcher.DeadLetters().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) == 0 {
+		t.Error("the hung delivery was not kept in the dead-letter queue")
+	}
+}
@@ -0,0 +1,174 @@
+// Package routine guides breaks with timed activities, rotating through the
+// enabled routines so the same one doesn't come up break after break.
+package routine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+// Break types a routine can be used for
+const (
+	Short = "short"
+	Long  = "long"
+	Any   = "any"
+)
+
+// Routine is a guided break activity made of timed steps
+type Routine = config.Routine
+
+// Step is one instruction of a routine
+type Step = config.RoutineStep
+
+// Builtin returns the routines that ship with tomatick
+func Builtin() []Routine {
+	return []Routine{
+		{
+			Name:  "eyes",
+			Title: "👀 20-20-20 eye reset",
+			Break: Any,
+			Steps: []Step{
+				step("Look at something at least 20 feet (6 m) away", 20*time.Second),
+				step("Close your eyes and relax your face", 20*time.Second),
+				step("Look far away again, blinking slowly", 20*time.Second),
+				step("Roll your eyes in slow circles, both directions", 20*time.Second),
+				step("Look out of a window or across the room", 20*time.Second),
+			},
+		},
+		{
+			Name:  "stretch",
+			Title: "🧘 Desk stretch sequence",
+			Break: Any,
+			Steps: []Step{
+				step("Stand up and roll your shoulders back 10 times", 30*time.Second),
+				step("Tilt your head to each side, holding 10 seconds", 30*time.Second),
+				step("Interlace your fingers and reach overhead", 20*time.Second),
+				step("Twist gently to each side from the waist", 30*time.Second),
+				step("Stretch your wrists and forearms against a wall", 30*time.Second),
+				step("Fold forward and let your arms hang", 20*time.Second),
+			},
+		},
+		{
+			Name:  "breathing",
+			Title: "🌬️ Box breathing",
+			Break: Any,
+			Steps: []Step{
+				step("Sit comfortably and breathe out fully", 10*time.Second),
+				step("Box breathing: in 4, hold 4, out 4, hold 4", 60*time.Second),
+				step("Keep the box going, eyes closed if you like", 60*time.Second),
+				step("Breathe naturally and notice how you feel", 20*time.Second),
+			},
+		},
+		{
+			Name:  "walk",
+			Title: "🚶 Walk and hydrate",
+			Break: Long,
+			Steps: []Step{
+				step("Get up and fill a glass of water", 1*time.Minute),
+				step("Walk around, outside if you can, away from screens", 8*time.Minute),
+				step("Stretch your legs, calves and hips", 2*time.Minute),
+			},
+		},
+	}
+}
+
+func step(instruction string, duration time.Duration) Step {
+	return Step{Instruction: instruction, Duration: duration}
+}
+
+// Library is the routines the user has enabled
+type Library struct {
+	routines []Routine
+}
+
+// NewLibrary merges the built-in routines with the custom ones from the
+// config, keeping only the enabled routines
+func NewLibrary(cfg config.Routines) (*Library, error) {
+	routines := Builtin()
+	for _, custom := range cfg.Custom {
+		replaced := false
+		for i := range routines {
+			if routines[i].Name == custom.Name {
+				routines[i] = custom
+				replaced = true
+			}
+		}
+		if !replaced {
+			routines = append(routines, custom)
+		}
+	}
+
+	if len(cfg.Enabled) == 0 {
+		return &Library{routines: routines}, nil
+	}
+
+	enabled := make([]Routine, 0, len(cfg.Enabled))
+	for _, name := range cfg.Enabled {
+		routine, ok := find(routines, name)
+		if !ok {
+			return nil, fmt.Errorf("unknown break routine %q", name)
+		}
+		enabled = append(enabled, routine)
+	}
+	return &Library{routines: enabled}, nil
+}
+
+func find(routines []Routine, name string) (Routine, bool) {
+	for _, routine := range routines {
+		if strings.EqualFold(routine.Name, name) {
+			return routine, true
+		}
+	}
+	return Routine{}, false
+}
+
+// Routines returns the enabled routines
+func (l *Library) Routines() []Routine {
+	return append([]Routine(nil), l.routines...)
+}
+
+// Next picks the routine for a break of the given type: the one used least
+// recently according to lastUsed, which maps routine names to when they were
+// last done. Routines never used come first, in library order.
+func (l *Library) Next(breakType string, lastUsed map[string]time.Time) (Routine, bool) {
+	var next Routine
+	var nextUsed time.Time
+	found := false
+
+	for _, routine := range l.routines {
+		if routine.Break != Any && routine.Break != breakType {
+			continue
+		}
+		used := lastUsed[routine.Name]
+		if !found || used.Before(nextUsed) {
+			next, nextUsed, found = routine, used, true
+		}
+	}
+	return next, found
+}
+
+// Duration is how long the routine's steps take
+func Duration(routine Routine) time.Duration {
+	var total time.Duration
+	for _, s := range routine.Steps {
+		total += s.Duration
+	}
+	return total
+}
+
+// StepsDone counts the steps finished within elapsed
+func StepsDone(routine Routine, elapsed time.Duration) int {
+	done := 0
+	var end time.Duration
+	for _, s := range routine.Steps {
+		end += s.Duration
+		if end > elapsed {
+			break
+		}
+		done++
+	}
+	return done
+}
@@ -0,0 +1,116 @@
+package routine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+func names(routines []Routine) []string {
+	var out []string
+	for _, r := range routines {
+		out = append(out, r.Name)
+	}
+	return out
+}
+
+func TestNewLibraryEnablesRoutinesInOrder(t *testing.T) {
+	library, err := NewLibrary(config.Routines{Enabled: []string{"breathing", "eyes"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(library.Routines()); len(got) != 2 || got[0] != "breathing" || got[1] != "eyes" {
+		t.Errorf("routines = %v, want [breathing eyes]", got)
+	}
+
+	if _, err := NewLibrary(config.Routines{Enabled: []string{"juggling"}}); err == nil {
+		t.Error("enabled an unknown routine")
+	}
+}
+
+func TestCustomRoutinesReplaceBuiltins(t *testing.T) {
+	custom := []Routine{
+		{Name: "eyes", Title: "My eyes", Break: Any, Steps: []Step{step("Blink", time.Minute)}},
+		{Name: "pushups", Title: "Push-ups", Break: Long, Steps: []Step{step("Ten push-ups", time.Minute)}},
+	}
+	library, err := NewLibrary(config.Routines{Custom: custom})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routines := library.Routines()
+	if len(routines) != len(Builtin())+1 {
+		t.Fatalf("routines = %v, want the built-ins plus pushups", names(routines))
+	}
+	if routines[0].Title != "My eyes" {
+		t.Errorf("eyes routine = %q, want the custom one", routines[0].Title)
+	}
+	if routines[len(routines)-1].Name != "pushups" {
+		t.Errorf("routines = %v, want pushups last", names(routines))
+	}
+}
+
+func TestNextRotatesLeastRecentlyUsed(t *testing.T) {
+	library, err := NewLibrary(config.Routines{Enabled: []string{"eyes", "stretch", "breathing", "walk"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	lastUsed := map[string]time.Time{}
+	var picked []string
+	for i := 0; i < 4; i++ {
+		r, ok := library.Next(Short, lastUsed)
+		if !ok {
+			t.Fatal("no routine for a short break")
+		}
+		picked = append(picked, r.Name)
+		lastUsed[r.Name] = now.Add(time.Duration(i) * time.Minute)
+	}
+
+	// walk is for long breaks only
+	want := []string{"eyes", "stretch", "breathing", "eyes"}
+	for i := range want {
+		if picked[i] != want[i] {
+			t.Fatalf("picked %v, want %v", picked, want)
+		}
+	}
+
+	if r, _ := library.Next(Long, lastUsed); r.Name != "walk" {
+		t.Errorf("long break picked %s, want the unused walk", r.Name)
+	}
+}
+
+func TestNextWithoutEligibleRoutine(t *testing.T) {
+	library, err := NewLibrary(config.Routines{Enabled: []string{"walk"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := library.Next(Short, nil); ok {
+		t.Errorf("picked %s for a short break", r.Name)
+	}
+}
+
+func TestStepsDone(t *testing.T) {
+	r := Routine{Steps: []Step{step("a", 20*time.Second), step("b", 30*time.Second), step("c", 10*time.Second)}}
+
+	for _, tt := range []struct {
+		elapsed time.Duration
+		want    int
+	}{
+		{0, 0},
+		{19 * time.Second, 0},
+		{20 * time.Second, 1},
+		{49 * time.Second, 1},
+		{time.Minute, 3},
+		{5 * time.Minute, 3},
+	} {
+		if got := StepsDone(r, tt.elapsed); got != tt.want {
+			t.Errorf("StepsDone(%v) = %d, want %d", tt.elapsed, got, tt.want)
+		}
+	}
+	if Duration(r) != time.Minute {
+		t.Errorf("Duration = %v, want 1m", Duration(r))
+	}
+}
@@ -48,6 +48,9 @@ func (cp *ContextPresenter) PresentContextMenu() string {
 		title string
 	}{
 		{cp.theme.Emoji.Context, "Load Existing Context"},
+		{cp.theme.Emoji.Section, "Build From a Template"},
+		{cp.theme.Emoji.Reflection, "Continue From Previous Session"},
+		{cp.theme.Emoji.Stats, "Import From a Git Repository"},
 		{cp.theme.Emoji.Brain, "Create New Context"},
 	}
 
@@ -83,7 +86,7 @@ func (cp *ContextPresenter) PresentContextList(contexts []string) string {
 	return sb.String()
 }
 
-func (cp *ContextPresenter) PresentContextInput() string {
+func (cp *ContextPresenter) PresentContextInput(useEditor bool) string {
 	var sb strings.Builder
 
 	// Header
@@ -104,6 +107,13 @@ func (cp *ContextPresenter) PresentContextInput() string {
 			cp.theme.Styles.InfoText.Render(guide)))
 	}
 
+	if useEditor {
+		sb.WriteString("\n\n" + cp.theme.Styles.SystemInstruction.Render(
+			"Opening your editor. Save and close it when finished."))
+		sb.WriteString("\n")
+		return sb.String()
+	}
+
 	sb.WriteString("\n\n" + cp.theme.Styles.SystemInstruction.Render(
 		"Type your context below (type 'done' when finished)"))
 	sb.WriteString("\n> ")
@@ -111,7 +121,19 @@ func (cp *ContextPresenter) PresentContextInput() string {
 	return sb.String()
 }
 
-func (cp *ContextPresenter) PresentDeltaContextInput() string {
+func (cp *ContextPresenter) PresentTemplateStart(name string) string {
+	var sb strings.Builder
+
+	sb.WriteString(cp.theme.Styles.Title.Render(fmt.Sprintf("\n🧩 %s Context", name)))
+	sb.WriteString(cp.theme.Styles.Subtitle.Render("\n" + strings.Repeat("─", 40)))
+	sb.WriteString("\n" + cp.theme.Styles.SystemInstruction.Render(
+		"Answer each prompt. Multi-line answers finish with an empty line; leave optional ones blank to skip."))
+	sb.WriteString("\n\n")
+
+	return sb.String()
+}
+
+func (cp *ContextPresenter) PresentDeltaContextInput(useEditor bool) string {
 	var sb strings.Builder
 
 	// Header
@@ -132,6 +154,13 @@ func (cp *ContextPresenter) PresentDeltaContextInput() string {
 			cp.theme.Styles.InfoText.Render(guide)))
 	}
 
+	if useEditor {
+		sb.WriteString("\n\n" + cp.theme.Styles.SystemInstruction.Render(
+			"Opening your editor. Save and close it when finished."))
+		sb.WriteString("\n")
+		return sb.String()
+	}
+
 	sb.WriteString("\n\n" + cp.theme.Styles.SystemInstruction.Render(
 		"Type your additional context below (type 'done' when finished)"))
 	sb.WriteString("\n> ")
@@ -14,8 +14,21 @@ type ProgressModel struct {
 	total       time.Duration
 	elapsed     time.Duration
 	done        bool
+	aborted     bool
+	interrupted bool
+	paused      bool
+	pausedAt    time.Time
+	onPause     func(paused bool, elapsed, pausedFor time.Duration)
 	theme       *Theme
 	description string
+	stepsTitle  string
+	steps       []TimedStep
+}
+
+// TimedStep is an instruction shown for part of the timer
+type TimedStep struct {
+	Instruction string
+	Duration    time.Duration
 }
 
 func NewProgressModel(duration time.Duration, description string, theme *Theme) ProgressModel {
@@ -33,18 +46,91 @@ func NewProgressModel(duration time.Duration, description string, theme *Theme)
 	}
 }
 
+// OnPause registers a callback for when the timer is paused (paused is true)
+// or resumed, in which case pausedFor is how long it was paused
+func (m ProgressModel) OnPause(fn func(paused bool, elapsed, pausedFor time.Duration)) ProgressModel {
+	m.onPause = fn
+	return m
+}
+
+// WithSteps guides the timer through steps, shown one after the other
+// under the title. Once they are done the rest of the timer is free.
+func (m ProgressModel) WithSteps(title string, steps []TimedStep) ProgressModel {
+	m.stepsTitle = title
+	m.steps = steps
+	return m
+}
+
+// currentStep returns the index of the step at elapsed and how long it has
+// left, or len(m.steps) once every step is done
+func (m ProgressModel) currentStep() (int, time.Duration) {
+	var end time.Duration
+	for i, step := range m.steps {
+		end += step.Duration
+		if m.elapsed < end {
+			return i, end - m.elapsed
+		}
+	}
+	return len(m.steps), 0
+}
+
+// Aborted reports whether the timer was stopped before it ran out
+func (m ProgressModel) Aborted() bool {
+	return m.aborted
+}
+
+// Interrupted reports whether the timer was stopped with Ctrl-C
+func (m ProgressModel) Interrupted() bool {
+	return m.interrupted
+}
+
+// Elapsed returns how much of the timer has run, excluding pauses
+func (m ProgressModel) Elapsed() time.Duration {
+	return m.elapsed
+}
+
+// Remaining returns how much of the timer was left
+func (m ProgressModel) Remaining() time.Duration {
+	if m.elapsed >= m.total {
+		return 0
+	}
+	return m.total - m.elapsed
+}
+
+// PauseMsg pauses (Paused true) or resumes a running timer from outside the program
+type PauseMsg struct {
+	Paused bool
+}
+
+// StopMsg stops a running timer early, as if a key had been pressed
+type StopMsg struct{}
+
 func (m ProgressModel) Init() tea.Cmd {
 	return tick()
 }
 
 func (m ProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg.(type) {
+	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// p or space pauses and resumes; any other key stops the timer early
+		if msg.String() == "p" || msg.String() == " " {
+			return m.setPaused(!m.paused), nil
+		}
+		m.aborted = true
+		m.interrupted = msg.Type == tea.KeyCtrlC
+		return m, tea.Quit
+	case PauseMsg:
+		return m.setPaused(msg.Paused), nil
+	case StopMsg:
+		m.aborted = true
 		return m, tea.Quit
 	case tickMsg:
 		if m.done {
 			return m, tea.Quit
 		}
+		if m.paused {
+			return m, tick()
+		}
 
 		m.elapsed += time.Second
 		if m.elapsed >= m.total {
@@ -56,6 +142,23 @@ func (m ProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m ProgressModel) setPaused(paused bool) ProgressModel {
+	if paused == m.paused {
+		return m
+	}
+
+	m.paused = paused
+	if paused {
+		m.pausedAt = time.Now()
+		if m.onPause != nil {
+			m.onPause(true, m.elapsed, 0)
+		}
+	} else if m.onPause != nil {
+		m.onPause(false, m.elapsed, time.Since(m.pausedAt))
+	}
+	return m
+}
+
 func (m ProgressModel) View() string {
 	if m.done {
 		return m.theme.Styles.SuccessText.Render(
@@ -77,6 +180,10 @@ func (m ProgressModel) View() string {
 	str.WriteString(m.theme.Styles.InfoText.Render(
 		fmt.Sprintf("%s %s\n", m.theme.Emoji.Timer, m.description)))
 
+	if len(m.steps) > 0 {
+		str.WriteString(m.stepsView())
+	}
+
 	// Progress bar
 	str.WriteString(m.progress.ViewAs(progress) + "\n")
 
@@ -88,12 +195,40 @@ func (m ProgressModel) View() string {
 			int(remainingTime.Seconds())%60,
 		)))
 
+	if m.paused {
+		str.WriteString("\n" + m.theme.Styles.InfoText.Render("⏸  Paused, press p to resume"))
+	} else {
+		str.WriteString("\n" + m.theme.Styles.InfoText.Render("p: pause · any other key: stop early"))
+	}
+
 	// Bottom border
 	str.WriteString("\n" + m.theme.Styles.Subtitle.Render(border))
 
 	return str.String()
 }
 
+// stepsView renders the current step with its countdown and the next one
+func (m ProgressModel) stepsView() string {
+	str := strings.Builder{}
+	str.WriteString(m.theme.Styles.Subtitle.Render(m.stepsTitle) + "\n")
+
+	current, left := m.currentStep()
+	if current == len(m.steps) {
+		str.WriteString(m.theme.Styles.SuccessText.Render(
+			fmt.Sprintf("%s Routine complete, rest freely until the timer ends", m.theme.Emoji.Success)) + "\n")
+		return str.String()
+	}
+
+	str.WriteString(m.theme.Styles.Break.Render(
+		fmt.Sprintf("Step %d/%d: %s", current+1, len(m.steps), m.steps[current].Instruction)) + "\n")
+	str.WriteString(m.theme.Styles.InfoText.Render(
+		fmt.Sprintf("   %02d:%02d left in this step", int(left.Minutes()), int(left.Seconds())%60)) + "\n")
+	if current+1 < len(m.steps) {
+		str.WriteString(m.theme.Styles.InfoText.Render("   Next: "+m.steps[current+1].Instruction) + "\n")
+	}
+	return str.String()
+}
+
 type tickMsg time.Time
 
 func tick() tea.Cmd {
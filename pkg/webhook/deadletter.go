@@ -0,0 +1,220 @@
+package webhook
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const deadLetterFileName = "webhooks.deadletter.jsonl"
+
+// DeadLetter is a delivery that exhausted its retries
+type DeadLetter struct {
+	ID          string    `json:"id"`
+	Event       EventType `json:"event"`
+	EventTime   time.Time `json:"event_time"`
+	Target      string    `json:"target"`
+	ContentType string    `json:"content_type"`
+	Body        string    `json:"body"`
+	Attempts    int       `json:"attempts"`
+	FailedAt    time.Time `json:"failed_at"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastCode    int       `json:"last_code,omitempty"`
+}
+
+// Replayable reports whether the letter is a webhook delivery kept in the
+// dead-letter queue. Undelivered MQTT messages are not kept.
+func (l DeadLetter) Replayable() bool {
+	return strings.HasPrefix(l.Target, "http://") || strings.HasPrefix(l.Target, "https://")
+}
+
+// DeadLetterFilter selects dead letters for replay. Zero values match everything.
+type DeadLetterFilter struct {
+	Events []EventType
+	Target string
+	Since  time.Time
+	Until  time.Time
+}
+
+// Matches reports whether the dead letter passes the filter
+func (f DeadLetterFilter) Matches(letter DeadLetter) bool {
+	if len(f.Events) > 0 {
+		found := false
+		for _, event := range f.Events {
+			if event == letter.Event {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Target != "" && !strings.EqualFold(f.Target, letter.Target) {
+		return false
+	}
+	if !f.Since.IsZero() && letter.EventTime.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && letter.EventTime.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// DeadLetterQueue persists failed deliveries as JSON lines next to the webhook log.
+// A running session appends to it while `tomatick webhook replay` rewrites
+// it, so writes also hold a lock on a sibling .lock file.
+type DeadLetterQueue struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewDeadLetterQueue opens the dead-letter file in logDir
+func NewDeadLetterQueue(logDir string) *DeadLetterQueue {
+	return &DeadLetterQueue{path: filepath.Join(logDir, deadLetterFileName)}
+}
+
+// Path returns the location of the dead-letter file
+func (q *DeadLetterQueue) Path() string {
+	return q.path
+}
+
+// lock excludes other writers of the queue, in this process and others
+func (q *DeadLetterQueue) lock() (unlock func(), err error) {
+	q.mu.Lock()
+	unlockFile, err := lockFile(q.path + ".lock")
+	if err != nil {
+		q.mu.Unlock()
+		return nil, fmt.Errorf("failed to lock dead-letter queue: %w", err)
+	}
+	return func() {
+		unlockFile()
+		q.mu.Unlock()
+	}, nil
+}
+
+// Append adds a failed delivery to the queue
+func (q *DeadLetterQueue) Append(letter DeadLetter) error {
+	unlock, err := q.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if letter.ID == "" {
+		letter.ID = newID()
+	}
+
+	line, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(q.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// List returns every dead letter, oldest first
+func (q *DeadLetterQueue) List() ([]DeadLetter, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.read()
+}
+
+func (q *DeadLetterQueue) read() ([]DeadLetter, error) {
+	f, err := os.Open(q.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var letters []DeadLetter
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // bodies can carry whole transcripts
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var letter DeadLetter
+		if err := json.Unmarshal([]byte(line), &letter); err != nil {
+			return nil, fmt.Errorf("corrupt dead-letter entry: %w", err)
+		}
+		letters = append(letters, letter)
+	}
+
+	return letters, scanner.Err()
+}
+
+// Update replaces the stored letters with the same IDs and drops the IDs in
+// remove. Letters appended since they were listed are kept.
+func (q *DeadLetterQueue) Update(updated []DeadLetter, remove []string) error {
+	unlock, err := q.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	letters, err := q.read()
+	if err != nil {
+		return err
+	}
+
+	removed := make(map[string]bool, len(remove))
+	for _, id := range remove {
+		removed[id] = true
+	}
+	replacements := make(map[string]DeadLetter, len(updated))
+	for _, letter := range updated {
+		replacements[letter.ID] = letter
+	}
+
+	var sb strings.Builder
+	for _, letter := range letters {
+		if removed[letter.ID] {
+			continue
+		}
+		if replacement, ok := replacements[letter.ID]; ok {
+			letter = replacement
+		}
+		line, err := json.Marshal(letter)
+		if err != nil {
+			return err
+		}
+		sb.Write(line)
+		sb.WriteByte('\n')
+	}
+
+	// Write to a temp file first so a crash can't truncate the queue
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(sb.String()), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.path)
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
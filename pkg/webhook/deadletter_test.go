@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+func TestDeadLetterAppendBetweenListAndUpdate(t *testing.T) {
+	dir := t.TempDir()
+	// The replay command and a running session each open the queue
+	replay := NewDeadLetterQueue(dir)
+	session := NewDeadLetterQueue(dir)
+
+	if err := session.Append(DeadLetter{ID: "old", Event: EventWorkStart, Target: "https://example.com/hook"}); err != nil {
+		t.Fatal(err)
+	}
+
+	letters, err := replay.List()
+	if err != nil || len(letters) != 1 {
+		t.Fatalf("List = %v, %v", letters, err)
+	}
+
+	// The session fails another delivery while the replay is sending
+	if err := session.Append(DeadLetter{ID: "new", Event: EventBreakStart, Target: "https://example.com/hook"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := replay.Update(nil, []string{"old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	letters, err = session.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 1 || letters[0].ID != "new" {
+		t.Errorf("letters after replay = %+v, want only the new one", letters)
+	}
+}
+
+func TestDeadLetterAppendWaitsForLock(t *testing.T) {
+	dir := t.TempDir()
+	queue := NewDeadLetterQueue(dir)
+
+	// Another process rewriting the queue holds the lock
+	unlock, err := lockFile(queue.Path() + ".lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appended := make(chan error)
+	go func() { appended <- queue.Append(DeadLetter{ID: "waiting"}) }()
+
+	select {
+	case err := <-appended:
+		t.Fatalf("Append returned %v while the queue was locked", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case err := <-appended:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Append didn't finish after the lock was released")
+	}
+
+	if letters, err := queue.List(); err != nil || len(letters) != 1 {
+		t.Errorf("List = %v, %v", letters, err)
+	}
+}
+
+func TestReplayContentType(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[string]string) // body to Content-Type
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received[string(body)] = r.Header.Get("Content-Type")
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	d := NewHTTPDispatcher([]config.Webhook{{URL: server.URL, Template: "ntfy"}}, dir, config.LogRotation{})
+	defer d.Close()
+
+	for _, letter := range []DeadLetter{
+		{ID: "stored", Target: server.URL, ContentType: "application/json", Body: "stored"},
+		{ID: "legacy", Target: server.URL, Body: "legacy"},
+	} {
+		if err := d.DeadLetters().Append(letter); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := d.Replay(DeadLetterFilter{})
+	if err != nil || result.Delivered != 2 {
+		t.Fatalf("Replay = %+v, %v", result, err)
+	}
+	if got := received["stored"]; got != "application/json" {
+		t.Errorf("stored content type sent as %q", got)
+	}
+	if got := received["legacy"]; got != "text/plain; charset=utf-8" {
+		t.Errorf("letter without a content type sent as %q, want the endpoint's", got)
+	}
+}
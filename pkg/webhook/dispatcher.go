@@ -2,106 +2,207 @@ package webhook
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
 	"net/http"
 	"os"
-	"path/filepath"
 	"sync"
+	"text/template"
 	"time"
+
+	"github.com/1x-eng/tomatick/config"
 )
 
+// defaultTimeout applies to endpoints configured without a timeout
+const defaultTimeout = 10 * time.Second
+
+// errShutdown is recorded for deliveries cut short by Shutdown
+var errShutdown = errors.New("dispatcher shut down before delivery")
+
 // HTTPDispatcher implements the Dispatcher interface for HTTP webhooks
 type HTTPDispatcher struct {
-	urls       []string
+	endpoints  []*endpoint
 	client     *http.Client
 	workerPool chan struct{} // Semaphore to limit concurrent dispatches
 	logger     *log.Logger
-	logFile    *os.File
+	logFile    *rotatingLog
+	deadLetter *DeadLetterQueue
 	wg         sync.WaitGroup
+	session
+
+	// stop is cancelled when Shutdown runs out of time, aborting retries
+	stop        context.Context
+	cancel      context.CancelFunc
+	mu          sync.Mutex
+	undelivered []DeadLetter
+}
+
+// endpoint is a configured webhook with its payload template compiled
+type endpoint struct {
+	config.Webhook
+	tmpl        *template.Template // nil sends the standard JSON payload
+	contentType string
+	headers     map[string]string // preset headers, overridden by configured ones
 }
 
-type dispatchLog struct {
-	Timestamp string      `json:"timestamp"`
-	Event     EventType   `json:"event"`
-	Target    string      `json:"target"`
-	Attempt   int         `json:"attempt"`
-	Status    string      `json:"status"`
-	Code      int         `json:"code,omitempty"`
-	Error     string      `json:"error,omitempty"`
-	Duration  string      `json:"duration"`
+func newEndpoint(webhook config.Webhook) (*endpoint, error) {
+	tmpl, contentType, presetHeaders, err := compileTemplate(webhook.Template, webhook.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string)
+	for key, value := range presetHeaders {
+		headers[key] = value
+	}
+	for key, value := range webhook.Headers {
+		headers[key] = value
+	}
+
+	return &endpoint{
+		Webhook:     webhook,
+		tmpl:        tmpl,
+		contentType: contentType,
+		headers:     headers,
+	}, nil
 }
 
-// NewHTTPDispatcher creates a new dispatcher with the given webhook URLs and log path
-func NewHTTPDispatcher(urls []string, logDir string) *HTTPDispatcher {
+// render builds the request body for a payload
+func (e *endpoint) render(payload EventPayload) ([]byte, error) {
+	if e.tmpl == nil {
+		return json.Marshal(payload)
+	}
+	return renderTemplate(e.tmpl, payload)
+}
+
+// NewHTTPDispatcher creates a new dispatcher with the given webhook endpoints,
+// log directory and log rotation policy
+func NewHTTPDispatcher(webhooks []config.Webhook, logDir string, rotation config.LogRotation) *HTTPDispatcher {
 	// Ensure log directory exists
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		fmt.Printf("Warning: Failed to create webhook log directory: %v\n", err)
 	}
 
-	logPath := filepath.Join(logDir, "webhooks.log")
-	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	var logger *log.Logger
+	f, err := openRotatingLog(logDir, rotation)
 	if err != nil {
 		fmt.Printf("Warning: Failed to open webhook log file: %v\n", err)
+	} else {
+		logger = log.New(f, "", 0)
 	}
 
+	var endpoints []*endpoint
+	for _, webhook := range webhooks {
+		for _, event := range webhook.Events {
+			if !IsKnownEvent(EventType(event)) {
+				fmt.Printf("Warning: Webhook %s subscribes to unknown event %q\n", webhook.URL, event)
+			}
+		}
+
+		ep, err := newEndpoint(webhook)
+		if err != nil {
+			fmt.Printf("Warning: Webhook %s disabled: %v\n", webhook.URL, err)
+			continue
+		}
+		endpoints = append(endpoints, ep)
+	}
+
+	stop, cancel := context.WithCancel(context.Background())
+
 	return &HTTPDispatcher{
-		urls: urls,
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		endpoints: endpoints,
+		// Timeouts are applied per request, from each endpoint's configuration
+		client:     &http.Client{},
 		workerPool: make(chan struct{}, 10),
-		logger:     log.New(f, "", 0),
+		logger:     logger,
 		logFile:    f,
+		deadLetter: NewDeadLetterQueue(logDir),
+		session:    session{id: newID()},
+		stop:       stop,
+		cancel:     cancel,
 	}
 }
 
 // Dispatch sends an event to all configured webhooks asynchronously
-func (d *HTTPDispatcher) Dispatch(eventType EventType, data map[string]string) {
-	if len(d.urls) == 0 {
+func (d *HTTPDispatcher) Dispatch(event Event) {
+	if len(d.endpoints) == 0 {
 		return
 	}
 
-	payload := EventPayload{
-		Type:      eventType,
-		Timestamp: time.Now(),
-		Data:      data,
+	eventType := event.EventType()
+	payload := d.newPayload(event, time.Now())
+	timestamp := payload.Timestamp
+
+	for _, endpoint := range d.endpoints {
+		if !subscribes(endpoint.Events, eventType) {
+			continue
+		}
+
+		body, err := endpoint.render(endpoint.prepare(payload))
+		if err != nil {
+			d.logAttempt(eventType, endpoint.URL, 0, "Failed", 0, err, 0)
+			continue
+		}
+
+		d.wg.Add(1)
+		go d.sendWithRetry(endpoint, eventType, timestamp, body)
 	}
+}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		d.logAttempt(eventType, "marshal_error", 0, "Failed", 0, err, 0)
-		return
+// subscribes reports whether a subscription to events includes this type;
+// an empty list subscribes to everything
+func subscribes(events []string, eventType EventType) bool {
+	if len(events) == 0 {
+		return true
+	}
+	for _, event := range events {
+		if EventType(event) == eventType {
+			return true
+		}
 	}
+	return false
+}
 
-	for _, url := range d.urls {
-		d.wg.Add(1)
-		go d.sendWithRetry(url, eventType, body)
+// prepare strips user content from the payload unless the endpoint opted in
+func (e *endpoint) prepare(payload EventPayload) EventPayload {
+	if !e.IncludeSensitive {
+		payload.Data = payload.Data.Redacted()
 	}
+	return payload
 }
 
-func (d *HTTPDispatcher) sendWithRetry(url string, eventType EventType, body []byte) {
+func (d *HTTPDispatcher) sendWithRetry(endpoint *endpoint, eventType EventType, timestamp time.Time, body []byte) {
 	defer d.wg.Done()
 
-	// Acquire worker slot
-	d.workerPool <- struct{}{}
-	defer func() { <-d.workerPool }()
-
-	const maxRetries = 3
-	baseDelay := 1 * time.Second
+	maxRetries := endpoint.MaxRetries
+	baseDelay := endpoint.RetryBaseDelay
 
 	var lastErr error
 	var statusCode int
+	attempts := 0
+
+	// Acquire worker slot
+	select {
+	case d.workerPool <- struct{}{}:
+		defer func() { <-d.workerPool }()
+	case <-d.stop.Done():
+		lastErr = errShutdown
+		maxRetries = -1 // skip straight to the dead letter
+	}
 
+retries:
 	for attempt := 1; attempt <= maxRetries+1; attempt++ {
+		attempts = attempt
 		start := time.Now()
-		statusCode, lastErr = d.sendOnce(url, body)
+		statusCode, lastErr = d.sendOnce(endpoint, body)
 		duration := time.Since(start)
 
 		if lastErr == nil && statusCode >= 200 && statusCode < 300 {
-			d.logAttempt(eventType, url, attempt, "Success", statusCode, nil, duration)
+			d.logAttempt(eventType, endpoint.URL, attempt, "Success", statusCode, nil, duration)
 			return
 		}
 
@@ -110,14 +211,146 @@ func (d *HTTPDispatcher) sendWithRetry(url string, eventType EventType, body []b
 		if attempt <= maxRetries {
 			status = "Retrying"
 		}
-		d.logAttempt(eventType, url, attempt, status, statusCode, lastErr, duration)
+		d.logAttempt(eventType, endpoint.URL, attempt, status, statusCode, lastErr, duration)
 
 		if attempt <= maxRetries {
-			// Exponential backoff: 1s, 2s, 4s
+			// Exponential backoff, 1s, 2s, 4s with the default base delay
 			sleepDuration := baseDelay * time.Duration(math.Pow(2, float64(attempt-1)))
-			time.Sleep(sleepDuration)
+			select {
+			case <-time.After(sleepDuration):
+			case <-d.stop.Done():
+				lastErr = errShutdown
+				break retries
+			}
 		}
 	}
+
+	// Keep the rendered body so the delivery can be replayed later
+	letter := DeadLetter{
+		Event:       eventType,
+		EventTime:   timestamp,
+		Target:      endpoint.URL,
+		ContentType: endpoint.contentType,
+		Body:        string(body),
+		Attempts:    attempts,
+		FailedAt:    time.Now(),
+		LastCode:    statusCode,
+	}
+	if lastErr != nil {
+		letter.LastError = lastErr.Error()
+	}
+	if err := d.deadLetter.Append(letter); err != nil {
+		d.logAttempt(eventType, endpoint.URL, 0, "Dead letter failed", 0, err, 0)
+	}
+
+	d.mu.Lock()
+	d.undelivered = append(d.undelivered, letter)
+	d.mu.Unlock()
+}
+
+// ReplayResult summarises a replay of the dead-letter queue
+type ReplayResult struct {
+	Delivered int
+	Failed    int
+	Remaining int
+}
+
+// DeadLetters returns the dead-letter queue of this dispatcher
+func (d *HTTPDispatcher) DeadLetters() *DeadLetterQueue {
+	return d.deadLetter
+}
+
+// Replay resends the dead letters matching the filter, once each. Delivered
+// letters are removed from the queue; failed ones stay with their attempt
+// count and last error updated. Endpoints that are still configured are
+// replayed with their current headers and secret, so signatures are fresh.
+func (d *HTTPDispatcher) Replay(filter DeadLetterFilter) (ReplayResult, error) {
+	var result ReplayResult
+
+	letters, err := d.deadLetter.List()
+	if err != nil {
+		return result, err
+	}
+
+	var delivered []string
+	var failed []DeadLetter
+	for _, letter := range letters {
+		if !filter.Matches(letter) {
+			result.Remaining++
+			continue
+		}
+
+		ep := d.endpointFor(letter.Target)
+		// The stored content type wins, the template may have changed since.
+		// Older or hand-edited letters without one keep the endpoint's type.
+		if letter.ContentType != "" {
+			ep.contentType = letter.ContentType
+		}
+
+		start := time.Now()
+		code, err := d.sendOnce(ep, []byte(letter.Body))
+		duration := time.Since(start)
+
+		letter.Attempts++
+		if err == nil && code >= 200 && code < 300 {
+			d.logAttempt(letter.Event, letter.Target, letter.Attempts, "Replayed", code, nil, duration)
+			delivered = append(delivered, letter.ID)
+			result.Delivered++
+			continue
+		}
+
+		if err == nil {
+			err = fmt.Errorf("unexpected status %d", code)
+		}
+		d.logAttempt(letter.Event, letter.Target, letter.Attempts, "Replay failed", code, err, duration)
+		letter.FailedAt = time.Now()
+		letter.LastCode = code
+		letter.LastError = err.Error()
+		failed = append(failed, letter)
+		result.Failed++
+	}
+	result.Remaining += result.Failed
+
+	if len(delivered) == 0 && len(failed) == 0 {
+		return result, nil
+	}
+	return result, d.deadLetter.Update(failed, delivered)
+}
+
+// Test sends a sample event to url synchronously and reports the response status.
+// A configured endpoint with the same URL is used as-is, including its template and secret.
+func (d *HTTPDispatcher) Test(url string) (int, time.Duration, error) {
+	ep := d.endpointFor(url)
+
+	sample := WorkStart{
+		Tasks:                  []string{"Write the release notes", "Review open pull requests", "Reply to the test webhook"},
+		TasksCount:             3,
+		PlannedDurationSeconds: 25 * 60,
+	}
+	body, err := ep.render(ep.prepare(d.newPayload(sample, time.Now())))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	start := time.Now()
+	code, err := d.sendOnce(ep, body)
+	duration := time.Since(start)
+	d.logAttempt(EventWorkStart, url, 1, "Test", code, err, duration)
+
+	return code, duration, err
+}
+
+// endpointFor returns a copy of the configured endpoint for url, or a plain
+// JSON endpoint when the URL is not (or no longer) configured
+func (d *HTTPDispatcher) endpointFor(url string) *endpoint {
+	for _, ep := range d.endpoints {
+		if ep.URL == url {
+			copied := *ep
+			return &copied
+		}
+	}
+	ep, _ := newEndpoint(config.Webhook{URL: url})
+	return ep
 }
 
 // Wait blocks until all active webhooks have been sent or exhausted their retries
@@ -125,13 +358,52 @@ func (d *HTTPDispatcher) Wait() {
 	d.wg.Wait()
 }
 
-func (d *HTTPDispatcher) sendOnce(url string, body []byte) (int, error) {
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+// Shutdown waits up to timeout for in-flight deliveries, then cuts the
+// remaining ones short into the dead-letter queue and closes the log. It
+// returns every delivery of this session that did not go through.
+func (d *HTTPDispatcher) Shutdown(timeout time.Duration) []DeadLetter {
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		d.cancel()
+		<-done
+	}
+	d.cancel()
+	d.Close()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]DeadLetter(nil), d.undelivered...)
+}
+
+func (d *HTTPDispatcher) sendOnce(endpoint *endpoint, body []byte) (int, error) {
+	timeout := endpoint.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(d.stop, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint.URL, bytes.NewBuffer(body))
 	if err != nil {
 		return 0, err
 	}
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", endpoint.contentType)
 	req.Header.Set("User-Agent", "Tomatick-Webhook-Dispatcher/1.0")
+	for key, value := range endpoint.headers {
+		req.Header.Set(key, value)
+	}
+
+	// Signed per attempt, so retries carry a fresh timestamp
+	if endpoint.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(endpoint.Secret, time.Now(), body))
+	}
 
 	resp, err := d.client.Do(req)
 	if err != nil {
@@ -152,7 +424,7 @@ func (d *HTTPDispatcher) logAttempt(event EventType, target string, attempt int,
 		errStr = err.Error()
 	}
 
-	logEntry := dispatchLog{
+	logEntry := LogEntry{
 		Timestamp: time.Now().Format(time.RFC3339),
 		Event:     event,
 		Target:    target,
@@ -0,0 +1,288 @@
+package webhook
+
+import "time"
+
+// Event is the typed data of a webhook event. Fields carrying user content
+// (task text, AI output, contexts) are cleared by Redacted and only delivered
+// to endpoints that opt in with include_sensitive.
+type Event interface {
+	EventType() EventType
+	Redacted() Event
+}
+
+// WorkStart is sent when a focus session begins
+type WorkStart struct {
+	Tasks                  []string `json:"tasks,omitempty"`
+	TasksCount             int      `json:"tasks_count"`
+	PlannedDurationSeconds int      `json:"planned_duration_seconds"`
+}
+
+func (e WorkStart) EventType() EventType { return EventWorkStart }
+
+func (e WorkStart) Redacted() Event {
+	e.Tasks = nil
+	return e
+}
+
+// WorkComplete is sent when the focus timer runs out
+type WorkComplete struct {
+	Tasks                  []string `json:"tasks,omitempty"`
+	TasksCount             int      `json:"tasks_count"`
+	PlannedDurationSeconds int      `json:"planned_duration_seconds"`
+}
+
+func (e WorkComplete) EventType() EventType { return EventWorkComplete }
+
+func (e WorkComplete) Redacted() Event {
+	e.Tasks = nil
+	return e
+}
+
+// Break kinds
+const (
+	BreakShort = "short"
+	BreakLong  = "long"
+)
+
+// BreakStart is sent when a short or long break begins
+type BreakStart struct {
+	BreakType              string `json:"type"`
+	PlannedDurationSeconds int    `json:"planned_duration_seconds"`
+}
+
+func (e BreakStart) EventType() EventType { return EventBreakStart }
+
+func (e BreakStart) Redacted() Event { return e }
+
+// BreakEnd is sent when a break is over
+type BreakEnd struct {
+	BreakType              string `json:"type"`
+	PlannedDurationSeconds int    `json:"planned_duration_seconds"`
+}
+
+func (e BreakEnd) EventType() EventType { return EventBreakEnd }
+
+func (e BreakEnd) Redacted() Event { return e }
+
+// ContextRefined is sent when the copilot has refined the session context
+type ContextRefined struct {
+	OriginalLength int    `json:"original_length"`
+	RefinedLength  int    `json:"refined_length"`
+	RefinedContext string `json:"refined_context,omitempty"`
+}
+
+func (e ContextRefined) EventType() EventType { return EventContextRefined }
+
+func (e ContextRefined) Redacted() Event {
+	e.RefinedContext = ""
+	return e
+}
+
+// AISuggestions is sent when the copilot has suggested tasks
+type AISuggestions struct {
+	SuggestionsCount int      `json:"suggestions_count"`
+	Suggestions      []string `json:"suggestions,omitempty"`
+}
+
+func (e AISuggestions) EventType() EventType { return EventAISuggestions }
+
+func (e AISuggestions) Redacted() Event {
+	e.Suggestions = nil
+	return e
+}
+
+// AIAnalysis is sent when the copilot has analysed a finished cycle
+type AIAnalysis struct {
+	Analysis string   `json:"analysis,omitempty"`
+	Tasks    []string `json:"tasks,omitempty"`
+}
+
+func (e AIAnalysis) EventType() EventType { return EventAIAnalysis }
+
+func (e AIAnalysis) Redacted() Event {
+	e.Analysis = ""
+	e.Tasks = nil
+	return e
+}
+
+// Chat topics of AIChatExchange
+const (
+	ChatSuggestionDiscussion = "suggestion_discussion"
+	ChatAnalysisDiscussion   = "analysis_discussion"
+)
+
+// AIChatExchange is sent for every message exchanged with the copilot
+type AIChatExchange struct {
+	Context    string `json:"context"`
+	UserInput  string `json:"user_input,omitempty"`
+	AIResponse string `json:"ai_response,omitempty"`
+}
+
+func (e AIChatExchange) EventType() EventType { return EventAIChatExchange }
+
+func (e AIChatExchange) Redacted() Event {
+	e.UserInput = ""
+	e.AIResponse = ""
+	return e
+}
+
+// SessionSummary is sent when a cycle summary is appended to mem.ai
+type SessionSummary struct {
+	MemID   string `json:"mem_id"`
+	Content string `json:"content,omitempty"`
+}
+
+func (e SessionSummary) EventType() EventType { return EventSessionSummary }
+
+func (e SessionSummary) Redacted() Event {
+	e.Content = ""
+	return e
+}
+
+// SessionStart is sent once when tomatick starts, with the timer settings in use
+type SessionStart struct {
+	WorkDurationSeconds       int `json:"work_duration_seconds"`
+	ShortBreakDurationSeconds int `json:"short_break_duration_seconds"`
+	LongBreakDurationSeconds  int `json:"long_break_duration_seconds"`
+	CyclesBeforeLongBreak     int `json:"cycles_before_long_break"`
+}
+
+func (e SessionStart) EventType() EventType { return EventSessionStart }
+
+func (e SessionStart) Redacted() Event { return e }
+
+// SessionEnd is sent when the user ends the workday
+type SessionEnd struct {
+	CyclesCompleted int `json:"cycles_completed"`
+	WorkSeconds     int `json:"work_seconds"`
+	DurationSeconds int `json:"duration_seconds"` // wall-clock time since session_start
+}
+
+func (e SessionEnd) EventType() EventType { return EventSessionEnd }
+
+func (e SessionEnd) Redacted() Event { return e }
+
+// Context sources of ContextLoaded
+const (
+	ContextFromFile     = "file"
+	ContextFromTemplate = "template"
+	ContextFromPrevious = "previous_session"
+	ContextFromGit      = "git"
+	ContextFromInput    = "input"
+)
+
+// ContextLoaded is sent when the session context has been set up
+type ContextLoaded struct {
+	Source      string   `json:"source"`
+	Structured  bool     `json:"structured"`
+	GoalsCount  int      `json:"goals_count"`
+	Tags        []string `json:"tags,omitempty"`
+	HasSchedule bool     `json:"has_schedule"`
+	Project     string   `json:"project,omitempty"`
+}
+
+func (e ContextLoaded) EventType() EventType { return EventContextLoaded }
+
+func (e ContextLoaded) Redacted() Event {
+	e.Project = ""
+	return e
+}
+
+// Task sources of TaskAdded
+const (
+	TaskFromInput      = "input"
+	TaskFromSuggestion = "suggestion"
+	TaskFromAPI        = "api"
+)
+
+// TaskAdded is sent for every task planned for a cycle
+type TaskAdded struct {
+	Source string `json:"source"`
+	Task   string `json:"task,omitempty"`
+}
+
+func (e TaskAdded) EventType() EventType { return EventTaskAdded }
+
+func (e TaskAdded) Redacted() Event {
+	e.Task = ""
+	return e
+}
+
+// TaskCompleted is sent for every task marked done in the progress check
+type TaskCompleted struct {
+	Task string `json:"task,omitempty"`
+}
+
+func (e TaskCompleted) EventType() EventType { return EventTaskCompleted }
+
+func (e TaskCompleted) Redacted() Event {
+	e.Task = ""
+	return e
+}
+
+// Timer phases
+const (
+	PhaseWork       = "work"
+	PhaseShortBreak = "short_break"
+	PhaseLongBreak  = "long_break"
+)
+
+// TimerPaused is sent when the user pauses a running timer
+type TimerPaused struct {
+	Phase            string `json:"phase"`
+	ElapsedSeconds   int    `json:"elapsed_seconds"`
+	RemainingSeconds int    `json:"remaining_seconds"`
+}
+
+func (e TimerPaused) EventType() EventType { return EventTimerPaused }
+
+func (e TimerPaused) Redacted() Event { return e }
+
+// TimerResumed is sent when a paused timer continues
+type TimerResumed struct {
+	Phase            string `json:"phase"`
+	ElapsedSeconds   int    `json:"elapsed_seconds"`
+	RemainingSeconds int    `json:"remaining_seconds"`
+	PausedSeconds    int    `json:"paused_seconds"`
+}
+
+func (e TimerResumed) EventType() EventType { return EventTimerResumed }
+
+func (e TimerResumed) Redacted() Event { return e }
+
+// TimerAborted is sent when a timer is stopped before it runs out. The
+// phase's work_complete or break_end event still follows.
+type TimerAborted struct {
+	Phase            string `json:"phase"`
+	ElapsedSeconds   int    `json:"elapsed_seconds"`
+	RemainingSeconds int    `json:"remaining_seconds"`
+}
+
+func (e TimerAborted) EventType() EventType { return EventTimerAborted }
+
+func (e TimerAborted) Redacted() Event { return e }
+
+// LongBreakDue is sent when enough cycles have passed for a long break
+type LongBreakDue struct {
+	CyclesSinceLongBreak  int `json:"cycles_since_long_break"`
+	CyclesBeforeLongBreak int `json:"cycles_before_long_break"`
+}
+
+func (e LongBreakDue) EventType() EventType { return EventLongBreakDue }
+
+func (e LongBreakDue) Redacted() Event { return e }
+
+// BreakViolation is sent when the break monitor sees work during a break
+type BreakViolation struct {
+	ViolationsCount int       `json:"violations_count"`
+	FirstAt         time.Time `json:"first_at"`
+	LastAt          time.Time `json:"last_at"`
+	Details         []string  `json:"details,omitempty"`
+}
+
+func (e BreakViolation) EventType() EventType { return EventBreakViolation }
+
+func (e BreakViolation) Redacted() Event {
+	e.Details = nil
+	return e
+}
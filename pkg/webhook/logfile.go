@@ -0,0 +1,339 @@
+package webhook
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+const logFileName = "webhooks.log"
+
+// rotatingLog is an append-only log file that is rotated once it grows past
+// the configured size or age. Rotated files are named webhooks-<time>.log.
+type rotatingLog struct {
+	mu       sync.Mutex
+	dir      string
+	rotation config.LogRotation
+	file     *os.File
+	size     int64
+	started  time.Time // timestamp of the first entry in the current file
+}
+
+func openRotatingLog(dir string, rotation config.LogRotation) (*rotatingLog, error) {
+	l := &rotatingLog{dir: dir, rotation: rotation}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	if l.due(time.Now()) {
+		if err := l.rotate(); err != nil && l.file == nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (l *rotatingLog) open() error {
+	path := filepath.Join(l.dir, logFileName)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	l.file = f
+	l.size = info.Size()
+	l.started = time.Time{}
+	if l.size > 0 {
+		l.started = firstEntryTime(path, info.ModTime())
+	}
+	return nil
+}
+
+// firstEntryTime reads the timestamp of the first entry, falling back to fallback
+func firstEntryTime(path string, fallback time.Time) time.Time {
+	f, err := os.Open(path)
+	if err != nil {
+		return fallback
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		return fallback
+	}
+	var entry LogEntry
+	if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+		return fallback
+	}
+	if t, err := time.Parse(time.RFC3339, entry.Timestamp); err == nil {
+		return t
+	}
+	return fallback
+}
+
+// due reports whether the current file should be rotated before writing
+func (l *rotatingLog) due(now time.Time) bool {
+	if l.size == 0 {
+		return false
+	}
+	if l.rotation.MaxSize > 0 && l.size >= l.rotation.MaxSize {
+		return true
+	}
+	return l.rotation.MaxAge > 0 && !l.started.IsZero() && now.Sub(l.started) >= l.rotation.MaxAge
+}
+
+// rotate moves the current file aside and starts a new one. When that
+// fails, the file holding the latest entries is reopened, so logging goes
+// on without rotation; l.file is nil only if even that fails.
+func (l *rotatingLog) rotate() error {
+	current := filepath.Join(l.dir, logFileName)
+	if err := l.file.Close(); err != nil {
+		return l.resume(current, err)
+	}
+
+	rotated := filepath.Join(l.dir, fmt.Sprintf("webhooks-%s.log", time.Now().Format("20060102-150405.000")))
+	if err := os.Rename(current, rotated); err != nil {
+		return l.resume(current, err)
+	}
+
+	l.prune()
+	if err := l.open(); err != nil {
+		return l.resume(rotated, err)
+	}
+	return nil
+}
+
+// resume reopens path in append mode after a failed rotation
+func (l *rotatingLog) resume(path string, cause error) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		l.file = nil
+		return fmt.Errorf("failed to rotate webhook log: %w (and to reopen it: %v)", cause, err)
+	}
+	l.file = f
+	return fmt.Errorf("failed to rotate webhook log: %w", cause)
+}
+
+// prune removes the oldest rotated files beyond MaxBackups
+func (l *rotatingLog) prune() {
+	if l.rotation.MaxBackups <= 0 {
+		return
+	}
+	rotated, err := rotatedLogFiles(l.dir)
+	if err != nil || len(rotated) <= l.rotation.MaxBackups {
+		return
+	}
+	for _, path := range rotated[:len(rotated)-l.rotation.MaxBackups] {
+		os.Remove(path)
+	}
+}
+
+func (l *rotatingLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	now := time.Now()
+	if l.due(now) {
+		// Rotation is retried on the next write; the entry goes to the
+		// reopened file meanwhile
+		if err := l.rotate(); err != nil && l.file == nil {
+			return 0, err
+		}
+	}
+	if l.size == 0 {
+		l.started = now
+	}
+
+	n, err := l.file.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+func (l *rotatingLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// rotatedLogFiles lists rotated logs in dir, oldest first
+func rotatedLogFiles(dir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "webhooks-*.log"))
+	if err != nil {
+		return nil, err
+	}
+	// The timestamp in the name sorts chronologically
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// LogEntry is one delivery attempt in the webhook log
+type LogEntry struct {
+	Timestamp string    `json:"timestamp"`
+	Event     EventType `json:"event"`
+	Target    string    `json:"target"`
+	Attempt   int       `json:"attempt"`
+	Status    string    `json:"status"`
+	Code      int       `json:"code,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+}
+
+// Time parses the entry timestamp
+func (e LogEntry) Time() time.Time {
+	t, _ := time.Parse(time.RFC3339, e.Timestamp)
+	return t
+}
+
+// Latency parses the entry duration
+func (e LogEntry) Latency() time.Duration {
+	d, _ := time.ParseDuration(e.Duration)
+	return d
+}
+
+// Delivered reports whether the attempt reached the endpoint with a 2xx status
+func (e LogEntry) Delivered() bool {
+	return e.Code >= 200 && e.Code < 300 && e.Error == ""
+}
+
+// ReadLog returns the entries at or after since from the current and rotated
+// logs in logDir, oldest first. Unparseable lines are skipped.
+func ReadLog(logDir string, since time.Time) ([]LogEntry, error) {
+	files, err := rotatedLogFiles(logDir)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, filepath.Join(logDir, logFileName))
+
+	var entries []LogEntry
+	for _, path := range files {
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var entry LogEntry
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				continue
+			}
+			if !since.IsZero() && entry.Time().Before(since) {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time().Before(entries[j].Time())
+	})
+	return entries, nil
+}
+
+// TargetSummary aggregates the log entries of one endpoint
+type TargetSummary struct {
+	Target    string
+	Attempts  int
+	Delivered int
+	Retried   int // attempts that failed and were retried
+	Failed    int // deliveries that exhausted their retries
+	P50       time.Duration
+	P95       time.Duration
+	Entries   []LogEntry // oldest first
+}
+
+// SummarizeLog groups entries by target, in order of each target's latest entry, most recent first
+func SummarizeLog(entries []LogEntry) []TargetSummary {
+	index := make(map[string]int)
+	var summaries []TargetSummary
+	latencies := make(map[string][]time.Duration)
+
+	for _, entry := range entries {
+		i, ok := index[entry.Target]
+		if !ok {
+			i = len(summaries)
+			index[entry.Target] = i
+			summaries = append(summaries, TargetSummary{Target: entry.Target})
+		}
+		s := &summaries[i]
+		s.Entries = append(s.Entries, entry)
+
+		// Entries without an attempt number are bookkeeping, e.g. render failures
+		if entry.Attempt > 0 {
+			s.Attempts++
+			if latency := entry.Latency(); latency > 0 {
+				latencies[entry.Target] = append(latencies[entry.Target], latency)
+			}
+		}
+		switch {
+		case entry.Status == "Retrying":
+			s.Retried++
+		case entry.Delivered():
+			s.Delivered++
+		case entry.Status == "Failed" || entry.Status == "Replay failed":
+			s.Failed++
+		}
+	}
+
+	for i := range summaries {
+		l := latencies[summaries[i].Target]
+		sort.Slice(l, func(a, b int) bool { return l[a] < l[b] })
+		summaries[i].P50 = percentile(l, 0.50)
+		summaries[i].P95 = percentile(l, 0.95)
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		last := func(s TargetSummary) time.Time { return s.Entries[len(s.Entries)-1].Time() }
+		return last(summaries[i]).After(last(summaries[j]))
+	})
+	return summaries
+}
+
+// percentile of sorted durations, nearest-rank
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
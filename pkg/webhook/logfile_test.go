@@ -0,0 +1,243 @@
+package webhook
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+// writeLines writes each line to the log and fails the test on an error
+func writeLines(t *testing.T, l *rotatingLog, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if _, err := l.Write([]byte(line + "\n")); err != nil {
+			t.Fatalf("Write(%q): %v", line, err)
+		}
+	}
+}
+
+func TestRotatingLogRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	l, err := openRotatingLog(dir, config.LogRotation{MaxSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	writeLines(t, l, "first entry", "second entry")
+
+	rotated, _ := rotatedLogFiles(dir)
+	if len(rotated) != 1 {
+		t.Fatalf("rotated files = %v, want 1", rotated)
+	}
+	current, _ := os.ReadFile(filepath.Join(dir, logFileName))
+	if string(current) != "second entry\n" {
+		t.Errorf("current log = %q", current)
+	}
+}
+
+func TestRotatingLogKeepsLoggingWhenRotationFails(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can rename in a read-only directory")
+	}
+	dir := t.TempDir()
+	l, err := openRotatingLog(dir, config.LogRotation{MaxSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	writeLines(t, l, "first entry")
+	if err := os.Chmod(dir, 0500); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(dir, 0700)
+
+	// Rotation can't rename in a read-only directory; entries keep going
+	// to the current file, which is still writable
+	writeLines(t, l, "second entry", "third entry")
+
+	current, _ := os.ReadFile(filepath.Join(dir, logFileName))
+	if string(current) != "first entry\nsecond entry\nthird entry\n" {
+		t.Errorf("current log = %q", current)
+	}
+
+	// Rotation resumes once the directory is writable again
+	os.Chmod(dir, 0700)
+	writeLines(t, l, "fourth entry")
+	if rotated, _ := rotatedLogFiles(dir); len(rotated) != 1 {
+		t.Errorf("rotated files = %v, want 1", rotated)
+	}
+}
+
+func TestRotatingLogRecreatesRemovedFile(t *testing.T) {
+	dir := t.TempDir()
+	l, err := openRotatingLog(dir, config.LogRotation{MaxSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	writeLines(t, l, "first entry")
+	// Someone cleaned up the log directory, so the rename finds nothing
+	if err := os.Remove(filepath.Join(dir, logFileName)); err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, l, "second entry")
+
+	current, err := os.ReadFile(filepath.Join(dir, logFileName))
+	if err != nil || !strings.Contains(string(current), "second entry") {
+		t.Errorf("current log = %q, %v; want the entry after the failed rotation", current, err)
+	}
+}
+
+// logLine encodes one log entry the way the dispatcher writes it
+func logLine(t *testing.T, at time.Time, target string, attempt int, status string, code int, latency time.Duration) string {
+	t.Helper()
+	line, err := json.Marshal(LogEntry{
+		Timestamp: at.Format(time.RFC3339),
+		Event:     EventWorkStart,
+		Target:    target,
+		Attempt:   attempt,
+		Status:    status,
+		Code:      code,
+		Duration:  latency.String(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(line) + "\n"
+}
+
+func TestReadLog(t *testing.T) {
+	const hook = "https://example.com/hook"
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		since   time.Time
+		wantLen int
+	}{
+		{name: "no log", wantLen: 0},
+		{name: "empty log", files: map[string]string{logFileName: ""}, wantLen: 0},
+		{name: "single entry", files: map[string]string{
+			logFileName: logLine(t, base, hook, 1, "Delivered", 200, time.Second),
+		}, wantLen: 1},
+		{name: "rotated and current", files: map[string]string{
+			"webhooks-20260301-090000.000.log": logLine(t, base, hook, 1, "Delivered", 200, time.Second),
+			logFileName:                        logLine(t, base.Add(time.Hour), hook, 1, "Delivered", 200, time.Second),
+		}, wantLen: 2},
+		{name: "since excludes older entries", files: map[string]string{
+			logFileName: logLine(t, base, hook, 1, "Delivered", 200, time.Second) +
+				logLine(t, base.Add(time.Hour), hook, 1, "Delivered", 200, time.Second),
+		}, since: base.Add(time.Hour), wantLen: 1},
+		{name: "unparseable lines skipped", files: map[string]string{
+			logFileName: "not json\n\n" + logLine(t, base, hook, 1, "Delivered", 200, time.Second),
+		}, wantLen: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			entries, err := ReadLog(dir, tt.since)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != tt.wantLen {
+				t.Fatalf("entries = %+v, want %d", entries, tt.wantLen)
+			}
+			for i := 1; i < len(entries); i++ {
+				if entries[i].Time().Before(entries[i-1].Time()) {
+					t.Errorf("entries not oldest first: %+v", entries)
+				}
+			}
+		})
+	}
+}
+
+func TestSummarizeLog(t *testing.T) {
+	const (
+		slack = "https://hooks.slack.com/services/x"
+		ntfy  = "https://ntfy.sh/focus"
+	)
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	entry := func(minute int, target string, attempt int, status string, code int, latency time.Duration) LogEntry {
+		return LogEntry{
+			Timestamp: base.Add(time.Duration(minute) * time.Minute).Format(time.RFC3339),
+			Target:    target,
+			Attempt:   attempt,
+			Status:    status,
+			Code:      code,
+			Duration:  latency.String(),
+		}
+	}
+
+	// Twenty deliveries of 10ms to 200ms
+	var twenty []LogEntry
+	for i := 1; i <= 20; i++ {
+		twenty = append(twenty, entry(i, slack, 1, "Delivered", 200, time.Duration(i)*10*time.Millisecond))
+	}
+
+	tests := []struct {
+		name    string
+		entries []LogEntry
+		want    []TargetSummary // Entries are checked separately
+	}{
+		{name: "empty log", entries: nil, want: nil},
+		{name: "single entry",
+			entries: []LogEntry{entry(0, slack, 1, "Delivered", 200, 120*time.Millisecond)},
+			want:    []TargetSummary{{Target: slack, Attempts: 1, Delivered: 1, P50: 120 * time.Millisecond, P95: 120 * time.Millisecond}}},
+		{name: "percentiles", entries: twenty,
+			want: []TargetSummary{{Target: slack, Attempts: 20, Delivered: 20, P50: 100 * time.Millisecond, P95: 190 * time.Millisecond}}},
+		{name: "per target, most recent first",
+			entries: []LogEntry{
+				entry(0, slack, 1, "Retrying", 503, 40*time.Millisecond),
+				entry(1, ntfy, 1, "Delivered", 200, 30*time.Millisecond),
+				entry(2, slack, 2, "Failed", 503, 60*time.Millisecond),
+				entry(3, ntfy, 0, "Render failed", 0, 0),
+			},
+			want: []TargetSummary{
+				{Target: ntfy, Attempts: 1, Delivered: 1, P50: 30 * time.Millisecond, P95: 30 * time.Millisecond},
+				{Target: slack, Attempts: 2, Retried: 1, Failed: 1, P50: 40 * time.Millisecond, P95: 60 * time.Millisecond},
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SummarizeLog(tt.entries)
+			if len(got) != len(tt.want) {
+				t.Fatalf("summaries = %+v, want %d", got, len(tt.want))
+			}
+			total := 0
+			for i, want := range tt.want {
+				s := got[i]
+				total += len(s.Entries)
+				for _, e := range s.Entries {
+					if e.Target != s.Target {
+						t.Errorf("%s summary has an entry for %s", s.Target, e.Target)
+					}
+				}
+				s.Entries = nil
+				if !reflect.DeepEqual(s, want) {
+					t.Errorf("summary %d = %+v, want %+v", i, s, want)
+				}
+			}
+			if total != len(tt.entries) {
+				t.Errorf("summaries hold %d entries, want %d", total, len(tt.entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+// mqttQueueSize bounds the messages waiting for the broker
+const mqttQueueSize = 256
+
+var errQueueFull = errors.New("mqtt publish queue is full")
+
+// States of the retained state topic besides the timer phases
+const (
+	StateIdle   = "idle"
+	StatePaused = "paused"
+	StateEnded  = "ended"
+)
+
+// MQTTState is the retained message on <prefix>/state. It always describes
+// what tomatick is doing right now, so presence lights and signage that
+// subscribe late still get the current phase.
+type MQTTState struct {
+	Phase     string     `json:"phase"` // idle, work, short_break, long_break or ended
+	Paused    bool       `json:"paused"`
+	SessionID string     `json:"session_id"`
+	Cycle     int        `json:"cycle"`
+	Since     time.Time  `json:"since"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"` // unset while paused or idle
+}
+
+// label is the plain-text value of <prefix>/phase
+func (s MQTTState) label() string {
+	if s.Paused {
+		return StatePaused
+	}
+	return s.Phase
+}
+
+// mqttMessage is a queued publish
+type mqttMessage struct {
+	event     EventType
+	timestamp time.Time
+	topic     string
+	payload   []byte
+	retain    bool
+}
+
+// MQTTDispatcher implements the Dispatcher interface for an MQTT broker.
+// Events are published to <prefix>/events/<type>. The retained topics
+// <prefix>/state (JSON) and <prefix>/phase (plain text) hold the current
+// phase, and <prefix>/status is "online" while connected, with "offline"
+// as the last will. Messages are published in order by a single worker.
+type MQTTDispatcher struct {
+	config.MQTT
+	session
+
+	queue   chan mqttMessage
+	pending sync.WaitGroup
+	done    chan struct{}
+
+	// stop is cancelled when Shutdown runs out of time, aborting retries
+	stop   context.Context
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	state       MQTTState
+	closed      bool
+	undelivered []DeadLetter
+}
+
+// NewMQTTDispatcher creates a dispatcher for the broker in cfg. The session
+// ID is shared with the other dispatchers of this run. It connects lazily,
+// on the first event.
+func NewMQTTDispatcher(cfg config.MQTT, sessionID string) *MQTTDispatcher {
+	for _, event := range cfg.Events {
+		if !IsKnownEvent(EventType(event)) {
+			fmt.Printf("Warning: MQTT_EVENTS subscribes to unknown event %q\n", event)
+		}
+	}
+	if cfg.ClientID == "" {
+		cfg.ClientID = "tomatick-" + sessionID
+	}
+	if cfg.KeepAlive <= 0 {
+		cfg.KeepAlive = time.Minute
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	cfg.TopicPrefix = strings.TrimSuffix(cfg.TopicPrefix, "/")
+
+	stop, cancel := context.WithCancel(context.Background())
+
+	d := &MQTTDispatcher{
+		MQTT:    cfg,
+		session: session{id: sessionID},
+		queue:   make(chan mqttMessage, mqttQueueSize),
+		done:    make(chan struct{}),
+		stop:    stop,
+		cancel:  cancel,
+		state:   MQTTState{Phase: StateIdle, SessionID: sessionID, Since: time.Now()},
+	}
+	go d.run()
+	return d
+}
+
+func (d *MQTTDispatcher) topic(parts ...string) string {
+	return d.TopicPrefix + "/" + strings.Join(parts, "/")
+}
+
+// Dispatch queues the event and, when the phase changes, the new state
+func (d *MQTTDispatcher) Dispatch(event Event) {
+	payload := d.newPayload(event, time.Now())
+	if !d.IncludeSensitive {
+		payload.Data = payload.Data.Redacted()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+
+	if subscribes(d.Events, payload.Type) {
+		if body, err := json.Marshal(payload); err == nil {
+			d.enqueue(mqttMessage{event: payload.Type, timestamp: payload.Timestamp, topic: d.topic("events", string(payload.Type)), payload: body})
+		}
+	}
+
+	state, changed := nextState(d.state, payload)
+	if !changed {
+		return
+	}
+	d.state = state
+	body, _ := json.Marshal(state)
+	d.enqueue(mqttMessage{event: payload.Type, timestamp: payload.Timestamp, topic: d.topic("state"), payload: body, retain: true})
+	d.enqueue(mqttMessage{event: payload.Type, timestamp: payload.Timestamp, topic: d.topic("phase"), payload: []byte(state.label()), retain: true})
+}
+
+// State returns the state last published to <prefix>/state
+func (d *MQTTDispatcher) State() MQTTState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.state
+}
+
+// enqueue never blocks the caller; d.mu must be held. The message is
+// counted as pending before it is queued, as the worker may deliver it at once.
+func (d *MQTTDispatcher) enqueue(msg mqttMessage) {
+	d.pending.Add(1)
+	select {
+	case d.queue <- msg:
+	default:
+		d.pending.Done()
+		d.undelivered = append(d.undelivered, d.deadLetter(msg, 0, errQueueFull))
+	}
+}
+
+// nextState applies an event to the current state. It reports false for
+// events that don't move the timer.
+func nextState(state MQTTState, payload EventPayload) (MQTTState, bool) {
+	at := payload.Timestamp
+	phase := func(name string, seconds int) MQTTState {
+		next := MQTTState{Phase: name, SessionID: payload.SessionID, Cycle: payload.Cycle, Since: at}
+		if seconds > 0 {
+			ends := at.Add(time.Duration(seconds) * time.Second)
+			next.EndsAt = &ends
+		}
+		return next
+	}
+
+	switch e := payload.Data.(type) {
+	case SessionStart, WorkComplete, BreakEnd, TimerAborted:
+		return phase(StateIdle, 0), true
+	case WorkStart:
+		return phase(PhaseWork, e.PlannedDurationSeconds), true
+	case BreakStart:
+		if e.BreakType == BreakLong {
+			return phase(PhaseLongBreak, e.PlannedDurationSeconds), true
+		}
+		return phase(PhaseShortBreak, e.PlannedDurationSeconds), true
+	case SessionEnd:
+		return phase(StateEnded, 0), true
+	case TimerPaused:
+		state.Paused = true
+		state.EndsAt = nil
+		return state, true
+	case TimerResumed:
+		ends := at.Add(time.Duration(e.RemainingSeconds) * time.Second)
+		state.Paused = false
+		state.EndsAt = &ends
+		return state, true
+	}
+	return state, false
+}
+
+// run publishes queued messages in order and keeps the connection alive
+func (d *MQTTDispatcher) run() {
+	defer close(d.done)
+
+	var conn *mqttConn
+	ticker := time.NewTicker(d.KeepAlive / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-d.queue:
+			if !ok {
+				if conn != nil {
+					if d.stop.Err() == nil {
+						conn.publish(d.topic("status"), []byte("offline"), d.QoS, true)
+					}
+					conn.disconnect()
+				}
+				return
+			}
+			conn = d.deliver(conn, msg)
+			d.pending.Done()
+		case <-ticker.C:
+			if conn != nil {
+				if err := conn.ping(); err != nil {
+					conn.close()
+					conn = nil
+				}
+			}
+		}
+	}
+}
+
+// deliver publishes msg, reconnecting and retrying with exponential
+// backoff. It returns the connection to use for the next message.
+func (d *MQTTDispatcher) deliver(conn *mqttConn, msg mqttMessage) *mqttConn {
+	err := d.stop.Err()
+	if err != nil {
+		err = errShutdown
+	}
+
+	attempts := 0
+retries:
+	for attempt := 1; err != errShutdown && attempt <= d.MaxRetries+1; attempt++ {
+		attempts = attempt
+		if conn == nil {
+			conn, err = d.connect()
+		}
+		if conn != nil {
+			if err = conn.publish(msg.topic, msg.payload, d.QoS, msg.retain); err == nil {
+				return conn
+			}
+			conn.close()
+			conn = nil
+		}
+
+		if attempt <= d.MaxRetries {
+			select {
+			case <-time.After(d.RetryBaseDelay << (attempt - 1)):
+			case <-d.stop.Done():
+				err = errShutdown
+				break retries
+			}
+		}
+	}
+
+	d.mu.Lock()
+	d.undelivered = append(d.undelivered, d.deadLetter(msg, attempts, err))
+	d.mu.Unlock()
+	return nil
+}
+
+// connect opens a session and marks tomatick online
+func (d *MQTTDispatcher) connect() (*mqttConn, error) {
+	conn, err := dialMQTT(d.stop, d.Broker, d.Timeout, mqttConnectOptions{
+		clientID:  d.ClientID,
+		username:  d.Username,
+		password:  d.Password,
+		keepAlive: d.KeepAlive,
+		will:      &mqttWill{topic: d.topic("status"), payload: []byte("offline"), qos: d.QoS, retain: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.publish(d.topic("status"), []byte("online"), d.QoS, true); err != nil {
+		conn.close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (d *MQTTDispatcher) deadLetter(msg mqttMessage, attempts int, err error) DeadLetter {
+	letter := DeadLetter{
+		ID:          newID(),
+		Event:       msg.event,
+		EventTime:   msg.timestamp,
+		Target:      strings.TrimSuffix(d.Broker, "/") + "/" + msg.topic,
+		ContentType: "application/json",
+		Body:        string(msg.payload),
+		Attempts:    attempts,
+		FailedAt:    time.Now(),
+	}
+	if err != nil {
+		letter.LastError = err.Error()
+	}
+	return letter
+}
+
+// Wait blocks until every queued message has been published or given up on
+func (d *MQTTDispatcher) Wait() {
+	d.pending.Wait()
+}
+
+// Shutdown publishes what is queued for at most timeout, marks tomatick
+// offline and disconnects. It returns the messages that were not published;
+// they are not kept, a later replay would resurrect a stale state.
+func (d *MQTTDispatcher) Shutdown(timeout time.Duration) []DeadLetter {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-d.done:
+	case <-time.After(timeout):
+		d.cancel()
+		<-d.done
+	}
+	d.cancel()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]DeadLetter(nil), d.undelivered...)
+}
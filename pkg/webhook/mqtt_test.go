@@ -0,0 +1,374 @@
+package webhook
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+// published is a PUBLISH received by the fake broker
+type published struct {
+	topic   string
+	payload string
+	retain  bool
+}
+
+// fakeBroker is a minimal in-process MQTT 3.1.1 broker that records what
+// clients publish and keeps retained messages
+type fakeBroker struct {
+	t        *testing.T
+	listener net.Listener
+	refuse   byte // CONNACK return code; 0 accepts
+	noAck    bool // never acknowledge QoS 1 publishes
+
+	mu          sync.Mutex
+	clientIDs   []string
+	usernames   []string
+	will        string
+	messages    []published
+	retained    map[string]string
+	disconnects int
+}
+
+func newFakeBroker(t *testing.T) *fakeBroker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &fakeBroker{t: t, listener: listener, retained: make(map[string]string)}
+	go b.serve()
+	t.Cleanup(func() { listener.Close() })
+	return b
+}
+
+func (b *fakeBroker) url() string {
+	return "tcp://" + b.listener.Addr().String()
+}
+
+func (b *fakeBroker) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.handle(conn)
+	}
+}
+
+func (b *fakeBroker) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		packet, err := readPacket(r)
+		if err != nil {
+			return
+		}
+
+		switch packet.kind() {
+		case mqttConnect:
+			b.connect(packet.body)
+			reply, _ := encodePacket(mqttConnack, []byte{0, b.refuse})
+			conn.Write(reply)
+			if b.refuse != 0 {
+				return
+			}
+		case mqttPublish:
+			msg, id := parsePublish(packet)
+			b.mu.Lock()
+			b.messages = append(b.messages, msg)
+			if msg.retain {
+				b.retained[msg.topic] = msg.payload
+			}
+			b.mu.Unlock()
+			if packet.header&0x06 != 0 && !b.noAck {
+				reply, _ := encodePacket(mqttPuback, binary.BigEndian.AppendUint16(nil, id))
+				conn.Write(reply)
+			}
+		case mqttPingreq:
+			reply, _ := encodePacket(mqttPingresp, nil)
+			conn.Write(reply)
+		case mqttDisconnect:
+			b.mu.Lock()
+			b.disconnects++
+			b.mu.Unlock()
+			return
+		}
+	}
+}
+
+// connect records the client ID, user name and will of a CONNECT body
+func (b *fakeBroker) connect(body []byte) {
+	readString := func() string {
+		n := int(binary.BigEndian.Uint16(body))
+		s := string(body[2 : 2+n])
+		body = body[2+n:]
+		return s
+	}
+
+	if protocol := readString(); protocol != "MQTT" || body[0] != 4 {
+		b.t.Errorf("CONNECT protocol = %q level %d, want MQTT 4", protocol, body[0])
+	}
+	flags := body[1]
+	body = body[4:] // level, flags, keep alive
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clientIDs = append(b.clientIDs, readString())
+	if flags&0x04 != 0 {
+		b.will = readString() + "=" + readString()
+	}
+	if flags&0x80 != 0 {
+		b.usernames = append(b.usernames, readString())
+	}
+}
+
+func parsePublish(packet mqttPacket) (published, uint16) {
+	body := packet.body
+	n := int(binary.BigEndian.Uint16(body))
+	msg := published{topic: string(body[2 : 2+n]), retain: packet.header&0x01 != 0}
+	body = body[2+n:]
+
+	var id uint16
+	if packet.header&0x06 != 0 {
+		id = binary.BigEndian.Uint16(body)
+		body = body[2:]
+	}
+	msg.payload = string(body)
+	return msg, id
+}
+
+func (b *fakeBroker) topics() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var topics []string
+	for _, msg := range b.messages {
+		topics = append(topics, msg.topic)
+	}
+	return topics
+}
+
+func (b *fakeBroker) disconnectCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.disconnects
+}
+
+func (b *fakeBroker) lastPayload(topic string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := len(b.messages) - 1; i >= 0; i-- {
+		if b.messages[i].topic == topic {
+			return b.messages[i].payload
+		}
+	}
+	return ""
+}
+
+func testMQTTConfig(broker string) config.MQTT {
+	return config.MQTT{
+		Broker:      broker,
+		TopicPrefix: "office/desk1",
+		QoS:         1,
+		KeepAlive:   time.Minute,
+		Timeout:     time.Second,
+	}
+}
+
+func TestMQTTDispatcherPublishesEventsAndRetainedState(t *testing.T) {
+	broker := newFakeBroker(t)
+	cfg := testMQTTConfig(broker.url())
+	cfg.Username = "tomatick"
+	d := NewMQTTDispatcher(cfg, "abc123")
+
+	d.SetCycle(1)
+	d.Dispatch(SessionStart{WorkDurationSeconds: 1500})
+	d.Dispatch(WorkStart{Tasks: []string{"Secret roadmap"}, TasksCount: 1, PlannedDurationSeconds: 1500})
+	d.Dispatch(AISuggestions{SuggestionsCount: 2})
+	d.Dispatch(TimerPaused{Phase: PhaseWork, ElapsedSeconds: 60, RemainingSeconds: 1440})
+	d.Wait()
+
+	want := []string{
+		"office/desk1/status",
+		"office/desk1/events/session_start", "office/desk1/state", "office/desk1/phase",
+		"office/desk1/events/work_start", "office/desk1/state", "office/desk1/phase",
+		"office/desk1/events/ai_suggestions",
+		"office/desk1/events/timer_paused", "office/desk1/state", "office/desk1/phase",
+	}
+	if got := broker.topics(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("published topics:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	workStart := broker.lastPayload("office/desk1/events/work_start")
+	if strings.Contains(workStart, "Secret roadmap") {
+		t.Errorf("work_start payload leaks task names without MQTT_INCLUDE_SENSITIVE: %s", workStart)
+	}
+	var payload struct {
+		SessionID     string `json:"session_id"`
+		Cycle         int    `json:"cycle"`
+		CorrelationID string `json:"correlation_id"`
+	}
+	if err := json.Unmarshal([]byte(workStart), &payload); err != nil {
+		t.Fatalf("work_start payload is not JSON: %v", err)
+	}
+	if payload.SessionID != "abc123" || payload.Cycle != 1 || payload.CorrelationID != "abc123-1" {
+		t.Errorf("payload session = %q cycle %d correlation %q", payload.SessionID, payload.Cycle, payload.CorrelationID)
+	}
+
+	broker.mu.Lock()
+	phase := broker.retained["office/desk1/phase"]
+	stateJSON := broker.retained["office/desk1/state"]
+	clientIDs, usernames, will := broker.clientIDs, broker.usernames, broker.will
+	broker.mu.Unlock()
+
+	if phase != StatePaused {
+		t.Errorf("retained phase = %q, want %q", phase, StatePaused)
+	}
+	var state MQTTState
+	if err := json.Unmarshal([]byte(stateJSON), &state); err != nil {
+		t.Fatalf("retained state is not JSON: %v", err)
+	}
+	if state.Phase != PhaseWork || !state.Paused || state.EndsAt != nil || state.Cycle != 1 {
+		t.Errorf("retained state = %+v, want paused work in cycle 1 without an end time", state)
+	}
+	if len(clientIDs) != 1 || clientIDs[0] != "tomatick-abc123" {
+		t.Errorf("client IDs = %v, want one connection as tomatick-abc123", clientIDs)
+	}
+	if len(usernames) != 1 || usernames[0] != "tomatick" {
+		t.Errorf("user names = %v", usernames)
+	}
+	if will != "office/desk1/status=offline" {
+		t.Errorf("will = %q", will)
+	}
+
+	d.Dispatch(SessionEnd{CyclesCompleted: 1})
+	if undelivered := d.Shutdown(time.Second); len(undelivered) != 0 {
+		t.Errorf("undelivered = %+v", undelivered)
+	}
+
+	// The broker reads the DISCONNECT after the client has hung up
+	deadline := time.Now().Add(time.Second)
+	for broker.disconnectCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	if got := broker.retained["office/desk1/phase"]; got != StateEnded {
+		t.Errorf("retained phase after shutdown = %q, want %q", got, StateEnded)
+	}
+	if got := broker.retained["office/desk1/status"]; got != "offline" {
+		t.Errorf("retained status after shutdown = %q, want offline", got)
+	}
+	if broker.disconnects != 1 {
+		t.Errorf("clean disconnects = %d, want 1", broker.disconnects)
+	}
+}
+
+func TestMQTTDispatcherEventFilterKeepsStateCurrent(t *testing.T) {
+	broker := newFakeBroker(t)
+	cfg := testMQTTConfig(broker.url())
+	cfg.Events = []string{string(EventBreakStart)}
+	d := NewMQTTDispatcher(cfg, "abc123")
+
+	d.Dispatch(WorkStart{PlannedDurationSeconds: 1500})
+	d.Dispatch(BreakStart{BreakType: BreakLong, PlannedDurationSeconds: 900})
+	d.Wait()
+	d.Shutdown(time.Second)
+
+	for _, topic := range broker.topics() {
+		if topic == "office/desk1/events/work_start" {
+			t.Errorf("work_start published despite the event filter")
+		}
+	}
+	if broker.lastPayload("office/desk1/events/break_start") == "" {
+		t.Errorf("break_start not published")
+	}
+	if got := broker.lastPayload("office/desk1/phase"); got != PhaseLongBreak {
+		t.Errorf("phase = %q, want %q", got, PhaseLongBreak)
+	}
+}
+
+func TestMQTTDispatcherRefusedConnection(t *testing.T) {
+	broker := newFakeBroker(t)
+	broker.refuse = 5
+	d := NewMQTTDispatcher(testMQTTConfig(broker.url()), "abc123")
+
+	d.Dispatch(BreakEnd{BreakType: BreakShort})
+	undelivered := d.Shutdown(time.Second)
+
+	// The event and both state topics
+	if len(undelivered) != 3 {
+		t.Fatalf("undelivered = %d, want 3", len(undelivered))
+	}
+	letter := undelivered[0]
+	if letter.Event != EventBreakEnd || letter.Target != broker.url()+"/office/desk1/events/break_end" || letter.Attempts != 1 {
+		t.Errorf("dead letter = %+v", letter)
+	}
+	if !strings.Contains(letter.LastError, "not authorized") {
+		t.Errorf("last error = %q, want the CONNACK reason", letter.LastError)
+	}
+	if letter.Replayable() {
+		t.Errorf("MQTT dead letters must not be offered for webhook replay")
+	}
+}
+
+func TestMQTTDispatcherShutdownTimeout(t *testing.T) {
+	broker := newFakeBroker(t)
+	broker.noAck = true
+	cfg := testMQTTConfig(broker.url())
+	cfg.Timeout = 10 * time.Second
+	d := NewMQTTDispatcher(cfg, "abc123")
+
+	d.Dispatch(WorkStart{PlannedDurationSeconds: 1500})
+
+	start := time.Now()
+	undelivered := d.Shutdown(200 * time.Millisecond)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Shutdown took %v, want it bounded by its timeout", elapsed)
+	}
+	if len(undelivered) != 3 {
+		t.Fatalf("undelivered = %d, want 3", len(undelivered))
+	}
+	for _, letter := range undelivered {
+		if letter.LastError == "" {
+			t.Errorf("dead letter without an error: %+v", letter)
+		}
+	}
+}
+
+func TestEncodePacketRemainingLength(t *testing.T) {
+	tests := []struct {
+		size int
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7F}},
+		{128, []byte{0x80, 0x01}},
+		{16383, []byte{0xFF, 0x7F}},
+		{16384, []byte{0x80, 0x80, 0x01}},
+	}
+
+	for _, tt := range tests {
+		body := bytes.Repeat([]byte{'x'}, tt.size)
+		packet, err := encodePacket(mqttPublish, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := packet[1 : 1+len(tt.want)]; !bytes.Equal(got, tt.want) {
+			t.Errorf("remaining length of %d = % x, want % x", tt.size, got, tt.want)
+		}
+
+		decoded, err := readPacket(bufio.NewReader(bytes.NewReader(packet)))
+		if err != nil || decoded.header != mqttPublish || len(decoded.body) != tt.size {
+			t.Errorf("round trip of %d bytes = %d bytes, %v", tt.size, len(decoded.body), err)
+		}
+	}
+}
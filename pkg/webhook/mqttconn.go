@@ -0,0 +1,316 @@
+package webhook
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// MQTT 3.1.1 control packet types, shifted into the fixed header
+const (
+	mqttConnect    byte = 1 << 4
+	mqttConnack    byte = 2 << 4
+	mqttPublish    byte = 3 << 4
+	mqttPuback     byte = 4 << 4
+	mqttPingreq    byte = 12 << 4
+	mqttPingresp   byte = 13 << 4
+	mqttDisconnect byte = 14 << 4
+)
+
+// mqttMaxRemaining is the largest remaining length MQTT can encode
+const mqttMaxRemaining = 268435455
+
+var connackErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "client identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// mqttPacket is a decoded control packet
+type mqttPacket struct {
+	header byte // packet type and flags
+	body   []byte
+}
+
+func (p mqttPacket) kind() byte {
+	return p.header & 0xF0
+}
+
+// mqttWill is published by the broker when the connection drops uncleanly
+type mqttWill struct {
+	topic   string
+	payload []byte
+	qos     byte
+	retain  bool
+}
+
+// mqttConnectOptions are the fields of a CONNECT packet
+type mqttConnectOptions struct {
+	clientID  string
+	username  string
+	password  string
+	keepAlive time.Duration
+	will      *mqttWill
+}
+
+// mqttConn is a publish-only MQTT 3.1.1 client connection. It is not safe
+// for concurrent use; the MQTT dispatcher owns it from a single goroutine.
+type mqttConn struct {
+	conn      net.Conn
+	r         *bufio.Reader
+	timeout   time.Duration
+	nextID    uint16
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// dialMQTT connects to the broker and completes the CONNECT handshake
+func dialMQTT(ctx context.Context, broker string, timeout time.Duration, opts mqttConnectOptions) (*mqttConn, error) {
+	u, err := url.Parse(broker)
+	if err != nil {
+		return nil, err
+	}
+
+	secure := u.Scheme == "ssl" || u.Scheme == "tls" || u.Scheme == "mqtts"
+	host := u.Host
+	if u.Port() == "" {
+		port := "1883"
+		if secure {
+			port = "8883"
+		}
+		host = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var conn net.Conn
+	if secure {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: u.Hostname()}}
+		conn, err = dialer.DialContext(dialCtx, "tcp", host)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(dialCtx, "tcp", host)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &mqttConn{conn: conn, r: bufio.NewReader(conn), timeout: timeout, closed: make(chan struct{})}
+
+	// Cancelling ctx interrupts a blocked read or write
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Now())
+		case <-c.closed:
+		}
+	}()
+
+	if err := c.connect(opts); err != nil {
+		c.close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *mqttConn) connect(opts mqttConnectOptions) error {
+	var flags byte = 0x02 // clean session
+	payload := appendString(nil, opts.clientID)
+
+	if opts.will != nil {
+		flags |= 0x04 | opts.will.qos<<3
+		if opts.will.retain {
+			flags |= 0x20
+		}
+		payload = appendString(payload, opts.will.topic)
+		payload = appendBytes(payload, opts.will.payload)
+	}
+	if opts.username != "" {
+		flags |= 0x80
+		payload = appendString(payload, opts.username)
+	}
+	if opts.password != "" {
+		flags |= 0x40
+		payload = appendString(payload, opts.password)
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags) // protocol level 4 is MQTT 3.1.1
+	body = binary.BigEndian.AppendUint16(body, uint16(opts.keepAlive/time.Second))
+	body = append(body, payload...)
+
+	if err := c.write(mqttConnect, body); err != nil {
+		return err
+	}
+
+	packet, err := c.read()
+	if err != nil {
+		return err
+	}
+	if packet.kind() != mqttConnack || len(packet.body) != 2 {
+		return fmt.Errorf("mqtt: expected CONNACK, got packet type %d", packet.kind()>>4)
+	}
+	if code := packet.body[1]; code != 0 {
+		reason, ok := connackErrors[code]
+		if !ok {
+			reason = fmt.Sprintf("return code %d", code)
+		}
+		return fmt.Errorf("mqtt: connection refused: %s", reason)
+	}
+	return nil
+}
+
+// publish sends a message and, at QoS 1, waits for the broker's PUBACK
+func (c *mqttConn) publish(topic string, payload []byte, qos byte, retain bool) error {
+	header := mqttPublish | qos<<1
+	if retain {
+		header |= 0x01
+	}
+
+	body := appendString(nil, topic)
+	var id uint16
+	if qos > 0 {
+		c.nextID++
+		if c.nextID == 0 {
+			c.nextID = 1
+		}
+		id = c.nextID
+		body = binary.BigEndian.AppendUint16(body, id)
+	}
+	body = append(body, payload...)
+
+	if err := c.write(header, body); err != nil {
+		return err
+	}
+	if qos == 0 {
+		return nil
+	}
+
+	for {
+		packet, err := c.read()
+		if err != nil {
+			return err
+		}
+		// A late PINGRESP may arrive first
+		if packet.kind() == mqttPuback && len(packet.body) == 2 && binary.BigEndian.Uint16(packet.body) == id {
+			return nil
+		}
+	}
+}
+
+// ping keeps the connection alive between sparse events
+func (c *mqttConn) ping() error {
+	if err := c.write(mqttPingreq, nil); err != nil {
+		return err
+	}
+	for {
+		packet, err := c.read()
+		if err != nil {
+			return err
+		}
+		if packet.kind() == mqttPingresp {
+			return nil
+		}
+	}
+}
+
+// disconnect closes the connection cleanly, so the broker drops the will
+func (c *mqttConn) disconnect() {
+	c.write(mqttDisconnect, nil)
+	c.close()
+}
+
+func (c *mqttConn) close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.conn.Close()
+	})
+}
+
+func (c *mqttConn) write(header byte, body []byte) error {
+	packet, err := encodePacket(header, body)
+	if err != nil {
+		return err
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	_, err = c.conn.Write(packet)
+	return err
+}
+
+func (c *mqttConn) read() (mqttPacket, error) {
+	c.conn.SetReadDeadline(time.Now().Add(c.timeout))
+	return readPacket(c.r)
+}
+
+// encodePacket prefixes body with the fixed header and remaining length
+func encodePacket(header byte, body []byte) ([]byte, error) {
+	if len(body) > mqttMaxRemaining {
+		return nil, fmt.Errorf("mqtt: packet of %d bytes is too large", len(body))
+	}
+
+	packet := []byte{header}
+	remaining := len(body)
+	for {
+		digit := byte(remaining % 128)
+		remaining /= 128
+		if remaining > 0 {
+			digit |= 0x80
+		}
+		packet = append(packet, digit)
+		if remaining == 0 {
+			break
+		}
+	}
+	return append(packet, body...), nil
+}
+
+// readPacket reads one control packet
+func readPacket(r *bufio.Reader) (mqttPacket, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return mqttPacket{}, err
+	}
+
+	remaining, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return mqttPacket{}, errors.New("mqtt: malformed remaining length")
+		}
+		digit, err := r.ReadByte()
+		if err != nil {
+			return mqttPacket{}, err
+		}
+		remaining += int(digit&0x7F) * multiplier
+		multiplier *= 128
+		if digit&0x80 == 0 {
+			break
+		}
+	}
+
+	body := make([]byte, remaining)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return mqttPacket{}, err
+	}
+	return mqttPacket{header: header, body: body}, nil
+}
+
+// appendString appends an MQTT length-prefixed UTF-8 string
+func appendString(b []byte, s string) []byte {
+	return appendBytes(b, []byte(s))
+}
+
+func appendBytes(b []byte, data []byte) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(data)))
+	return append(b, data...)
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+// MultiDispatcher fans events out to several dispatchers of one session
+type MultiDispatcher struct {
+	dispatchers []Dispatcher
+}
+
+// NewMultiDispatcher combines dispatchers; the first one's session ID is reported
+func NewMultiDispatcher(dispatchers ...Dispatcher) *MultiDispatcher {
+	return &MultiDispatcher{dispatchers: dispatchers}
+}
+
+// NewSessionDispatcher creates the dispatchers configured in cfg: the HTTP
+// webhooks, plus the MQTT broker when one is set, sharing a session ID
+func NewSessionDispatcher(cfg *config.Config) Dispatcher {
+	http := NewHTTPDispatcher(cfg.Webhooks, filepath.Join(cfg.ContextDir, "logs"), cfg.WebhookLog)
+	if cfg.MQTT.Broker == "" {
+		return http
+	}
+	return NewMultiDispatcher(http, NewMQTTDispatcher(cfg.MQTT, http.SessionID()))
+}
+
+// SessionID returns the session ID shared by the dispatchers
+func (m *MultiDispatcher) SessionID() string {
+	if len(m.dispatchers) == 0 {
+		return ""
+	}
+	return m.dispatchers[0].SessionID()
+}
+
+// Dispatch sends the event to every dispatcher
+func (m *MultiDispatcher) Dispatch(event Event) {
+	for _, d := range m.dispatchers {
+		d.Dispatch(event)
+	}
+}
+
+// SetCycle sets the work cycle on every dispatcher
+func (m *MultiDispatcher) SetCycle(cycle int) {
+	for _, d := range m.dispatchers {
+		d.SetCycle(cycle)
+	}
+}
+
+// Wait blocks until every dispatcher is idle
+func (m *MultiDispatcher) Wait() {
+	for _, d := range m.dispatchers {
+		d.Wait()
+	}
+}
+
+// Shutdown drains all dispatchers in parallel, so the whole drain stays within timeout
+func (m *MultiDispatcher) Shutdown(timeout time.Duration) []DeadLetter {
+	var wg sync.WaitGroup
+	results := make([][]DeadLetter, len(m.dispatchers))
+	for i, d := range m.dispatchers {
+		wg.Add(1)
+		go func(i int, d Dispatcher) {
+			defer wg.Done()
+			results[i] = d.Shutdown(timeout)
+		}(i, d)
+	}
+	wg.Wait()
+
+	var undelivered []DeadLetter
+	for _, letters := range results {
+		undelivered = append(undelivered, letters...)
+	}
+	return undelivered
+}
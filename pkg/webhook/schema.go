@@ -0,0 +1,11 @@
+package webhook
+
+import _ "embed"
+
+//go:embed schema/payload.v2.json
+var payloadSchema []byte
+
+// Schema returns the JSON Schema of the standard payload for SchemaVersion
+func Schema() []byte {
+	return payloadSchema
+}
@@ -0,0 +1,280 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+// sampleEvents has one event of every known type with every field set
+func sampleEvents() []Event {
+	at := time.Date(2026, 3, 2, 9, 40, 0, 0, time.UTC)
+	return []Event{
+		WorkStart{Tasks: []string{"Draft the proposal"}, TasksCount: 1, PlannedDurationSeconds: 1500},
+		WorkComplete{Tasks: []string{"Draft the proposal"}, TasksCount: 1, PlannedDurationSeconds: 1500},
+		BreakStart{BreakType: BreakShort, PlannedDurationSeconds: 300},
+		BreakEnd{BreakType: BreakShort, PlannedDurationSeconds: 300},
+		ContextRefined{OriginalLength: 120, RefinedLength: 340, RefinedContext: "Ship the proposal by noon"},
+		AISuggestions{SuggestionsCount: 1, Suggestions: []string{"Outline the proposal"}},
+		AIAnalysis{Analysis: "Good focus", Tasks: []string{"Draft the proposal"}},
+		AIChatExchange{Context: ChatAnalysisDiscussion, UserInput: "Why?", AIResponse: "Because"},
+		SessionStart{WorkDurationSeconds: 1500, ShortBreakDurationSeconds: 300, LongBreakDurationSeconds: 900, CyclesBeforeLongBreak: 4},
+		SessionEnd{CyclesCompleted: 3, WorkSeconds: 4500, DurationSeconds: 5400},
+		SessionSummary{MemID: "mem_1", Content: "Drafted the proposal"},
+		ContextLoaded{Source: ContextFromFile, Structured: true, GoalsCount: 2, Tags: []string{"writing"}, HasSchedule: true, Project: "Proposal"},
+		TaskAdded{Source: TaskFromInput, Task: "Draft the proposal"},
+		TaskCompleted{Task: "Draft the proposal"},
+		TimerPaused{Phase: PhaseWork, ElapsedSeconds: 600, RemainingSeconds: 900},
+		TimerResumed{Phase: PhaseWork, ElapsedSeconds: 600, RemainingSeconds: 900, PausedSeconds: 60},
+		TimerAborted{Phase: PhaseShortBreak, ElapsedSeconds: 60, RemainingSeconds: 240},
+		LongBreakDue{CyclesSinceLongBreak: 4, CyclesBeforeLongBreak: 4},
+		BreakViolation{ViolationsCount: 2, FirstAt: at, LastAt: at.Add(time.Minute), Details: []string{"Typing in Code"}},
+	}
+}
+
+// sensitiveFields are the data fields Redacted must clear, by event type
+var sensitiveFields = map[EventType][]string{
+	EventWorkStart:      {"tasks"},
+	EventWorkComplete:   {"tasks"},
+	EventContextRefined: {"refined_context"},
+	EventAISuggestions:  {"suggestions"},
+	EventAIAnalysis:     {"analysis", "tasks"},
+	EventAIChatExchange: {"user_input", "ai_response"},
+	EventSessionSummary: {"content"},
+	EventContextLoaded:  {"project"},
+	EventTaskAdded:      {"task"},
+	EventTaskCompleted:  {"task"},
+	EventBreakViolation: {"details"},
+}
+
+func TestSampleEventsCoverKnownEvents(t *testing.T) {
+	var got []EventType
+	for _, event := range sampleEvents() {
+		got = append(got, event.EventType())
+	}
+	if !reflect.DeepEqual(got, knownEvents) {
+		t.Errorf("sample events = %v, want one of each in %v", got, knownEvents)
+	}
+}
+
+func TestPayloadsMatchSchema(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(Schema(), &schema); err != nil {
+		t.Fatalf("embedded schema: %v", err)
+	}
+
+	var mu sync.Mutex
+	received := make(map[string][][]byte) // path to bodies, in arrival order per event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	d := NewHTTPDispatcher([]config.Webhook{
+		{URL: server.URL + "/full", IncludeSensitive: true},
+		{URL: server.URL + "/redacted"},
+	}, t.TempDir(), config.LogRotation{})
+	defer d.Close()
+
+	for i, event := range sampleEvents() {
+		d.SetCycle(i / 4)
+		d.Dispatch(event)
+		d.Wait()
+	}
+
+	for _, path := range []string{"/full", "/redacted"} {
+		bodies := received[path]
+		if len(bodies) != len(knownEvents) {
+			t.Fatalf("%s received %d payloads, want %d", path, len(bodies), len(knownEvents))
+		}
+
+		for i, body := range bodies {
+			var payload map[string]interface{}
+			if err := json.Unmarshal(body, &payload); err != nil {
+				t.Fatalf("%s payload %d: %v", path, i, err)
+			}
+			eventType := EventType(fmt.Sprint(payload["type"]))
+			if err := validateSchema(schema, schema, payload, "payload"); err != nil {
+				t.Errorf("%s %s doesn't match the schema: %v\n%s", path, eventType, err, body)
+			}
+
+			// The session is stable for the run; the cycle follows SetCycle
+			wantCycle := i / 4
+			if payload["session_id"] != d.SessionID() || payload["cycle"] != float64(wantCycle) ||
+				payload["correlation_id"] != fmt.Sprintf("%s-%d", d.SessionID(), wantCycle) {
+				t.Errorf("%s %s: session %v, cycle %v, correlation %v; want %s, %d",
+					path, eventType, payload["session_id"], payload["cycle"], payload["correlation_id"], d.SessionID(), wantCycle)
+			}
+
+			data, _ := payload["data"].(map[string]interface{})
+			for _, field := range sensitiveFields[eventType] {
+				_, present := data[field]
+				if path == "/full" && !present {
+					t.Errorf("%s %s lacks %s", path, eventType, field)
+				}
+				if path == "/redacted" && present {
+					t.Errorf("%s %s sent sensitive %s", path, eventType, field)
+				}
+			}
+		}
+	}
+}
+
+func TestSchemaRejectsMalformedPayloads(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(Schema(), &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := `"schema_version": 2, "type": "work_start", "timestamp": "2026-03-02T09:00:00Z", "session_id": "s", "cycle": 1, "correlation_id": "s-1"`
+	for name, body := range map[string]string{
+		"wrong data type":       `{` + valid + `, "data": {"tasks_count": "two", "planned_duration_seconds": 1500}}`,
+		"missing data field":    `{` + valid + `, "data": {"tasks_count": 2}}`,
+		"unexpected data field": `{` + valid + `, "data": {"tasks_count": 2, "planned_duration_seconds": 1500, "mood": "great"}}`,
+		"negative count":        `{` + valid + `, "data": {"tasks_count": -1, "planned_duration_seconds": 1500}}`,
+		"unknown type":          `{"schema_version": 2, "type": "lunch", "timestamp": "2026-03-02T09:00:00Z", "session_id": "s", "cycle": 1, "correlation_id": "s-1", "data": {}}`,
+		"old schema version":    `{"schema_version": 1, "type": "break_end", "timestamp": "2026-03-02T09:00:00Z", "session_id": "s", "cycle": 1, "correlation_id": "s-1", "data": {"type": "short", "planned_duration_seconds": 300}}`,
+	} {
+		var payload map[string]interface{}
+		if err := json.Unmarshal([]byte(body), &payload); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if validateSchema(schema, schema, payload, "payload") == nil {
+			t.Errorf("%s: payload matched the schema", name)
+		}
+	}
+}
+
+func TestRedactedKeepsMetadata(t *testing.T) {
+	for _, event := range sampleEvents() {
+		redacted := event.Redacted()
+		if redacted.EventType() != event.EventType() {
+			t.Errorf("%T.Redacted changed the type to %s", event, redacted.EventType())
+		}
+
+		full, _ := eventFields(event)
+		stripped, _ := eventFields(redacted)
+		for _, field := range sensitiveFields[event.EventType()] {
+			delete(full, field)
+		}
+		if !reflect.DeepEqual(full, stripped) {
+			t.Errorf("%T.Redacted = %v, want %v", event, stripped, full)
+		}
+	}
+}
+
+// validateSchema checks value against the subset of JSON Schema the payload
+// schema uses. root resolves $ref pointers into $defs.
+func validateSchema(root, schema map[string]interface{}, value interface{}, at string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		defs, _ := root["$defs"].(map[string]interface{})
+		def, ok := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: unresolved $ref %s", at, ref)
+		}
+		if err := validateSchema(root, def, value, at); err != nil {
+			return err
+		}
+	}
+
+	if want, ok := schema["type"].(string); ok && !hasJSONType(value, want) {
+		return fmt.Errorf("%s: %v is not of type %s", at, value, want)
+	}
+	if want, ok := schema["const"]; ok && !reflect.DeepEqual(value, want) {
+		return fmt.Errorf("%s: %v, want %v", at, value, want)
+	}
+	if options, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, option := range options {
+			found = found || reflect.DeepEqual(value, option)
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", at, value, options)
+		}
+	}
+	if minimum, ok := schema["minimum"].(float64); ok {
+		if n, isNumber := value.(float64); isNumber && n < minimum {
+			return fmt.Errorf("%s: %v is below %v", at, n, minimum)
+		}
+	}
+	if schema["format"] == "date-time" {
+		if _, err := time.Parse(time.RFC3339, fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("%s: %v is not a date-time", at, value)
+		}
+	}
+
+	if object, ok := value.(map[string]interface{}); ok {
+		properties, _ := schema["properties"].(map[string]interface{})
+		required, _ := schema["required"].([]interface{})
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing required %s", at, name)
+			}
+		}
+		for name, field := range object {
+			property, ok := properties[name].(map[string]interface{})
+			if !ok {
+				if schema["additionalProperties"] == false {
+					return fmt.Errorf("%s: unexpected property %s", at, name)
+				}
+				continue
+			}
+			if err := validateSchema(root, property, field, at+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		array, _ := value.([]interface{})
+		for i, item := range array {
+			if err := validateSchema(root, items, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	all, _ := schema["allOf"].([]interface{})
+	for _, sub := range all {
+		sub, _ := sub.(map[string]interface{})
+		if condition, ok := sub["if"].(map[string]interface{}); ok {
+			if validateSchema(root, condition, value, at) != nil {
+				continue
+			}
+			sub, _ = sub["then"].(map[string]interface{})
+		}
+		if err := validateSchema(root, sub, value, at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasJSONType(value interface{}, want string) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return want == "object"
+	case []interface{}:
+		return want == "array"
+	case string:
+		return want == "string"
+	case bool:
+		return want == "boolean"
+	case float64:
+		return want == "number" || (want == "integer" && v == float64(int64(v)))
+	case nil:
+		return want == "null"
+	}
+	return false
+}
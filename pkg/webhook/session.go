@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// session stamps events with the run of tomatick and the work cycle they
+// belong to. Dispatchers of one run share the session ID.
+type session struct {
+	id      string
+	cycleMu sync.Mutex
+	cycle   int
+}
+
+// SessionID identifies this run of tomatick in every payload
+func (s *session) SessionID() string {
+	return s.id
+}
+
+// SetCycle sets the work cycle that following events belong to
+func (s *session) SetCycle(cycle int) {
+	s.cycleMu.Lock()
+	defer s.cycleMu.Unlock()
+	s.cycle = cycle
+}
+
+// newPayload wraps an event with the session and cycle it belongs to
+func (s *session) newPayload(event Event, timestamp time.Time) EventPayload {
+	s.cycleMu.Lock()
+	cycle := s.cycle
+	s.cycleMu.Unlock()
+
+	return EventPayload{
+		SchemaVersion: SchemaVersion,
+		Type:          event.EventType(),
+		Timestamp:     timestamp,
+		SessionID:     s.id,
+		Cycle:         cycle,
+		CorrelationID: fmt.Sprintf("%s-%d", s.id, cycle),
+		Data:          event,
+	}
+}
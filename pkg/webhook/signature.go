@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the HMAC-SHA256 signature of a webhook request.
+// Its value has the form "t=<unix seconds>,v1=<hex digest>", where the digest
+// is computed over "<unix seconds>.<raw body>" with the endpoint's secret.
+const SignatureHeader = "X-Tomatick-Signature"
+
+// DefaultSignatureTolerance is how far a signature timestamp may drift from
+// the receiver's clock before the request is rejected as a replay
+const DefaultSignatureTolerance = 5 * time.Minute
+
+var (
+	ErrSignatureMissing   = errors.New("webhook signature missing")
+	ErrSignatureMalformed = errors.New("webhook signature malformed")
+	ErrSignatureExpired   = errors.New("webhook signature timestamp outside tolerance")
+	ErrSignatureMismatch  = errors.New("webhook signature mismatch")
+)
+
+// Sign returns the signature header value for body, signed at timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", ts, computeSignature(secret, ts, body))
+}
+
+func computeSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks a signature header value against body. Signatures
+// older or newer than tolerance are rejected to prevent replays; a tolerance
+// of zero or less means DefaultSignatureTolerance, so the check can't be
+// switched off by accident.
+func VerifySignature(secret, header string, body []byte, tolerance time.Duration) error {
+	if header == "" {
+		return ErrSignatureMissing
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultSignatureTolerance
+	}
+
+	var timestamp, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			return ErrSignatureMalformed
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature = value
+		}
+	}
+	if timestamp == "" || signature == "" {
+		return ErrSignatureMalformed
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrSignatureMalformed
+	}
+	drift := time.Since(time.Unix(unix, 0))
+	if drift < 0 {
+		drift = -drift
+	}
+	if drift > tolerance {
+		return ErrSignatureExpired
+	}
+
+	expected := computeSignature(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
+
+// VerifyRequest reads and verifies the body of an incoming webhook request,
+// returning the body when the signature is valid. tolerance is as for
+// VerifySignature.
+func VerifyRequest(r *http.Request, secret string, tolerance time.Duration) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook body: %w", err)
+	}
+	if err := VerifySignature(secret, r.Header.Get(SignatureHeader), body, tolerance); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	const secret = "whsec_test"
+	body := []byte(`{"type":"work_start","cycle":1}`)
+	now := time.Now()
+	valid := Sign(secret, now, body)
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		want   error
+	}{
+		{name: "round trip", secret: secret, header: valid, body: body},
+		{name: "spaces after comma", secret: secret, header: strings.Replace(valid, ",", ", ", 1), body: body},
+		{name: "tampered body", secret: secret, header: valid, body: []byte(`{"type":"work_start","cycle":2}`), want: ErrSignatureMismatch},
+		{name: "wrong secret", secret: "whsec_other", header: valid, body: body, want: ErrSignatureMismatch},
+		{name: "tampered timestamp", secret: secret, header: strings.Replace(valid, "t="+ts, "t="+strconv.FormatInt(now.Unix()+1, 10), 1), body: body, want: ErrSignatureMismatch},
+		{name: "missing header", secret: secret, header: "", body: body, want: ErrSignatureMissing},
+		{name: "no key-value pairs", secret: secret, header: "garbage", body: body, want: ErrSignatureMalformed},
+		{name: "missing timestamp", secret: secret, header: "v1=" + strings.SplitN(valid, "v1=", 2)[1], body: body, want: ErrSignatureMalformed},
+		{name: "missing digest", secret: secret, header: "t=" + ts, body: body, want: ErrSignatureMalformed},
+		{name: "non-numeric timestamp", secret: secret, header: "t=yesterday,v1=abc", body: body, want: ErrSignatureMalformed},
+		{name: "too old", secret: secret, header: Sign(secret, now.Add(-6*time.Minute), body), body: body, want: ErrSignatureExpired},
+		{name: "too far ahead", secret: secret, header: Sign(secret, now.Add(6*time.Minute), body), body: body, want: ErrSignatureExpired},
+		{name: "within tolerance", secret: secret, header: Sign(secret, now.Add(-4*time.Minute), body), body: body},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(tt.secret, tt.header, tt.body, DefaultSignatureTolerance)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("VerifySignature = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifySignatureTolerance(t *testing.T) {
+	const secret = "whsec_test"
+	body := []byte(`{"type":"break_end"}`)
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		signedAt  time.Time
+		tolerance time.Duration
+		want      error
+	}{
+		{name: "custom tolerance", signedAt: now.Add(-90 * time.Second), tolerance: time.Minute, want: ErrSignatureExpired},
+		{name: "within custom tolerance", signedAt: now.Add(-30 * time.Second), tolerance: time.Minute},
+		{name: "zero means the default", signedAt: now.Add(-4 * time.Minute), tolerance: 0},
+		{name: "zero still rejects replays", signedAt: now.Add(-6 * time.Minute), tolerance: 0, want: ErrSignatureExpired},
+		{name: "negative means the default", signedAt: now.Add(-4 * time.Minute), tolerance: -time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(secret, Sign(secret, tt.signedAt, body), body, tt.tolerance)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("VerifySignature = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignIsStable(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	got := Sign("secret", at, []byte("body"))
+	if !strings.HasPrefix(got, "t=1700000000,v1=") || len(got) != len("t=1700000000,v1=")+64 {
+		t.Errorf("Sign = %q, want a hex SHA-256 digest", got)
+	}
+	if got != Sign("secret", at, []byte("body")) {
+		t.Error("Sign is not deterministic")
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	body := `{"type":"break_start"}`
+	req := httptest.NewRequest("POST", "/hook", strings.NewReader(body))
+	req.Header.Set(SignatureHeader, Sign("secret", time.Now(), []byte(body)))
+
+	got, err := VerifyRequest(req, "secret", DefaultSignatureTolerance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != body {
+		t.Errorf("body = %q, want %q", got, body)
+	}
+}
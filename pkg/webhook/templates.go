@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// preset is a built-in payload format for a popular webhook target
+type preset struct {
+	template    string
+	contentType string
+	headers     map[string]string
+}
+
+var presets = map[string]preset{
+	"json": {
+		template:    "{{json .Payload}}",
+		contentType: "application/json",
+	},
+	"slack": {
+		template:    `{"text": {{json .Summary}}}`,
+		contentType: "application/json",
+	},
+	"discord": {
+		template:    `{"content": {{json .Summary}}, "username": "Tomatick"}`,
+		contentType: "application/json",
+	},
+	"ntfy": {
+		template:    "{{.Summary}}",
+		contentType: "text/plain; charset=utf-8",
+		headers: map[string]string{
+			"Title": "Tomatick",
+			"Tags":  "tomato",
+		},
+	},
+}
+
+// TemplateData is what payload templates render. Data holds the event fields
+// keyed by their JSON names, already stripped of sensitive fields for
+// endpoints that don't include them.
+type TemplateData struct {
+	Type          EventType
+	Timestamp     time.Time
+	SessionID     string
+	Cycle         int
+	CorrelationID string
+	Data          map[string]interface{}
+	Summary       string       // one-line, human readable description of the event
+	Payload       EventPayload // the standard JSON payload
+}
+
+var templateFuncs = template.FuncMap{
+	// json renders a value as JSON, e.g. a safely quoted string inside a JSON template
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// IsPreset reports whether name is a built-in payload format
+func IsPreset(name string) bool {
+	_, ok := presets[strings.ToLower(name)]
+	return ok
+}
+
+// compileTemplate resolves a preset name or inline Go template. It returns a
+// nil template when the endpoint uses the standard JSON payload.
+func compileTemplate(source, contentType string) (*template.Template, string, map[string]string, error) {
+	if source == "" {
+		return nil, "application/json", nil, nil
+	}
+
+	var headers map[string]string
+	if p, ok := presets[strings.ToLower(source)]; ok {
+		source = p.template
+		headers = p.headers
+		if contentType == "" {
+			contentType = p.contentType
+		}
+	}
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	tmpl, err := template.New("payload").Funcs(templateFuncs).Option("missingkey=zero").Parse(source)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("invalid payload template: %w", err)
+	}
+
+	return tmpl, contentType, headers, nil
+}
+
+func renderTemplate(tmpl *template.Template, payload EventPayload) ([]byte, error) {
+	data, err := eventFields(payload.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, TemplateData{
+		Type:          payload.Type,
+		Timestamp:     payload.Timestamp,
+		SessionID:     payload.SessionID,
+		Cycle:         payload.Cycle,
+		CorrelationID: payload.CorrelationID,
+		Data:          data,
+		Summary:       Summarize(payload.Data),
+		Payload:       payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render payload template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// eventFields flattens an event into its JSON fields for templates
+func eventFields(event Event) (map[string]interface{}, error) {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// Summarize describes an event in one human readable line
+func Summarize(event Event) string {
+	switch e := event.(type) {
+	case WorkStart:
+		return fmt.Sprintf("🍅 Focus session started (%d tasks, %s)", e.TasksCount, minutes(e.PlannedDurationSeconds))
+	case WorkComplete:
+		return "✅ Focus session complete"
+	case BreakStart:
+		return fmt.Sprintf("☕ %s break started (%s)", titleCase(valueOr(e.BreakType, BreakShort)), minutes(e.PlannedDurationSeconds))
+	case BreakEnd:
+		return fmt.Sprintf("🔔 %s break is over", titleCase(valueOr(e.BreakType, BreakShort)))
+	case ContextRefined:
+		return "🧠 Session context refined"
+	case AISuggestions:
+		return fmt.Sprintf("💡 %d task suggestions ready", e.SuggestionsCount)
+	case AIAnalysis:
+		return "📊 Cycle analysis ready"
+	case AIChatExchange:
+		return fmt.Sprintf("💬 Copilot chat (%s)", strings.ReplaceAll(valueOr(e.Context, "discussion"), "_", " "))
+	case SessionSummary:
+		return "📝 Session summary saved"
+	case SessionStart:
+		return fmt.Sprintf("🌅 Workday started (%s focus / %s break)", minutes(e.WorkDurationSeconds), minutes(e.ShortBreakDurationSeconds))
+	case SessionEnd:
+		return fmt.Sprintf("🏁 Workday ended after %d cycles", e.CyclesCompleted)
+	case ContextLoaded:
+		return fmt.Sprintf("📂 Session context loaded (%s)", strings.ReplaceAll(e.Source, "_", " "))
+	case TaskAdded:
+		return "➕ Task added"
+	case TaskCompleted:
+		return "☑️ Task completed"
+	case TimerPaused:
+		return fmt.Sprintf("⏸️ %s timer paused (%s left)", titleCase(strings.ReplaceAll(e.Phase, "_", " ")), minutes(e.RemainingSeconds))
+	case TimerResumed:
+		return fmt.Sprintf("▶️ %s timer resumed", titleCase(strings.ReplaceAll(e.Phase, "_", " ")))
+	case TimerAborted:
+		return fmt.Sprintf("⏹️ %s timer stopped early (%s left)", titleCase(strings.ReplaceAll(e.Phase, "_", " ")), minutes(e.RemainingSeconds))
+	case LongBreakDue:
+		return fmt.Sprintf("🌴 Long break due after %d cycles", e.CyclesSinceLongBreak)
+	case BreakViolation:
+		return fmt.Sprintf("🚨 Working during a break (%d detections)", e.ViolationsCount)
+	default:
+		return fmt.Sprintf("Tomatick event: %s", event.EventType())
+	}
+}
+
+func valueOr(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
+
+func minutes(seconds int) string {
+	return fmt.Sprintf("%d min", (seconds+30)/60)
+}
+
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
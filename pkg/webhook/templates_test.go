@@ -0,0 +1,154 @@
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1x-eng/tomatick/config"
+)
+
+// samplePayload is a work start as a session would send it
+func samplePayload() EventPayload {
+	s := &session{id: "sess-1"}
+	s.SetCycle(2)
+	return s.newPayload(WorkStart{
+		Tasks:                  []string{`Fix the "flaky" test`, "Review PR #42"},
+		TasksCount:             2,
+		PlannedDurationSeconds: 25 * 60,
+	}, time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+}
+
+func TestRenderTemplates(t *testing.T) {
+	const summary = "🍅 Focus session started (2 tasks, 25 min)"
+
+	tests := []struct {
+		name        string
+		template    string
+		contentType string
+		headers     map[string]string
+		json        bool
+		want        func(t *testing.T, body []byte)
+	}{
+		{name: "standard payload", template: "", contentType: "application/json", json: true,
+			want: func(t *testing.T, body []byte) {
+				var payload struct {
+					Type string `json:"type"`
+					Data struct {
+						Tasks []string `json:"tasks"`
+					} `json:"data"`
+				}
+				json.Unmarshal(body, &payload)
+				if payload.Type != "work_start" || len(payload.Data.Tasks) != 2 {
+					t.Errorf("payload = %+v", payload)
+				}
+			}},
+		{name: "json", template: "json", contentType: "application/json", json: true,
+			want: func(t *testing.T, body []byte) {
+				standard, _ := json.Marshal(samplePayload())
+				if string(body) != string(standard) {
+					t.Errorf("body = %s, want the standard payload %s", body, standard)
+				}
+			}},
+		{name: "slack", template: "slack", contentType: "application/json", json: true,
+			want: func(t *testing.T, body []byte) {
+				var message struct{ Text string }
+				json.Unmarshal(body, &message)
+				if message.Text != summary {
+					t.Errorf("text = %q, want %q", message.Text, summary)
+				}
+			}},
+		{name: "discord, any case", template: "Discord", contentType: "application/json", json: true,
+			want: func(t *testing.T, body []byte) {
+				var message struct{ Content, Username string }
+				json.Unmarshal(body, &message)
+				if message.Content != summary || message.Username != "Tomatick" {
+					t.Errorf("message = %+v", message)
+				}
+			}},
+		{name: "ntfy", template: "ntfy", contentType: "text/plain; charset=utf-8", headers: map[string]string{"Title": "Tomatick", "Tags": "tomato"},
+			want: func(t *testing.T, body []byte) {
+				if string(body) != summary {
+					t.Errorf("body = %q, want %q", body, summary)
+				}
+			}},
+		{name: "custom text", template: `{{.Type}} #{{.Cycle}} in {{.SessionID}}: {{.Data.tasks_count}} tasks, first {{index .Data.tasks 0}}`,
+			contentType: "application/json",
+			want: func(t *testing.T, body []byte) {
+				if want := `work_start #2 in sess-1: 2 tasks, first Fix the "flaky" test`; string(body) != want {
+					t.Errorf("body = %q, want %q", body, want)
+				}
+			}},
+		{name: "custom JSON", template: `{"task": {{json (index .Data.tasks 0)}}}`, contentType: "application/json", json: true,
+			want: func(t *testing.T, body []byte) {
+				var message struct{ Task string }
+				json.Unmarshal(body, &message)
+				if message.Task != `Fix the "flaky" test` {
+					t.Errorf("task = %q", message.Task)
+				}
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ep, err := newEndpoint(config.Webhook{URL: "https://example.com/hook", Template: tt.template, IncludeSensitive: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ep.contentType != tt.contentType {
+				t.Errorf("content type = %q, want %q", ep.contentType, tt.contentType)
+			}
+			for key, value := range tt.headers {
+				if ep.headers[key] != value {
+					t.Errorf("header %s = %q, want %q", key, ep.headers[key], value)
+				}
+			}
+
+			body, err := ep.render(ep.prepare(samplePayload()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.json && !json.Valid(body) {
+				t.Errorf("body is not valid JSON: %s", body)
+			}
+			tt.want(t, body)
+		})
+	}
+}
+
+func TestTemplateRedactsTasks(t *testing.T) {
+	ep, err := newEndpoint(config.Webhook{URL: "https://example.com/hook", Template: "{{.Data.tasks}}|{{.Payload.Data}}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ep.render(ep.prepare(samplePayload()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(body), "flaky") {
+		t.Errorf("body = %q, want no task text without include_sensitive", body)
+	}
+}
+
+func TestCompileTemplateErrors(t *testing.T) {
+	if _, err := newEndpoint(config.Webhook{URL: "https://example.com/hook", Template: "{{.Type"}); err == nil {
+		t.Error("unterminated action compiled")
+	}
+
+	ep, err := newEndpoint(config.Webhook{URL: "https://example.com/hook", Template: "{{.Nope}}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ep.render(samplePayload()); err == nil {
+		t.Error("rendering an unknown field succeeded")
+	}
+
+	ep, err = newEndpoint(config.Webhook{URL: "https://example.com/hook", Template: "{{.Summary}}", ContentType: "text/markdown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ep.contentType != "text/markdown" {
+		t.Errorf("content type = %q, want the configured one", ep.contentType)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"time"
 )
 
+// SchemaVersion is the version of the payload schema, bumped on incompatible changes
+const SchemaVersion = 2
+
 // EventType represents the type of event occurring in the system
 type EventType string
 
@@ -20,18 +23,74 @@ const (
 	EventAIChatExchange EventType = "ai_chat_exchange"
 
 	// Lifecycle Events
+	EventSessionStart   EventType = "session_start"
+	EventSessionEnd     EventType = "session_end"
 	EventSessionSummary EventType = "session_summary"
+	EventContextLoaded  EventType = "context_loaded"
+	EventTaskAdded      EventType = "task_added"
+	EventTaskCompleted  EventType = "task_completed"
+	EventTimerPaused    EventType = "timer_paused"
+	EventTimerResumed   EventType = "timer_resumed"
+	EventTimerAborted   EventType = "timer_aborted"
+	EventLongBreakDue   EventType = "long_break_due"
+
+	// Break Monitoring Events
+	EventBreakViolation EventType = "break_violation"
 )
 
-// EventPayload represents the standard structure sent to webhooks
+// knownEvents lists every event type tomatick dispatches
+var knownEvents = []EventType{
+	EventWorkStart,
+	EventWorkComplete,
+	EventBreakStart,
+	EventBreakEnd,
+	EventContextRefined,
+	EventAISuggestions,
+	EventAIAnalysis,
+	EventAIChatExchange,
+	EventSessionStart,
+	EventSessionEnd,
+	EventSessionSummary,
+	EventContextLoaded,
+	EventTaskAdded,
+	EventTaskCompleted,
+	EventTimerPaused,
+	EventTimerResumed,
+	EventTimerAborted,
+	EventLongBreakDue,
+	EventBreakViolation,
+}
+
+// IsKnownEvent reports whether eventType is one tomatick dispatches
+func IsKnownEvent(eventType EventType) bool {
+	for _, known := range knownEvents {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// EventPayload represents the standard structure sent to webhooks. Every
+// event of one work cycle and its break shares a correlation ID.
 type EventPayload struct {
-	Type      EventType         `json:"type"`
-	Timestamp time.Time         `json:"timestamp"`
-	Data      map[string]string `json:"data,omitempty"`
+	SchemaVersion int       `json:"schema_version"`
+	Type          EventType `json:"type"`
+	Timestamp     time.Time `json:"timestamp"`
+	SessionID     string    `json:"session_id"`
+	Cycle         int       `json:"cycle"`
+	CorrelationID string    `json:"correlation_id"`
+	Data          Event     `json:"data"`
 }
 
 // Dispatcher defines the interface for sending events
 type Dispatcher interface {
-	Dispatch(eventType EventType, data map[string]string)
+	Dispatch(event Event)
+	// SessionID identifies this run of tomatick in every payload
+	SessionID() string
+	// SetCycle sets the work cycle that following events belong to
+	SetCycle(cycle int)
 	Wait()
+	// Shutdown drains deliveries for at most timeout and returns the undelivered ones
+	Shutdown(timeout time.Duration) []DeadLetter
 }